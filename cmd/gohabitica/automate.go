@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/automate"
+	"github.com/danielrichardt/gohabitica/habitica/webhook"
+)
+
+// runAutomate runs a local webhook receiver that evaluates automation rules,
+// polls the account instead with -poll, or replays recorded webhook payloads
+// through the rules with -replay.
+//
+// Example usage:
+//   gohabitica automate -rules rules.yaml -addr :8080 -token s3cret
+//   gohabitica automate -rules rules.yaml -poll 2m
+//   gohabitica automate -rules rules.yaml -replay events.jsonl -dry-run
+func runAutomate(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("automate", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		rulesPath string
+		addr      string
+		path      string
+		token     string
+		replay    string
+		dryRun    bool
+		failReqs  bool
+		poll      time.Duration
+	)
+
+	fs.StringVar(&rulesPath, "rules", "", "Path to the YAML rules file (required)")
+	fs.StringVar(&addr, "addr", ":8080", "Address to listen on")
+	fs.StringVar(&path, "path", "/habitica", "URL path of the webhook endpoint")
+	fs.StringVar(&token, "token", "", "Shared secret expected in the token query parameter")
+	fs.StringVar(&replay, "replay", "", "Feed recorded webhook payloads from this file (- for stdin) instead of listening")
+	fs.BoolVar(&dryRun, "dry-run", false, "Only log the actions")
+	fs.BoolVar(&failReqs, "fail-requests", false, "Answer webhook requests with 500 when an action fails")
+	fs.DurationVar(&poll, "poll", 0, "Poll the account at this interval instead of receiving webhooks, e.g. 2m")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(rulesPath) == "" {
+		return fmt.Errorf("flag -rules is required")
+	}
+	if poll < 0 {
+		return fmt.Errorf("flag -poll must not be negative")
+	}
+	if poll > 0 && replay != "" {
+		return fmt.Errorf("flags -poll and -replay cannot be combined")
+	}
+	rules, err := automate.Load(rulesPath)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	// Changes caused by the rules show up at the next poll.
+	engine := automate.New(client, rules, automate.Options{DryRun: dryRun, Log: logger, FailRequests: failReqs, EchoTTL: 2 * poll})
+
+	if replay != "" {
+		in := os.Stdin
+		if replay != "-" {
+			f, err := os.Open(replay)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		results, err := automate.Replay(ctx, engine, in)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Replayed events; %d action(s) matched.\n", len(results))
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	mode := ""
+	if dryRun {
+		mode = " (dry run)"
+	}
+
+	if poll > 0 {
+		logger.Printf("polling every %s with %d rule(s)%s", poll, len(rules.Rules), mode)
+		return engine.Watch(ctx, habitica.NewWatcher(client, habitica.WatchOptions{Interval: poll}))
+	}
+
+	h := webhook.NewHandler(webhook.WithToken(token), webhook.WithErrorLog(logger))
+	engine.Register(h)
+
+	mux := http.NewServeMux()
+	mux.Handle(path, h)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Printf("listening on %s%s with %d rule(s)%s", addr, path, len(rules.Rules), mode)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica/backup"
+)
+
+// runExport writes all tasks (including completed todos) and tags of the account to a file or stdout.
+//
+// Example usage:
+//   gohabitica export -o tasks.json
+//   gohabitica export -format csv > tasks.csv
+func runExport(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		out    string
+		format string
+	)
+
+	fs.StringVar(&out, "o", "", "Output file (default: stdout)")
+	fs.StringVar(&format, "format", "", "Output format: json, yaml or csv (default: from the file extension, else json)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	f, err := backupFormat(format, out)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	b, err := backup.Export(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		return backup.Encode(os.Stdout, b, f)
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := backup.Encode(file, b, f); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Exported %d task(s) and %d tag(s) to %s.\n", len(b.Tasks), len(b.Tags), out)
+	return nil
+}
+
+// runImport recreates the tasks and tags of an export on the configured account.
+// Tags are matched by name; tasks that already exist (same alias, or same type and text) are skipped.
+//
+// Example usage:
+//   gohabitica import -f tasks.json -dry-run
+//   gohabitica import -f tasks.csv -completed
+func runImport(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		in        string
+		format    string
+		completed bool
+		dryRun    bool
+	)
+
+	fs.StringVar(&in, "f", "", "Export file to import, or - for stdin (required)")
+	fs.StringVar(&format, "format", "", "Input format: json, yaml or csv (default: from the file extension, else json)")
+	fs.BoolVar(&completed, "completed", false, "Also import completed todos (scoring them grants XP and gold)")
+	fs.BoolVar(&dryRun, "dry-run", false, "Only show what would be imported")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(in) == "" {
+		return fmt.Errorf("flag -f is required")
+	}
+
+	f, err := backupFormat(format, in)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if in != "-" {
+		file, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	b, err := backup.Decode(r, f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	report, err := backup.Import(ctx, client, b, backup.ImportOptions{Completed: completed, DryRun: dryRun})
+	if report != nil {
+		verb := "Imported"
+		if dryRun {
+			verb = "Would import"
+		}
+		for _, tag := range report.TagsCreated {
+			fmt.Fprintf(os.Stdout, "%s tag %q\n", verb, tag.Name)
+		}
+		for _, t := range report.Created {
+			fmt.Fprintf(os.Stdout, "%s %s %q\n", verb, t.Type, t.Text)
+		}
+		for _, s := range report.Skipped {
+			fmt.Fprintf(os.Stdout, "Skipped %s %q: %s\n", s.Task.Type, s.Task.Text, s.Reason)
+		}
+		fmt.Fprintf(os.Stdout, "%s %d task(s) and %d tag(s), skipped %d task(s).\n", verb, len(report.Created), len(report.TagsCreated), len(report.Skipped))
+	}
+	return err
+}
+
+// backupFormat returns the explicit format, or the one derived from path.
+func backupFormat(format, path string) (backup.Format, error) {
+	if format != "" {
+		return backup.ParseFormat(format)
+	}
+	return backup.FormatFromPath(path), nil
+}
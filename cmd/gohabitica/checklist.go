@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// loadTodoChecklistItem fetches a todo and returns the checklist item at the 1-based index.
+func loadTodoChecklistItem(ctx context.Context, client *habitica.Client, id string, index int) (*habitica.Task, habitica.ChecklistItem, error) {
+	task, err := client.Tasks.GetTask(ctx, habitica.UUID(id))
+	if err != nil {
+		return nil, habitica.ChecklistItem{}, err
+	}
+	if task.Type != habitica.TaskTypeTodo {
+		return nil, habitica.ChecklistItem{}, fmt.Errorf("task %s is not a todo", id)
+	}
+	if len(task.Checklist) == 0 {
+		return nil, habitica.ChecklistItem{}, fmt.Errorf("todo %s has no checklist items", id)
+	}
+	if index > len(task.Checklist) {
+		return nil, habitica.ChecklistItem{}, fmt.Errorf("flag -index is out of range: todo %s only has %d checklist items", id, len(task.Checklist))
+	}
+	return task, task.Checklist[index-1], nil
+}
+
+// printChecklist prints the checklist of a task with 1-based indices.
+func printChecklist(task *habitica.Task) {
+	for i, item := range task.Checklist {
+		status := " "
+		if item.Completed {
+			status = "x"
+		}
+		fmt.Fprintf(os.Stdout, "  %d. [%s] %s\n", i+1, status, item.Text)
+	}
+}
+
+// runTodoCheckAdd appends checklist items to a todo.
+//
+// Example usage:
+//   gohabitica todo-check-add -id "37ceed6f-0772-43bb-a177-39d3074f75b7" -text "Eggs"
+//   gohabitica todo-check-add -id "..." -text "Eggs" -text "Butter"
+func runTodoCheckAdd(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("todo-check-add", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		id    string
+		texts stringSliceFlag
+	)
+
+	fs.StringVar(&id, "id", "", "ID of the todo (required)")
+	fs.Var(&texts, "text", "Text of the new checklist item (required, can be specified multiple times)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	taskID, err := requireID(id)
+	if err != nil {
+		return err
+	}
+	if len(texts) == 0 {
+		return fmt.Errorf("flag -text is required")
+	}
+	for _, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("flag -text must not be empty")
+		}
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		task   *habitica.Task
+		queued []string
+	)
+	for _, text := range texts {
+		added, err := client.Tasks.AddChecklistItem(ctx, taskID, text)
+		if errors.Is(err, habitica.ErrQueuedOffline) {
+			queued = append(queued, text)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		task = added
+	}
+
+	if len(queued) > 0 {
+		fmt.Fprintf(os.Stdout, "Habitica is unreachable; %d checklist item(s) for todo %s have been queued:\n", len(queued), taskID)
+		for _, text := range queued {
+			fmt.Fprintf(os.Stdout, "  - %s\n", text)
+		}
+		fmt.Fprintln(os.Stdout, "Run \"gohabitica sync\" once you are online.")
+	}
+	if task == nil {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stdout, "Checklist of todo %s:\n", taskID)
+	printChecklist(task)
+	return nil
+}
+
+// runTodoCheckRemove deletes a checklist item of a todo by its 1-based index.
+//
+// Example usage:
+//   gohabitica todo-check-remove -id "37ceed6f-0772-43bb-a177-39d3074f75b7" -index 2
+func runTodoCheckRemove(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("todo-check-remove", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		id    string
+		index int
+	)
+
+	fs.StringVar(&id, "id", "", "ID of the todo that owns the checklist item (required)")
+	fs.IntVar(&index, "index", 0, "1-based index of the checklist item to remove (required)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := requireID(id); err != nil {
+		return err
+	}
+	if index <= 0 {
+		return fmt.Errorf("flag -index must be greater than zero")
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, item, err := loadTodoChecklistItem(ctx, client, id, index)
+	if err != nil {
+		return err
+	}
+	if _, err := client.Tasks.DeleteChecklistItem(ctx, task.ID, item.ID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Checklist item #%d (%q) has been removed from todo %s.\n", index, item.Text, task.ID)
+	return nil
+}
+
+// runTodoCheckEdit renames and/or moves a checklist item of a todo.
+//
+// Example usage:
+//   gohabitica todo-check-edit -id "37ceed6f-0772-43bb-a177-39d3074f75b7" -index 1 -text "Oat milk"
+//   gohabitica todo-check-edit -id "..." -index 3 -move-to 1
+func runTodoCheckEdit(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("todo-check-edit", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		id     string
+		index  int
+		text   string
+		moveTo int
+	)
+
+	fs.StringVar(&id, "id", "", "ID of the todo that owns the checklist item (required)")
+	fs.IntVar(&index, "index", 0, "1-based index of the checklist item to edit (required)")
+	fs.StringVar(&text, "text", "", "New text of the checklist item")
+	fs.IntVar(&moveTo, "move-to", 0, "New 1-based position of the checklist item")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := requireID(id); err != nil {
+		return err
+	}
+	if index <= 0 {
+		return fmt.Errorf("flag -index must be greater than zero")
+	}
+	set := setFlags(fs)
+	if !set["text"] && !set["move-to"] {
+		return fmt.Errorf("at least one of -text or -move-to is required")
+	}
+	if set["text"] && strings.TrimSpace(text) == "" {
+		return fmt.Errorf("flag -text must not be empty")
+	}
+	if set["move-to"] && moveTo <= 0 {
+		return fmt.Errorf("flag -move-to must be greater than zero")
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, item, err := loadTodoChecklistItem(ctx, client, id, index)
+	if err != nil {
+		return err
+	}
+	if set["move-to"] && moveTo > len(task.Checklist) {
+		return fmt.Errorf("flag -move-to is out of range: todo %s only has %d checklist items", id, len(task.Checklist))
+	}
+
+	if set["text"] {
+		if task, err = client.Tasks.RenameChecklistItem(ctx, task.ID, item.ID, text); err != nil {
+			return err
+		}
+	}
+	if set["move-to"] {
+		if task, err = client.Tasks.MoveChecklistItem(ctx, task.ID, item.ID, moveTo-1); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "Checklist of todo %s:\n", task.ID)
+	printChecklist(task)
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/internal/config"
+)
+
+// offlineJournalFile is the name of the offline journal below the config directory.
+const offlineJournalFile = "offline-journal.jsonl"
+
+// newClient loads the configuration and creates a Habitica client for a command.
+// In offline mode task mutations that cannot reach the API are journaled for `gohabitica sync`.
+// Additional options are applied after the ones derived from the configuration.
+func newClient(cfgPath string, extra ...habitica.Option) (*habitica.Client, error) {
+	opts := config.Options{ConfigPath: cfgPath}
+	cfg, err := config.Load(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var clientOpts []habitica.Option
+	if cfg.Offline {
+		journal, err := openOfflineJournal()
+		if err != nil {
+			return nil, err
+		}
+		clientOpts = append(clientOpts, habitica.WithOfflineJournal(journal))
+	}
+
+	return habitica.NewClient(cfg, append(clientOpts, extra...)...)
+}
+
+// openOfflineJournal opens the offline journal stored in the config directory.
+func openOfflineJournal() (*habitica.OfflineJournal, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return habitica.OpenOfflineJournal(filepath.Join(dir, offlineJournalFile)), nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// contentCacheTTL is how long the downloaded /content payload is reused
+// before it is revalidated against Habitica.
+const contentCacheTTL = 24 * time.Hour
+
+// runContent dispatches the "content" subcommands.
+func runContent(cfgPath string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing content subcommand; expected: search")
+	}
+
+	switch args[0] {
+	case "search":
+		return runContentSearch(cfgPath, args[1:])
+	default:
+		return fmt.Errorf("unknown content subcommand %q", args[0])
+	}
+}
+
+// runContentSearch searches the static game content.
+//
+// Example usage:
+//   gohabitica content search -kind gear -class rogue -max 100 -currency gold
+//   gohabitica content search -kind quest -drops eggs -json
+func runContentSearch(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("content search", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		kinds    stringSliceFlag
+		text     string
+		class    string
+		set      string
+		typ      string
+		currency string
+		minValue string
+		maxValue string
+		limited  bool
+		event    string
+		drops    string
+		asJSON   bool
+	)
+
+	fs.Var(&kinds, "kind", "Content kind: gear, egg, hatchingPotion, food, quest, spell, background (can be specified multiple times)")
+	fs.StringVar(&text, "text", "", "Case-insensitive text to search in key, name and description")
+	fs.StringVar(&class, "class", "", "Class of gear or spells (warrior, rogue, wizard, healer, ...)")
+	fs.StringVar(&set, "set", "", "Gear set, background set or quest group")
+	fs.StringVar(&typ, "type", "", "Gear slot (weapon, armor, ...), quest category or spell target")
+	fs.StringVar(&currency, "currency", "", "Price currency: gold, gems or mana")
+	fs.StringVar(&minValue, "min", "", "Minimum price (inclusive)")
+	fs.StringVar(&maxValue, "max", "", "Maximum price (inclusive)")
+	fs.BoolVar(&limited, "limited", false, "Only list limited event content")
+	fs.StringVar(&event, "event", "", "Only list content of this event, by season (winter) or event key (winter2024)")
+	fs.StringVar(&drops, "drops", "", "Only list quests dropping items of this type (eggs, hatchingPotions, food, ...)")
+	fs.BoolVar(&asJSON, "json", false, "Print the result as JSON instead of a table")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	q := habitica.ContentQuery{
+		Text:     text,
+		Class:    class,
+		Set:      set,
+		Type:     typ,
+		Currency: currency,
+		Limited:  limited,
+		Event:    event,
+		Drops:    drops,
+	}
+	for _, k := range kinds {
+		q.Kinds = append(q.Kinds, habitica.ContentKind(k))
+	}
+	var err error
+	if q.MinValue, err = parseOptionalFloat("min", minValue); err != nil {
+		return err
+	}
+	if q.MaxValue, err = parseOptionalFloat("max", maxValue); err != nil {
+		return err
+	}
+
+	var clientOpts []habitica.Option
+	if dir, err := habitica.DefaultCacheDir(); err == nil {
+		clientOpts = append(clientOpts, habitica.WithCache(habitica.NewDiskCache(dir, contentCacheTTL), "/content"))
+	}
+
+	client, err := newClient(cfgPath, clientOpts...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	content, err := client.Content.GetContent(ctx)
+	if err != nil {
+		return err
+	}
+
+	items := content.Search(q)
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if items == nil {
+			items = []habitica.ContentItem{}
+		}
+		return enc.Encode(items)
+	}
+
+	if len(items) == 0 {
+		fmt.Fprintln(os.Stdout, "No content found.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tKEY\tNAME\tCLASS\tSET\tTYPE\tPRICE")
+	for _, it := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s %s\n",
+			it.Kind, it.Key, it.Text, it.Class, it.Set, it.Type,
+			strconv.FormatFloat(it.Value, 'f', -1, 64), it.Currency)
+	}
+	return tw.Flush()
+}
+
+// parseOptionalFloat parses a numeric flag value; an empty value yields nil.
+func parseOptionalFloat(name, v string) (*float64, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return nil, fmt.Errorf("flag -%s must be a number: %w", name, err)
+	}
+	return &f, nil
+}
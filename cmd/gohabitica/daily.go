@@ -0,0 +1,307 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// dailySchedule holds the scheduling flags shared by "daily" and "daily-edit".
+type dailySchedule struct {
+	frequency   string
+	every       int
+	days        string
+	daysOfMonth string
+	start       string
+}
+
+func (s *dailySchedule) register(fs *flag.FlagSet) {
+	fs.StringVar(&s.frequency, "frequency", "weekly", "How often the daily repeats: daily, weekly, monthly or yearly")
+	fs.IntVar(&s.every, "every", 1, "Repeat every N days/weeks/months/years")
+	fs.StringVar(&s.days, "days", "all", "Weekdays for weekly dailies, e.g. mon,wed,fri (also: weekdays, weekend, all, none)")
+	fs.StringVar(&s.daysOfMonth, "day-of-month", "", "Days of the month for monthly dailies, e.g. 1,15")
+	fs.StringVar(&s.start, "start", "", "Start date (YYYY-MM-DD); defaults to today")
+}
+
+// runDaily creates a new daily with a repeat schedule.
+//
+// Example usage:
+//   gohabitica daily -text "Workout" -days mon,wed,fri
+//   gohabitica daily -text "Pay rent" -frequency monthly -day-of-month 1
+//   gohabitica daily -text "Water plants" -frequency daily -every 3 -start 2024-03-01
+func runDaily(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("daily", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		text       string
+		notes      string
+		checks     stringSliceFlag
+		difficulty string
+		schedule   dailySchedule
+	)
+
+	fs.StringVar(&text, "text", "", "Title of the daily (required)")
+	fs.StringVar(&notes, "notes", "", "Notes of the daily")
+	fs.Var(&checks, "check", "A checklist entry (can be specified multiple times)")
+	fs.StringVar(&difficulty, "difficulty", "", "Difficulty/priority of the daily (trivial, easy, medium, hard, or numeric)")
+	schedule.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("flag -text is required")
+	}
+
+	priority := 1.0
+	if strings.TrimSpace(difficulty) != "" {
+		p, err := habitica.ParseDifficulty(difficulty)
+		if err != nil {
+			return err
+		}
+		priority = p
+	}
+
+	req := &habitica.TaskCreateRequest{
+		Text:      text,
+		Notes:     notes,
+		Type:      habitica.TaskTypeDaily,
+		Priority:  priority,
+		Attribute: "str",
+	}
+	for _, c := range checks {
+		if c != "" {
+			req.Checklist = append(req.Checklist, habitica.ChecklistItem{Text: c})
+		}
+	}
+
+	update := &habitica.TaskUpdateRequest{}
+	if err := schedule.apply(setFlags(fs), update, true); err != nil {
+		return err
+	}
+	req.Frequency = *update.Frequency
+	req.EveryX = *update.EveryX
+	req.Repeat = update.Repeat
+	if update.DaysOfMonth != nil {
+		req.DaysOfMonth = *update.DaysOfMonth
+	}
+	if update.StartDate != nil {
+		req.StartDate = *update.StartDate
+	}
+
+	task, err := createTask(cfgPath, req)
+	if err != nil || task == nil {
+		return err
+	}
+	if s := describeSchedule(task); s != "" {
+		fmt.Fprintf(os.Stdout, "Schedule: %s\n", s)
+	}
+	return nil
+}
+
+// apply copies the schedule into req. With all set, unset flags contribute
+// their defaults (used on creation); otherwise only explicitly set flags are applied.
+func (s *dailySchedule) apply(set map[string]bool, req *habitica.TaskUpdateRequest, all bool) error {
+	if all || set["frequency"] {
+		f, err := parseFrequency(habitica.TaskTypeDaily, s.frequency)
+		if err != nil {
+			return err
+		}
+		req.Frequency = &f
+	}
+	if all || set["every"] {
+		if s.every < 0 {
+			return fmt.Errorf("flag -every must not be negative")
+		}
+		req.EveryX = &s.every
+	}
+	if (all && (req.Frequency == nil || *req.Frequency == habitica.FrequencyWeekly)) || set["days"] {
+		r, err := habitica.ParseWeekdays(strings.Split(s.days, ",")...)
+		if err != nil {
+			return err
+		}
+		req.Repeat = r
+	}
+	if set["day-of-month"] {
+		days, err := parseDaysOfMonth(s.daysOfMonth)
+		if err != nil {
+			return err
+		}
+		req.DaysOfMonth = &days
+	}
+	if set["start"] {
+		d, err := habitica.ParseDate(s.start, time.Local)
+		if err != nil {
+			return err
+		}
+		ts := habitica.NewTimestamp(d)
+		req.StartDate = &ts
+	}
+	return nil
+}
+
+// describeSchedule renders a short human-readable description of a daily's schedule.
+func describeSchedule(t *habitica.Task) string {
+	every := t.EveryX
+	unit := map[habitica.TaskFrequency]string{
+		habitica.FrequencyDaily:   "day",
+		habitica.FrequencyWeekly:  "week",
+		habitica.FrequencyMonthly: "month",
+		habitica.FrequencyYearly:  "year",
+	}[t.Frequency]
+	if unit == "" {
+		return ""
+	}
+
+	s := "every " + unit
+	if every > 1 {
+		s = fmt.Sprintf("every %d %ss", every, unit)
+	}
+	switch t.Frequency {
+	case habitica.FrequencyWeekly:
+		s += " on " + t.Repeat.String()
+	case habitica.FrequencyMonthly:
+		if len(t.DaysOfMonth) > 0 {
+			days := make([]string, len(t.DaysOfMonth))
+			for i, d := range t.DaysOfMonth {
+				days[i] = strconv.Itoa(d)
+			}
+			s += " on day " + strings.Join(days, ",")
+		}
+	}
+	return s
+}
+
+// runDailiesList lists the user's dailies with their due state and streak.
+//
+// Example usage:
+//   gohabitica dailies
+//   gohabitica dailies -due
+func runDailiesList(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("dailies", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var dueOnly bool
+	fs.BoolVar(&dueOnly, "due", false, "Only list dailies that are due today")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tasks, err := listTasks(cfgPath, "dailys")
+	if err != nil {
+		return err
+	}
+
+	printed := 0
+	for _, t := range tasks {
+		if dueOnly && !t.IsDue {
+			continue
+		}
+		printed++
+
+		status := " "
+		if t.Completed {
+			status = "x"
+		}
+		due := "not due"
+		if t.IsDue {
+			due = "due"
+		}
+		fmt.Fprintf(os.Stdout, "[%s] %s (%s, streak %d, %s) (ID: %s)\n", status, t.Text, due, t.Streak, describeSchedule(t), t.ID)
+		for _, item := range t.Checklist {
+			subStatus := " "
+			if item.Completed {
+				subStatus = "x"
+			}
+			fmt.Fprintf(os.Stdout, "  - [%s] %s\n", subStatus, item.Text)
+		}
+	}
+
+	if printed == 0 {
+		fmt.Fprintln(os.Stdout, "No dailies found.")
+	}
+	return nil
+}
+
+// runDailyScore checks off a daily, or unchecks it with -down.
+//
+// Example usage:
+//   gohabitica daily-score -id "37ceed6f-0772-43bb-a177-39d3074f75b7"
+//   gohabitica daily-score -id "..." -down
+func runDailyScore(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("daily-score", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		id   string
+		down bool
+	)
+	fs.StringVar(&id, "id", "", "ID of the daily to score (required)")
+	fs.BoolVar(&down, "down", false, "Uncheck the daily instead of checking it off")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	taskID, err := requireID(id)
+	if err != nil {
+		return err
+	}
+
+	if down {
+		return scoreTask(cfgPath, taskID, "down", "daily", "unchecked")
+	}
+	return scoreTask(cfgPath, taskID, "up", "daily", "checked off")
+}
+
+// runDailyEdit changes the title, notes, difficulty or schedule of a daily.
+// Only flags that are given are changed.
+//
+// Example usage:
+//   gohabitica daily-edit -id "37ceed6f-0772-43bb-a177-39d3074f75b7" -days weekdays
+//   gohabitica daily-edit -id "..." -text "Workout (30 min)" -difficulty hard
+func runDailyEdit(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("daily-edit", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		common   taskEditFlags
+		schedule dailySchedule
+	)
+	common.register(fs, "daily")
+	schedule.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	taskID, err := requireID(common.id)
+	if err != nil {
+		return err
+	}
+
+	set := setFlags(fs)
+	req := &habitica.TaskUpdateRequest{}
+	if err := common.apply(set, req); err != nil {
+		return err
+	}
+	if err := schedule.apply(set, req, false); err != nil {
+		return err
+	}
+
+	return updateTask(cfgPath, taskID, req, "daily")
+}
+
+// runDailyDelete deletes a daily by its ID.
+//
+// Example usage:
+//   gohabitica daily-delete -id "37ceed6f-0772-43bb-a177-39d3074f75b7"
+func runDailyDelete(cfgPath string, args []string) error {
+	return runTaskDelete(cfgPath, args, "daily")
+}
@@ -2,15 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/danielrichardt/gohabitica/habitica"
-	"github.com/danielrichardt/gohabitica/internal/config"
 )
 
 // stringSliceFlag allows passing a flag multiple times.
@@ -29,6 +28,15 @@ func (s *stringSliceFlag) Set(v string) error {
 // Without arguments, it runs a simple smoke test (GET /user).
 // With subcommand "todo" it creates todos with checklists.
 // With subcommand "todos" it lists existing todos.
+// The "daily", "habit" and "reward" families create, list, score, edit and delete those task types.
+// "plan" and "apply" compare the account with a YAML manifest and apply the differences.
+// "export" and "import" copy all tasks and tags to and from JSON, YAML or CSV files.
+// "todotxt import|export|sync" keeps a todo.txt file and the todos in sync.
+// "md-sync" syncs the task lists of a Markdown file with todos.
+// "ics export" writes due todos and dailies as an iCalendar file.
+// "webhook list|add|edit|rm|enable|disable" manages the webhooks of the account.
+// "automate" runs a local webhook receiver that applies automation rules.
+// With subcommand "sync" it replays task changes journaled in offline mode.
 // With the "-config" flag you can specify an explicit YAML configuration file.
 func execute(args []string) error {
 	fs := flag.NewFlagSet("gohabitica", flag.ContinueOnError)
@@ -58,6 +66,64 @@ func execute(args []string) error {
 		return runTodoComplete(cfgPath, rest[1:])
 	case "todo-check":
 		return runTodoCheck(cfgPath, rest[1:])
+	case "todo-check-add":
+		return runTodoCheckAdd(cfgPath, rest[1:])
+	case "todo-check-remove":
+		return runTodoCheckRemove(cfgPath, rest[1:])
+	case "todo-check-edit":
+		return runTodoCheckEdit(cfgPath, rest[1:])
+	case "daily":
+		return runDaily(cfgPath, rest[1:])
+	case "dailies":
+		return runDailiesList(cfgPath, rest[1:])
+	case "daily-score":
+		return runDailyScore(cfgPath, rest[1:])
+	case "daily-edit":
+		return runDailyEdit(cfgPath, rest[1:])
+	case "daily-delete":
+		return runDailyDelete(cfgPath, rest[1:])
+	case "habit":
+		return runHabit(cfgPath, rest[1:])
+	case "habits":
+		return runHabitsList(cfgPath, rest[1:])
+	case "habit-score":
+		return runHabitScore(cfgPath, rest[1:])
+	case "habit-edit":
+		return runHabitEdit(cfgPath, rest[1:])
+	case "habit-delete":
+		return runHabitDelete(cfgPath, rest[1:])
+	case "reward":
+		return runReward(cfgPath, rest[1:])
+	case "rewards":
+		return runRewardsList(cfgPath, rest[1:])
+	case "reward-buy":
+		return runRewardBuy(cfgPath, rest[1:])
+	case "reward-edit":
+		return runRewardEdit(cfgPath, rest[1:])
+	case "reward-delete":
+		return runRewardDelete(cfgPath, rest[1:])
+	case "plan":
+		return runPlan(cfgPath, rest[1:])
+	case "apply":
+		return runApply(cfgPath, rest[1:])
+	case "export":
+		return runExport(cfgPath, rest[1:])
+	case "import":
+		return runImport(cfgPath, rest[1:])
+	case "todotxt":
+		return runTodotxt(cfgPath, rest[1:])
+	case "md-sync":
+		return runMdSync(cfgPath, rest[1:])
+	case "ics":
+		return runIcs(cfgPath, rest[1:])
+	case "webhook":
+		return runWebhook(cfgPath, rest[1:])
+	case "automate":
+		return runAutomate(cfgPath, rest[1:])
+	case "sync":
+		return runSync(cfgPath, rest[1:])
+	case "content":
+		return runContent(cfgPath, rest[1:])
 	default:
 		return fmt.Errorf("unknown command %q", rest[0])
 	}
@@ -65,13 +131,7 @@ func execute(args []string) error {
 
 // runSmokeTest runs the original /user sanity check.
 func runSmokeTest(cfgPath string) error {
-	opts := config.Options{ConfigPath: cfgPath}
-	cfg, err := config.Load(opts)
-	if err != nil {
-		return err
-	}
-
-	client, err := habitica.NewClient(cfg)
+	client, err := newClient(cfgPath)
 	if err != nil {
 		return err
 	}
@@ -92,6 +152,7 @@ func runSmokeTest(cfgPath string) error {
 //
 // Example usage:
 //   gohabitica todo -text "Shopping" -check "Milk" -check "Bread"
+//   gohabitica todo -text "Write report" -tag work -tag urgent
 //   gohabitica -config config/local.yaml todo -text "Shopping" -check "Milk"
 func runTodo(cfgPath string, args []string) error {
 	fs := flag.NewFlagSet("todo", flag.ContinueOnError)
@@ -100,11 +161,13 @@ func runTodo(cfgPath string, args []string) error {
 	var (
 		text       string
 		checks     stringSliceFlag
+		tags       stringSliceFlag
 		difficulty string
 	)
 
 	fs.StringVar(&text, "text", "", "Title of the todo (required)")
 	fs.Var(&checks, "check", "A checklist entry (can be specified multiple times)")
+	fs.Var(&tags, "tag", "Tag name or ID; unknown names are created (can be specified multiple times)")
 	fs.StringVar(&difficulty, "difficulty", "", "Difficulty/priority of the todo (trivial, easy, medium, hard, or numeric)")
 
 	if err := fs.Parse(args); err != nil {
@@ -117,20 +180,14 @@ func runTodo(cfgPath string, args []string) error {
 
 	priority := 1.0
 	if strings.TrimSpace(difficulty) != "" {
-		p, err := parseDifficulty(difficulty)
+		p, err := habitica.ParseDifficulty(difficulty)
 		if err != nil {
 			return err
 		}
 		priority = p
 	}
 
-	opts := config.Options{ConfigPath: cfgPath}
-	cfg, err := config.Load(opts)
-	if err != nil {
-		return err
-	}
-
-	client, err := habitica.NewClient(cfg)
+	client, err := newClient(cfgPath)
 	if err != nil {
 		return err
 	}
@@ -149,15 +206,29 @@ func runTodo(cfgPath string, args []string) error {
 		})
 	}
 
+	tagIDs, err := client.Tags.ResolveTags(ctx, tags, true)
+	if client.Unreachable(ctx, err) {
+		// Tag names cannot be resolved offline; queue the todo without them.
+		fmt.Fprintf(os.Stderr, "Warning: tags %s could not be resolved offline; the todo is queued without them.\n", strings.Join(tags, ", "))
+		tagIDs = nil
+	} else if err != nil {
+		return err
+	}
+
 	req := &habitica.TaskCreateRequest{
 		Text:      text,
 		Type:      habitica.TaskTypeTodo,
 		Priority:  priority,
 		Attribute: "str",
 		Checklist: items,
+		Tags:      tagIDs,
 	}
 
 	task, err := client.Tasks.CreateTask(ctx, req)
+	if errors.Is(err, habitica.ErrQueuedOffline) {
+		fmt.Fprintf(os.Stdout, "Habitica is unreachable; todo %q has been queued. Run \"gohabitica sync\" once you are online.\n", text)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -173,49 +244,31 @@ func runTodo(cfgPath string, args []string) error {
 	return nil
 }
 
-// parseDifficulty converts a human-friendly difficulty string into a Habitica priority value.
-// Accepts the named levels "trivial", "easy", "medium", "hard" (case-insensitive)
-// or a numeric value (e.g. 0.1, 1, 1.5, 2).
-func parseDifficulty(input string) (float64, error) {
-	s := strings.ToLower(strings.TrimSpace(input))
-
-	switch s {
-	case "trivial":
-		return 0.1, nil
-	case "easy":
-		return 1, nil
-	case "medium":
-		return 1.5, nil
-	case "hard":
-		return 2, nil
-	}
-
-	v, err := strconv.ParseFloat(s, 64)
-	if err != nil {
-		return 0, fmt.Errorf("invalid difficulty %q; expected one of trivial, easy, medium, hard, or a numeric value", input)
-	}
-	if v <= 0 || v > 2 {
-		return 0, fmt.Errorf("difficulty %q is out of allowed range (0 < value <= 2)", input)
-	}
-	return v, nil
-}
-
 // runTodosList lists the user's todos together with their checklist entries.
+// Todos can be filtered by tag and grouped by tag name.
 //
 // Example usage:
 //   gohabitica todos
+//   gohabitica todos -tag work
+//   gohabitica todos -group-by-tag
 //   gohabitica -config config/local.yaml todos
 func runTodosList(cfgPath string, args []string) error {
-	// currently no flags; can be extended later if needed
-	_ = args
+	fs := flag.NewFlagSet("todos", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
 
-	opts := config.Options{ConfigPath: cfgPath}
-	cfg, err := config.Load(opts)
-	if err != nil {
+	var (
+		tags       stringSliceFlag
+		groupByTag bool
+	)
+
+	fs.Var(&tags, "tag", "Only list todos with this tag name or ID (can be specified multiple times; all must match)")
+	fs.BoolVar(&groupByTag, "group-by-tag", false, "Group the todos by tag name")
+
+	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
-	client, err := habitica.NewClient(cfg)
+	client, err := newClient(cfgPath)
 	if err != nil {
 		return err
 	}
@@ -228,31 +281,117 @@ func runTodosList(cfgPath string, args []string) error {
 		return err
 	}
 
+	var index *habitica.TagIndex
+	var allTags []*habitica.Tag
+	if len(tags) > 0 || groupByTag {
+		if allTags, err = client.Tags.ListTags(ctx); err != nil {
+			return err
+		}
+		index = habitica.NewTagIndex(allTags)
+	}
+
+	if len(tags) > 0 {
+		var want []habitica.UUID
+		for _, name := range tags {
+			tag := index.Lookup(name)
+			if tag == nil {
+				return fmt.Errorf("unknown tag %q", name)
+			}
+			want = append(want, tag.ID)
+		}
+		tasks = filterByTags(tasks, want)
+	}
+
 	if len(tasks) == 0 {
 		fmt.Fprintln(os.Stdout, "No todos found.")
 		return nil
 	}
 
-	for _, t := range tasks {
-		status := " "
-		if t.Completed {
-			status = "x"
+	if !groupByTag {
+		for _, t := range tasks {
+			printTodo(t, "")
+		}
+		return nil
+	}
+
+	// Groups follow the user's tag order; todos with several tags appear in each group.
+	for _, tag := range allTags {
+		group := filterByTags(tasks, []habitica.UUID{tag.ID})
+		if len(group) == 0 {
+			continue
 		}
-		fmt.Fprintf(os.Stdout, "[%s] %s (ID: %s)\n", status, t.Text, t.ID)
-		if len(t.Checklist) > 0 {
-			for _, item := range t.Checklist {
-				subStatus := " "
-				if item.Completed {
-					subStatus = "x"
-				}
-				fmt.Fprintf(os.Stdout, "  - [%s] %s\n", subStatus, item.Text)
+		fmt.Fprintf(os.Stdout, "%s:\n", tag.Name)
+		for _, t := range group {
+			printTodo(t, "  ")
+		}
+	}
+	// Todos whose tags are all unknown (deleted or challenge tags) would
+	// otherwise appear in no group.
+	known := make(map[habitica.UUID]bool, len(allTags))
+	for _, tag := range allTags {
+		known[tag.ID] = true
+	}
+	var untagged []*habitica.Task
+	for _, t := range tasks {
+		hasKnown := false
+		for _, id := range t.Tags {
+			if known[id] {
+				hasKnown = true
+				break
 			}
 		}
+		if !hasKnown {
+			untagged = append(untagged, t)
+		}
+	}
+	if len(untagged) > 0 {
+		fmt.Fprintln(os.Stdout, "(no tag):")
+		for _, t := range untagged {
+			printTodo(t, "  ")
+		}
 	}
 
 	return nil
 }
 
+// filterByTags returns the tasks that carry all of the given tags.
+func filterByTags(tasks []*habitica.Task, tagIDs []habitica.UUID) []*habitica.Task {
+	var out []*habitica.Task
+	for _, t := range tasks {
+		has := make(map[habitica.UUID]bool, len(t.Tags))
+		for _, id := range t.Tags {
+			has[id] = true
+		}
+		match := true
+		for _, id := range tagIDs {
+			if !has[id] {
+				match = false
+				break
+			}
+		}
+		if match {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// printTodo prints a todo and its checklist with the given indentation.
+func printTodo(t *habitica.Task, indent string) {
+	status := " "
+	if t.Completed {
+		status = "x"
+	}
+	fmt.Fprintf(os.Stdout, "%s[%s] %s (ID: %s)\n", indent, status, t.Text, t.ID)
+	for _, item := range t.Checklist {
+		subStatus := " "
+		if item.Completed {
+			subStatus = "x"
+		}
+		fmt.Fprintf(os.Stdout, "%s  - [%s] %s\n", indent, subStatus, item.Text)
+	}
+}
+
 // runTodoDelete deletes a todo by its ID.
 //
 // Example usage:
@@ -272,13 +411,7 @@ func runTodoDelete(cfgPath string, args []string) error {
 		return fmt.Errorf("flag -id is required")
 	}
 
-	opts := config.Options{ConfigPath: cfgPath}
-	cfg, err := config.Load(opts)
-	if err != nil {
-		return err
-	}
-
-	client, err := habitica.NewClient(cfg)
+	client, err := newClient(cfgPath)
 	if err != nil {
 		return err
 	}
@@ -286,7 +419,12 @@ func runTodoDelete(cfgPath string, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := client.Tasks.DeleteTask(ctx, habitica.UUID(id)); err != nil {
+	err = client.Tasks.DeleteTask(ctx, habitica.UUID(id))
+	if errors.Is(err, habitica.ErrQueuedOffline) {
+		fmt.Fprintf(os.Stdout, "Habitica is unreachable; deletion of todo %s has been queued. Run \"gohabitica sync\" once you are online.\n", id)
+		return nil
+	}
+	if err != nil {
 		return err
 	}
 
@@ -313,13 +451,7 @@ func runTodoComplete(cfgPath string, args []string) error {
 		return fmt.Errorf("flag -id is required")
 	}
 
-	opts := config.Options{ConfigPath: cfgPath}
-	cfg, err := config.Load(opts)
-	if err != nil {
-		return err
-	}
-
-	client, err := habitica.NewClient(cfg)
+	client, err := newClient(cfgPath)
 	if err != nil {
 		return err
 	}
@@ -327,11 +459,16 @@ func runTodoComplete(cfgPath string, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := client.Tasks.ScoreTask(ctx, habitica.UUID(id), "up"); err != nil {
+	summary, err := scoreAndSummarize(ctx, client, habitica.UUID(id), "up")
+	if errors.Is(err, habitica.ErrQueuedOffline) {
+		fmt.Fprintf(os.Stdout, "Habitica is unreachable; completion of todo %s has been queued. Run \"gohabitica sync\" once you are online.\n", id)
+		return nil
+	}
+	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(os.Stdout, "Todo with ID %s has been completed.\n", id)
+	fmt.Fprintf(os.Stdout, "Todo with ID %s has been completed%s\n", id, summary)
 	return nil
 }
 
@@ -362,13 +499,7 @@ func runTodoCheck(cfgPath string, args []string) error {
 		return fmt.Errorf("flag -index must be greater than zero")
 	}
 
-	opts := config.Options{ConfigPath: cfgPath}
-	cfg, err := config.Load(opts)
-	if err != nil {
-		return err
-	}
-
-	client, err := habitica.NewClient(cfg)
+	client, err := newClient(cfgPath)
 	if err != nil {
 		return err
 	}
@@ -376,21 +507,10 @@ func runTodoCheck(cfgPath string, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	task, err := client.Tasks.GetTask(ctx, habitica.UUID(id))
+	task, item, err := loadTodoChecklistItem(ctx, client, id, index)
 	if err != nil {
 		return err
 	}
-	if task.Type != habitica.TaskTypeTodo {
-		return fmt.Errorf("task %s is not a todo", id)
-	}
-	if len(task.Checklist) == 0 {
-		return fmt.Errorf("todo %s has no checklist items", id)
-	}
-	if index > len(task.Checklist) {
-		return fmt.Errorf("flag -index is out of range: todo %s only has %d checklist items", id, len(task.Checklist))
-	}
-
-	item := task.Checklist[index-1]
 	if err := client.Tasks.UpdateChecklistItemCompleted(ctx, task.ID, item.ID, !item.Completed); err != nil {
 		return err
 	}
@@ -399,3 +519,48 @@ func runTodoCheck(cfgPath string, args []string) error {
 	return nil
 }
 
+// runSync replays task changes that were journaled while Habitica was unreachable.
+//
+// Example usage:
+//   gohabitica sync
+//   gohabitica -config config/local.yaml sync
+func runSync(cfgPath string, args []string) error {
+	// currently no flags; can be extended later if needed
+	_ = args
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	journal, err := openOfflineJournal()
+	if err != nil {
+		return err
+	}
+
+	entries, err := journal.Entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stdout, "Nothing to sync.")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	report, err := client.ReplayOfflineJournal(ctx, journal)
+	if report != nil {
+		for _, e := range report.Applied {
+			fmt.Fprintf(os.Stdout, "Synced: %s %s\n", e.Method, e.Path)
+		}
+		for _, c := range report.Conflicts {
+			fmt.Fprintf(os.Stdout, "Conflict: %s %s – %s (%v)\n", c.Entry.Method, c.Entry.Path, c.Reason, c.Err)
+		}
+		if len(report.Pending) > 0 {
+			fmt.Fprintf(os.Stdout, "%d change(s) still pending.\n", len(report.Pending))
+		}
+	}
+	return err
+}
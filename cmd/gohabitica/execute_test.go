@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTodo_OfflineWithTags(t *testing.T) {
+	srv := httptest.NewServer(nil)
+	srv.Close() // the API is unreachable
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("HABITICA_USER_ID", "")
+	t.Setenv("HABITICA_API_TOKEN", "")
+	t.Setenv("HABITICA_OFFLINE", "")
+	cfgPath := filepath.Join(dir, "config.yaml")
+	cfg := "base_url: " + srv.URL + "\nuser_id: user\napi_token: token\noffline: true\n"
+	require.NoError(t, os.WriteFile(cfgPath, []byte(cfg), 0o600))
+
+	require.NoError(t, runTodo(cfgPath, []string{"-text", "Write report", "-tag", "work"}))
+
+	journal, err := openOfflineJournal()
+	require.NoError(t, err)
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "/tasks/user", entries[0].Path)
+
+	var req habitica.TaskCreateRequest
+	require.NoError(t, json.Unmarshal(entries[0].Body, &req))
+	require.Equal(t, "Write report", req.Text)
+	require.Empty(t, req.Tags)
+}
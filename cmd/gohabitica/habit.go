@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// runHabit creates a new habit with positive and/or negative scoring.
+//
+// Example usage:
+//   gohabitica habit -text "Drink water"
+//   gohabitica habit -text "Snacking" -up=false
+//   gohabitica habit -text "Floss" -down=false -frequency weekly
+func runHabit(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("habit", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		text       string
+		notes      string
+		difficulty string
+		up         bool
+		down       bool
+		frequency  string
+	)
+
+	fs.StringVar(&text, "text", "", "Title of the habit (required)")
+	fs.StringVar(&notes, "notes", "", "Notes of the habit")
+	fs.StringVar(&difficulty, "difficulty", "", "Difficulty/priority of the habit (trivial, easy, medium, hard, or numeric)")
+	fs.BoolVar(&up, "up", true, "Enable the positive (+) button")
+	fs.BoolVar(&down, "down", true, "Enable the negative (-) button")
+	fs.StringVar(&frequency, "frequency", "daily", "When the counters reset: daily, weekly or monthly")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("flag -text is required")
+	}
+
+	priority := 1.0
+	if strings.TrimSpace(difficulty) != "" {
+		p, err := habitica.ParseDifficulty(difficulty)
+		if err != nil {
+			return err
+		}
+		priority = p
+	}
+	freq, err := parseFrequency(habitica.TaskTypeHabit, frequency)
+	if err != nil {
+		return err
+	}
+
+	_, err = createTask(cfgPath, &habitica.TaskCreateRequest{
+		Text:      text,
+		Notes:     notes,
+		Type:      habitica.TaskTypeHabit,
+		Priority:  priority,
+		Attribute: "str",
+		Up:        &up,
+		Down:      &down,
+		Frequency: freq,
+	})
+	return err
+}
+
+// runHabitsList lists the user's habits with their counters.
+//
+// Example usage:
+//   gohabitica habits
+func runHabitsList(cfgPath string, args []string) error {
+	// currently no flags; can be extended later if needed
+	_ = args
+
+	tasks, err := listTasks(cfgPath, "habits")
+	if err != nil {
+		return err
+	}
+
+	if len(tasks) == 0 {
+		fmt.Fprintln(os.Stdout, "No habits found.")
+		return nil
+	}
+
+	for _, t := range tasks {
+		buttons := ""
+		if t.Up {
+			buttons += "+"
+		}
+		if t.Down {
+			buttons += "-"
+		}
+		fmt.Fprintf(os.Stdout, "[%-2s] %s (+%d/-%d %s) (ID: %s)\n", buttons, t.Text, t.CounterUp, t.CounterDown, t.Frequency, t.ID)
+	}
+
+	return nil
+}
+
+// runHabitScore scores a habit up, or down with -down.
+//
+// Example usage:
+//   gohabitica habit-score -id "37ceed6f-0772-43bb-a177-39d3074f75b7"
+//   gohabitica habit-score -id "..." -down
+func runHabitScore(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("habit-score", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		id   string
+		down bool
+	)
+	fs.StringVar(&id, "id", "", "ID of the habit to score (required)")
+	fs.BoolVar(&down, "down", false, "Score the habit down (-) instead of up (+)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	taskID, err := requireID(id)
+	if err != nil {
+		return err
+	}
+
+	if down {
+		return scoreTask(cfgPath, taskID, "down", "habit", "scored down")
+	}
+	return scoreTask(cfgPath, taskID, "up", "habit", "scored up")
+}
+
+// runHabitEdit changes a habit. Only flags that are given are changed.
+//
+// Example usage:
+//   gohabitica habit-edit -id "37ceed6f-0772-43bb-a177-39d3074f75b7" -down=false
+//   gohabitica habit-edit -id "..." -text "Drink 2l water" -frequency weekly
+func runHabitEdit(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("habit-edit", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		common    taskEditFlags
+		up        bool
+		down      bool
+		frequency string
+	)
+	common.register(fs, "habit")
+	fs.BoolVar(&up, "up", true, "Enable or disable the positive (+) button")
+	fs.BoolVar(&down, "down", true, "Enable or disable the negative (-) button")
+	fs.StringVar(&frequency, "frequency", "", "When the counters reset: daily, weekly or monthly")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	taskID, err := requireID(common.id)
+	if err != nil {
+		return err
+	}
+
+	set := setFlags(fs)
+	req := &habitica.TaskUpdateRequest{}
+	if err := common.apply(set, req); err != nil {
+		return err
+	}
+	if set["up"] {
+		req.Up = &up
+	}
+	if set["down"] {
+		req.Down = &down
+	}
+	if set["frequency"] {
+		f, err := parseFrequency(habitica.TaskTypeHabit, frequency)
+		if err != nil {
+			return err
+		}
+		req.Frequency = &f
+	}
+
+	return updateTask(cfgPath, taskID, req, "habit")
+}
+
+// runHabitDelete deletes a habit by its ID.
+//
+// Example usage:
+//   gohabitica habit-delete -id "37ceed6f-0772-43bb-a177-39d3074f75b7"
+func runHabitDelete(cfgPath string, args []string) error {
+	return runTaskDelete(cfgPath, args, "habit")
+}
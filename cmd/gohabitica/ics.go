@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica/ics"
+)
+
+// runIcs dispatches the "ics" subcommands.
+func runIcs(cfgPath string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing ics subcommand; expected: export")
+	}
+
+	switch args[0] {
+	case "export":
+		return runIcsExport(cfgPath, args[1:])
+	default:
+		return fmt.Errorf("unknown ics subcommand %q", args[0])
+	}
+}
+
+// runIcsExport writes the due todos and the dailies as an iCalendar file.
+// Dates are computed in the time zone of the Habitica account.
+//
+// Example usage:
+//   gohabitica ics export -o habitica.ics
+//   gohabitica ics export -todos-as-events -no-dailies > todos.ics
+func runIcsExport(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("ics export", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		out           string
+		name          string
+		todosAsEvents bool
+		noDailies     bool
+	)
+
+	fs.StringVar(&out, "o", "", "Output file (default: stdout)")
+	fs.StringVar(&name, "name", "Habitica", "Calendar name")
+	fs.BoolVar(&todosAsEvents, "todos-as-events", false, "Export todos as all-day events instead of tasks")
+	fs.BoolVar(&noDailies, "no-dailies", false, "Leave out the dailies")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user, err := client.User.GetCurrent(ctx)
+	if err != nil {
+		return err
+	}
+	opts := ics.Options{
+		Name:          name,
+		TodosAsEvents: todosAsEvents,
+		SkipDailies:   noDailies,
+		Location:      user.Preferences.Location(),
+	}
+
+	var buf bytes.Buffer
+	if err := ics.Export(ctx, client, &buf, opts); err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err := buf.WriteTo(os.Stdout)
+		return err
+	}
+	if err := os.WriteFile(out, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Exported calendar to %s.\n", out)
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/manifest"
+)
+
+// runPlan shows the changes "apply" would make for a manifest, without changing anything.
+//
+// Example usage:
+//   gohabitica plan -f tasks.yaml
+//   gohabitica plan -f tasks.yaml -prune
+func runPlan(cfgPath string, args []string) error {
+	_, plan, err := loadPlan(cfgPath, "plan", args)
+	if err != nil {
+		return err
+	}
+	return plan.Write(os.Stdout)
+}
+
+// runApply makes the account match a manifest: it prints the plan and executes it.
+//
+// Example usage:
+//   gohabitica apply -f tasks.yaml
+//   gohabitica apply -f tasks.yaml -prune
+func runApply(cfgPath string, args []string) error {
+	client, plan, err := loadPlan(cfgPath, "apply", args)
+	if err != nil {
+		return err
+	}
+	if err := plan.Write(os.Stdout); err != nil {
+		return err
+	}
+	if plan.Empty() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := manifest.Apply(ctx, client, plan); err != nil {
+		if errors.Is(err, habitica.ErrQueuedOffline) {
+			fmt.Fprintln(os.Stdout, "Offline: the remaining changes were not applied; run \"gohabitica sync\" and apply again.")
+		}
+		return err
+	}
+
+	create, update, del := plan.Counts()
+	fmt.Fprintf(os.Stdout, "Apply complete: %d created, %d updated, %d deleted.\n", create, update, del)
+	return nil
+}
+
+// loadPlan parses the shared flags of "plan" and "apply", loads the manifest
+// and diffs it against the live account.
+func loadPlan(cfgPath, name string, args []string) (*habitica.Client, *manifest.Plan, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		file  string
+		prune bool
+	)
+
+	fs.StringVar(&file, "f", "", "Path to the manifest YAML file (required)")
+	fs.BoolVar(&prune, "prune", false, "Delete aliased tasks and unused tags that are not in the manifest")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if strings.TrimSpace(file) == "" {
+		return nil, nil, fmt.Errorf("flag -f is required")
+	}
+
+	m, err := manifest.Load(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	live, err := manifest.Fetch(ctx, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, manifest.Diff(m, live, manifest.Options{Prune: prune}), nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica/mdsync"
+)
+
+// runMdSync syncs the task lists of a Markdown file with Habitica todos.
+// Headings become todos and their checkboxes checklist items.
+//
+// Example usage:
+//   gohabitica md-sync notes.md
+//   gohabitica md-sync -prefer habitica -dry-run notes.md
+func runMdSync(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("md-sync", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		prefer string
+		dryRun bool
+	)
+
+	fs.StringVar(&prefer, "prefer", "", "Resolve conflicts in favor of: file, habitica (default: report them)")
+	fs.BoolVar(&dryRun, "dry-run", false, "Only show the changes")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one Markdown file")
+	}
+	path := fs.Arg(0)
+
+	opts := mdsync.Options{DryRun: dryRun}
+	switch prefer {
+	case "":
+	case "file":
+		opts.Prefer = mdsync.PreferFile
+	case "habitica":
+		opts.Prefer = mdsync.PreferHabitica
+	default:
+		return fmt.Errorf("invalid -prefer %q; expected file or habitica", prefer)
+	}
+
+	doc, err := mdsync.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	original := doc.Bytes()
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	changes, err := mdsync.Sync(ctx, client, doc, opts)
+	conflicts := 0
+	for _, c := range changes {
+		fmt.Fprintln(os.Stdout, c)
+		if c.Action == "conflict" {
+			conflicts++
+		}
+	}
+
+	// Write even after an error, so that created todos stay linked.
+	if !dryRun && !bytes.Equal(original, doc.Bytes()) {
+		if werr := doc.WriteFile(path); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(os.Stdout, "Already in sync.")
+	}
+	if conflicts > 0 {
+		fmt.Fprintf(os.Stdout, "%d conflict(s) left unchanged; rerun with -prefer file or -prefer habitica.\n", conflicts)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// runReward creates a new custom reward.
+//
+// Example usage:
+//   gohabitica reward -text "Episode of a series" -value 25
+func runReward(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("reward", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		text  string
+		notes string
+		value float64
+	)
+
+	fs.StringVar(&text, "text", "", "Title of the reward (required)")
+	fs.StringVar(&notes, "notes", "", "Notes of the reward")
+	fs.Float64Var(&value, "value", 10, "Price of the reward in gold")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("flag -text is required")
+	}
+	if value < 0 {
+		return fmt.Errorf("flag -value must not be negative")
+	}
+
+	_, err := createTask(cfgPath, &habitica.TaskCreateRequest{
+		Text:  text,
+		Notes: notes,
+		Type:  habitica.TaskTypeReward,
+		Value: value,
+	})
+	return err
+}
+
+// runRewardsList lists the user's custom rewards with their price.
+//
+// Example usage:
+//   gohabitica rewards
+func runRewardsList(cfgPath string, args []string) error {
+	// currently no flags; can be extended later if needed
+	_ = args
+
+	tasks, err := listTasks(cfgPath, "rewards")
+	if err != nil {
+		return err
+	}
+
+	if len(tasks) == 0 {
+		fmt.Fprintln(os.Stdout, "No rewards found.")
+		return nil
+	}
+
+	for _, t := range tasks {
+		fmt.Fprintf(os.Stdout, "%s (%g GP) (ID: %s)\n", t.Text, t.Value, t.ID)
+	}
+
+	return nil
+}
+
+// runRewardBuy buys a custom reward, spending its price in gold.
+//
+// Example usage:
+//   gohabitica reward-buy -id "37ceed6f-0772-43bb-a177-39d3074f75b7"
+func runRewardBuy(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("reward-buy", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var id string
+	fs.StringVar(&id, "id", "", "ID of the reward to buy (required)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	taskID, err := requireID(id)
+	if err != nil {
+		return err
+	}
+
+	return scoreTask(cfgPath, taskID, "up", "reward", "bought")
+}
+
+// runRewardEdit changes a reward. Only flags that are given are changed.
+//
+// Example usage:
+//   gohabitica reward-edit -id "37ceed6f-0772-43bb-a177-39d3074f75b7" -value 40
+func runRewardEdit(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("reward-edit", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		id    string
+		text  string
+		notes string
+		value float64
+	)
+	fs.StringVar(&id, "id", "", "ID of the reward to edit (required)")
+	fs.StringVar(&text, "text", "", "New title")
+	fs.StringVar(&notes, "notes", "", "New notes")
+	fs.Float64Var(&value, "value", 0, "New price in gold")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	taskID, err := requireID(id)
+	if err != nil {
+		return err
+	}
+
+	set := setFlags(fs)
+	req := &habitica.TaskUpdateRequest{}
+	if set["text"] {
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("flag -text must not be empty")
+		}
+		req.Text = &text
+	}
+	if set["notes"] {
+		req.Notes = &notes
+	}
+	if set["value"] {
+		if value < 0 {
+			return fmt.Errorf("flag -value must not be negative")
+		}
+		req.Value = &value
+	}
+
+	return updateTask(cfgPath, taskID, req, "reward")
+}
+
+// runRewardDelete deletes a reward by its ID.
+//
+// Example usage:
+//   gohabitica reward-delete -id "37ceed6f-0772-43bb-a177-39d3074f75b7"
+func runRewardDelete(cfgPath string, args []string) error {
+	return runTaskDelete(cfgPath, args, "reward")
+}
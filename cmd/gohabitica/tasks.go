@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// Shared helpers for the daily, habit and reward command families.
+
+// printQueued reports that a change was journaled in offline mode.
+func printQueued(what string) {
+	fmt.Fprintf(os.Stdout, "Habitica is unreachable; %s has been queued. Run \"gohabitica sync\" once you are online.\n", what)
+}
+
+// setFlags returns the names of the flags that were given explicitly.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// requireID validates the mandatory -id flag.
+func requireID(id string) (habitica.UUID, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", fmt.Errorf("flag -id is required")
+	}
+	return habitica.UUID(id), nil
+}
+
+// runTaskDelete deletes a task of the given kind ("daily", "habit", "reward") by its ID.
+func runTaskDelete(cfgPath string, args []string, kind string) error {
+	fs := flag.NewFlagSet(kind+"-delete", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var id string
+	fs.StringVar(&id, "id", "", "ID of the "+kind+" to delete (required)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	taskID, err := requireID(id)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = client.Tasks.DeleteTask(ctx, taskID)
+	if errors.Is(err, habitica.ErrQueuedOffline) {
+		printQueued(fmt.Sprintf("deletion of %s %s", kind, taskID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "%s with ID %s has been deleted.\n", capitalize(kind), taskID)
+	return nil
+}
+
+// scoreTask scores a task and prints the outcome using the given past-tense verb.
+func scoreTask(cfgPath string, taskID habitica.UUID, direction, kind, verb string) error {
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	summary, err := scoreAndSummarize(ctx, client, taskID, direction)
+	if errors.Is(err, habitica.ErrQueuedOffline) {
+		printQueued(fmt.Sprintf("scoring of %s %s", kind, taskID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "%s with ID %s has been %s%s\n", capitalize(kind), taskID, verb, summary)
+	return nil
+}
+
+// scoreAndSummarize scores a task and describes the rewards, e.g.
+// ": +12 XP, +3.4 GP, found a Wolf Egg". The stats are fetched beforehand to
+// compute the gains; if that fails, only the side effects are reported.
+func scoreAndSummarize(ctx context.Context, client *habitica.Client, taskID habitica.UUID, direction string) (string, error) {
+	before, statsErr := client.User.GetStats(ctx)
+
+	res, err := client.Tasks.ScoreTask(ctx, taskID, direction)
+	if err != nil {
+		return "", err
+	}
+	if statsErr != nil {
+		before = nil
+	}
+
+	parts := formatScore(res, before)
+	if len(parts) == 0 {
+		return ".", nil
+	}
+	return ": " + strings.Join(parts, ", "), nil
+}
+
+// formatScore renders the gains and side effects of a score.
+func formatScore(res *habitica.ScoreResult, before *habitica.UserStats) []string {
+	var parts []string
+	if before != nil {
+		d := res.Diff(before)
+		for _, stat := range []struct {
+			value float64
+			unit  string
+		}{
+			{d.Exp, "XP"},
+			{d.GP, "GP"},
+			{d.HP, "HP"},
+			{d.MP, "MP"},
+		} {
+			if s := formatSigned(stat.value); s != "" {
+				parts = append(parts, s+" "+stat.unit)
+			}
+		}
+	}
+
+	tmp := res.Tmp
+	if tmp.LeveledUp != nil && tmp.LeveledUp.NewLvl > 0 {
+		parts = append(parts, fmt.Sprintf("reached level %d", tmp.LeveledUp.NewLvl))
+	} else if before != nil && res.Lvl > before.Lvl {
+		parts = append(parts, fmt.Sprintf("reached level %d", res.Lvl))
+	}
+	if tmp.Crit > 0 {
+		parts = append(parts, fmt.Sprintf("critical hit (x%.2g)", tmp.Crit))
+	}
+	if tmp.Drop != nil && tmp.Drop.Key != "" {
+		parts = append(parts, "found a "+tmp.Drop.Name())
+	}
+	if q := tmp.Quest; q != nil {
+		if s := formatSigned(q.ProgressDelta); s != "" && q.ProgressDelta > 0 {
+			parts = append(parts, strings.TrimPrefix(s, "+")+" quest damage")
+		}
+		if q.Collection > 0 {
+			parts = append(parts, fmt.Sprintf("collected %d quest item(s)", q.Collection))
+		}
+	}
+	return parts
+}
+
+// formatSigned formats v with a sign and at most one decimal; it returns ""
+// for values that round to zero.
+func formatSigned(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 1, 64)
+	s = strings.TrimSuffix(s, ".0")
+	if s == "0" || s == "-0" {
+		return ""
+	}
+	if v > 0 {
+		s = "+" + s
+	}
+	return s
+}
+
+// listTasks fetches the user's tasks of one type ("habits", "dailys", "rewards", ...).
+func listTasks(cfgPath, typ string) ([]*habitica.Task, error) {
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{Type: typ})
+}
+
+// createTask creates a task and prints a confirmation.
+func createTask(cfgPath string, req *habitica.TaskCreateRequest) (*habitica.Task, error) {
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := client.Tasks.CreateTask(ctx, req)
+	if errors.Is(err, habitica.ErrQueuedOffline) {
+		printQueued(fmt.Sprintf("%s %q", req.Type, req.Text))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stdout, "%s created: %s (ID: %s)\n", capitalize(string(req.Type)), task.Text, task.ID)
+	return task, nil
+}
+
+// updateTask applies an update and prints a confirmation.
+func updateTask(cfgPath string, taskID habitica.UUID, req *habitica.TaskUpdateRequest, kind string) error {
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := client.Tasks.UpdateTask(ctx, taskID, req)
+	if errors.Is(err, habitica.ErrQueuedOffline) {
+		printQueued(fmt.Sprintf("update of %s %s", kind, taskID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "%s updated: %s (ID: %s)\n", capitalize(kind), task.Text, task.ID)
+	return nil
+}
+
+// taskEditFlags holds the flags shared by all *-edit commands.
+type taskEditFlags struct {
+	id         string
+	text       string
+	notes      string
+	difficulty string
+}
+
+func (f *taskEditFlags) register(fs *flag.FlagSet, kind string) {
+	fs.StringVar(&f.id, "id", "", "ID of the "+kind+" to edit (required)")
+	fs.StringVar(&f.text, "text", "", "New title")
+	fs.StringVar(&f.notes, "notes", "", "New notes")
+	fs.StringVar(&f.difficulty, "difficulty", "", "New difficulty (trivial, easy, medium, hard, or numeric)")
+}
+
+// apply copies the explicitly set flags into req.
+func (f *taskEditFlags) apply(set map[string]bool, req *habitica.TaskUpdateRequest) error {
+	if set["text"] {
+		if strings.TrimSpace(f.text) == "" {
+			return fmt.Errorf("flag -text must not be empty")
+		}
+		req.Text = &f.text
+	}
+	if set["notes"] {
+		req.Notes = &f.notes
+	}
+	if set["difficulty"] {
+		p, err := habitica.ParseDifficulty(f.difficulty)
+		if err != nil {
+			return err
+		}
+		req.Priority = &p
+	}
+	return nil
+}
+
+// parseDaysOfMonth parses a comma-separated list of days such as "1,15".
+func parseDaysOfMonth(input string) ([]int, error) {
+	var days []int
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := strconv.Atoi(part)
+		if err != nil || d < 1 || d > 31 {
+			return nil, fmt.Errorf("invalid day of month %q; expected a number between 1 and 31", part)
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+// parseFrequency validates the frequency of a task of the given type:
+// dailies repeat daily, weekly, monthly or yearly, habits reset their
+// counters daily, weekly or monthly.
+func parseFrequency(typ habitica.TaskType, input string) (habitica.TaskFrequency, error) {
+	switch f := habitica.TaskFrequency(strings.ToLower(strings.TrimSpace(input))); f {
+	case habitica.FrequencyDaily, habitica.FrequencyWeekly, habitica.FrequencyMonthly:
+		return f, nil
+	case habitica.FrequencyYearly:
+		if typ == habitica.TaskTypeDaily {
+			return f, nil
+		}
+	}
+	if typ == habitica.TaskTypeDaily {
+		return "", fmt.Errorf("invalid frequency %q; expected daily, weekly, monthly or yearly", input)
+	}
+	return "", fmt.Errorf("invalid frequency %q for a %s; expected daily, weekly or monthly", input, typ)
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFrequency(t *testing.T) {
+	f, err := parseFrequency(habitica.TaskTypeDaily, " Yearly ")
+	require.NoError(t, err)
+	require.Equal(t, habitica.FrequencyYearly, f)
+
+	f, err = parseFrequency(habitica.TaskTypeHabit, "weekly")
+	require.NoError(t, err)
+	require.Equal(t, habitica.FrequencyWeekly, f)
+
+	_, err = parseFrequency(habitica.TaskTypeHabit, "yearly")
+	require.EqualError(t, err, `invalid frequency "yearly" for a habit; expected daily, weekly or monthly`)
+
+	_, err = parseFrequency(habitica.TaskTypeDaily, "hourly")
+	require.ErrorContains(t, err, "expected daily, weekly, monthly or yearly")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica/todotxt"
+)
+
+// runTodotxt dispatches the "todotxt" subcommands.
+func runTodotxt(cfgPath string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing todotxt subcommand; expected: import, export, sync")
+	}
+
+	switch args[0] {
+	case "import":
+		return runTodotxtSync(cfgPath, "import", todotxt.ModeImport, args[1:])
+	case "export":
+		return runTodotxtSync(cfgPath, "export", todotxt.ModeExport, args[1:])
+	case "sync":
+		return runTodotxtSync(cfgPath, "sync", todotxt.ModeSync, args[1:])
+	default:
+		return fmt.Errorf("unknown todotxt subcommand %q", args[0])
+	}
+}
+
+// runTodotxtSync imports a todo.txt file, exports the todos to it or syncs both ways.
+// The file is rewritten afterwards, so that every line records the alias of its todo.
+// Todos whose line was removed are only deleted by "sync -prune".
+//
+// Example usage:
+//   gohabitica todotxt import -f ~/todo.txt
+//   gohabitica todotxt export -f ~/todo.txt
+//   gohabitica todotxt sync -f ~/todo.txt -dry-run
+//   gohabitica todotxt sync -f ~/todo.txt -prune -prefer habitica
+func runTodotxtSync(cfgPath, name string, mode todotxt.Mode, args []string) error {
+	fs := flag.NewFlagSet("todotxt "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		path   string
+		prefer string
+		prune  bool
+		dryRun bool
+	)
+
+	fs.StringVar(&path, "f", "todo.txt", "Path to the todo.txt file")
+	if mode == todotxt.ModeSync {
+		fs.StringVar(&prefer, "prefer", "", "Resolve conflicts in favor of: file, habitica (default: report them)")
+		fs.BoolVar(&prune, "prune", false, "Delete todos of this file whose line was removed")
+	}
+	fs.BoolVar(&dryRun, "dry-run", false, "Only show the changes")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(path) == "" {
+		return fmt.Errorf("flag -f must not be empty")
+	}
+
+	opts := todotxt.Options{
+		Mode:   mode,
+		File:   todotxt.FileID(path),
+		Prune:  prune,
+		DryRun: dryRun,
+	}
+	switch prefer {
+	case "":
+	case "file":
+		opts.Prefer = todotxt.PreferFile
+	case "habitica":
+		opts.Prefer = todotxt.PreferHabitica
+	default:
+		return fmt.Errorf("invalid -prefer %q; expected file or habitica", prefer)
+	}
+
+	items, err := todotxt.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	out, changes, err := todotxt.Sync(ctx, client, items, opts)
+	orphans, conflicts := 0, 0
+	for _, c := range changes {
+		fmt.Fprintln(os.Stdout, c)
+		switch c.Action {
+		case "orphan":
+			orphans++
+		case "conflict":
+			conflicts++
+		}
+	}
+	if err != nil {
+		// Sync links lines in place; keep the aliases of todos created so far,
+		// or the next run would create them again.
+		if !dryRun && mode != todotxt.ModeExport {
+			_ = todotxt.WriteFile(path, items)
+		}
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(os.Stdout, "Already in sync.")
+	}
+	if conflicts > 0 {
+		fmt.Fprintf(os.Stdout, "%d conflict(s) left unchanged; rerun with -prefer file or -prefer habitica.\n", conflicts)
+	}
+	if orphans > 0 {
+		fmt.Fprintf(os.Stdout, "%d todo(s) are no longer in the file; rerun with -prune to delete them.\n", orphans)
+	}
+	if dryRun {
+		return nil
+	}
+	return todotxt.WriteFile(path, out)
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// runWebhook dispatches the "webhook" subcommands.
+func runWebhook(cfgPath string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing webhook subcommand; expected: list, add, edit, rm, enable, disable")
+	}
+
+	switch args[0] {
+	case "list":
+		return runWebhookList(cfgPath, args[1:])
+	case "add":
+		return runWebhookAdd(cfgPath, args[1:])
+	case "edit":
+		return runWebhookEdit(cfgPath, args[1:])
+	case "rm":
+		return runWebhookRemove(cfgPath, args[1:])
+	case "enable":
+		return runWebhookEnable(cfgPath, "enable", true, args[1:])
+	case "disable":
+		return runWebhookEnable(cfgPath, "disable", false, args[1:])
+	default:
+		return fmt.Errorf("unknown webhook subcommand %q", args[0])
+	}
+}
+
+// webhookEvents lists the event options of each webhook type, in API order.
+var webhookEvents = map[habitica.WebhookType][]string{
+	habitica.WebhookTaskActivity:  {"created", "updated", "deleted", "scored", "checklistScored"},
+	habitica.WebhookUserActivity:  {"petHatched", "mountRaised", "leveledUp"},
+	habitica.WebhookQuestActivity: {"questStarted", "questFinished", "questInvited"},
+}
+
+// parseWebhookType validates the -type flag.
+func parseWebhookType(input string) (habitica.WebhookType, error) {
+	for _, typ := range []habitica.WebhookType{
+		habitica.WebhookTaskActivity,
+		habitica.WebhookGroupChatReceived,
+		habitica.WebhookUserActivity,
+		habitica.WebhookQuestActivity,
+		habitica.WebhookGlobalActivity,
+	} {
+		if strings.EqualFold(input, string(typ)) {
+			return typ, nil
+		}
+	}
+	return "", fmt.Errorf("invalid webhook type %q; expected taskActivity, groupChatReceived, userActivity, questActivity or globalActivity", input)
+}
+
+// webhookOptions builds the options of a webhook type from a comma-separated
+// list of events and, for groupChatReceived webhooks, a group ID.
+func webhookOptions(typ habitica.WebhookType, events, group string) (habitica.WebhookOptions, error) {
+	if typ == habitica.WebhookGroupChatReceived {
+		if strings.TrimSpace(events) != "" {
+			return nil, fmt.Errorf("groupChatReceived webhooks have no events; use -group")
+		}
+		if strings.TrimSpace(group) == "" {
+			return nil, fmt.Errorf("flag -group is required for groupChatReceived webhooks (use \"party\" for your party)")
+		}
+		return &habitica.GroupChatReceivedOptions{GroupID: habitica.UUID(strings.TrimSpace(group))}, nil
+	}
+	if strings.TrimSpace(group) != "" {
+		return nil, fmt.Errorf("flag -group only applies to groupChatReceived webhooks")
+	}
+
+	names, ok := webhookEvents[typ]
+	if !ok {
+		if strings.TrimSpace(events) != "" {
+			return nil, fmt.Errorf("%s webhooks have no events", typ)
+		}
+		return nil, nil
+	}
+
+	on := make(map[string]bool)
+	for _, e := range strings.Split(events, ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		found := false
+		for _, name := range names {
+			if strings.EqualFold(e, name) {
+				on[name] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid %s event %q; expected one of %s", typ, e, strings.Join(names, ", "))
+		}
+	}
+
+	switch typ {
+	case habitica.WebhookTaskActivity:
+		return &habitica.TaskActivityOptions{
+			Created:         on["created"],
+			Updated:         on["updated"],
+			Deleted:         on["deleted"],
+			Scored:          on["scored"],
+			ChecklistScored: on["checklistScored"],
+		}, nil
+	case habitica.WebhookUserActivity:
+		return &habitica.UserActivityOptions{
+			PetHatched:  on["petHatched"],
+			MountRaised: on["mountRaised"],
+			LeveledUp:   on["leveledUp"],
+		}, nil
+	default:
+		return &habitica.QuestActivityOptions{
+			QuestStarted:  on["questStarted"],
+			QuestFinished: on["questFinished"],
+			QuestInvited:  on["questInvited"],
+		}, nil
+	}
+}
+
+// resolveWebhookGroup replaces the group "party" of groupChatReceived options
+// with the ID of the user's party; Habitica only accepts group IDs there.
+func resolveWebhookGroup(ctx context.Context, client *habitica.Client, opts habitica.WebhookOptions) error {
+	chat, ok := opts.(*habitica.GroupChatReceivedOptions)
+	if !ok || chat.GroupID != "party" {
+		return nil
+	}
+	party, err := client.Groups.GetGroup(ctx, "party")
+	if err != nil {
+		return fmt.Errorf("look up your party: %w", err)
+	}
+	chat.GroupID = party.ID
+	return nil
+}
+
+// describeWebhookOptions formats the options for listings, e.g. "scored, checklistScored".
+func describeWebhookOptions(opts habitica.WebhookOptions) string {
+	var events []string
+	add := func(on bool, name string) {
+		if on {
+			events = append(events, name)
+		}
+	}
+	switch o := opts.(type) {
+	case nil:
+		return "all events"
+	case *habitica.TaskActivityOptions:
+		add(o.Created, "created")
+		add(o.Updated, "updated")
+		add(o.Deleted, "deleted")
+		add(o.Scored, "scored")
+		add(o.ChecklistScored, "checklistScored")
+	case *habitica.GroupChatReceivedOptions:
+		return "group " + string(o.GroupID)
+	case *habitica.UserActivityOptions:
+		add(o.PetHatched, "petHatched")
+		add(o.MountRaised, "mountRaised")
+		add(o.LeveledUp, "leveledUp")
+	case *habitica.QuestActivityOptions:
+		add(o.QuestStarted, "questStarted")
+		add(o.QuestFinished, "questFinished")
+		add(o.QuestInvited, "questInvited")
+	case *habitica.RawWebhookOptions:
+		return string(o.Raw)
+	}
+	if len(events) == 0 {
+		return "no events"
+	}
+	return strings.Join(events, ", ")
+}
+
+func printWebhook(h *habitica.Webhook) {
+	state := "on "
+	if !h.Enabled {
+		state = "off"
+	}
+	label := h.Label
+	if label == "" {
+		label = "(no label)"
+	}
+	fmt.Fprintf(os.Stdout, "[%s] %s %s -> %s (ID: %s)\n", state, label, h.Type, h.URL, h.ID)
+	fmt.Fprintf(os.Stdout, "      events: %s\n", describeWebhookOptions(h.Options))
+	if h.Failures > 0 {
+		fmt.Fprintf(os.Stdout, "      failures: %d\n", h.Failures)
+	}
+}
+
+// findWebhook looks up a webhook by ID or (case-insensitive) label.
+func findWebhook(ctx context.Context, client *habitica.Client, idOrLabel string) (*habitica.Webhook, error) {
+	hooks, err := client.Webhooks.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var byLabel []*habitica.Webhook
+	for _, h := range hooks {
+		if string(h.ID) == idOrLabel {
+			return h, nil
+		}
+		if h.Label != "" && strings.EqualFold(h.Label, idOrLabel) {
+			byLabel = append(byLabel, h)
+		}
+	}
+	switch len(byLabel) {
+	case 0:
+		return nil, fmt.Errorf("no webhook with ID or label %q", idOrLabel)
+	case 1:
+		return byLabel[0], nil
+	default:
+		return nil, fmt.Errorf("label %q matches %d webhooks; use the ID", idOrLabel, len(byLabel))
+	}
+}
+
+// runWebhookList lists the registered webhooks.
+//
+// Example usage:
+//   gohabitica webhook list
+func runWebhookList(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("webhook list", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hooks, err := client.Webhooks.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		fmt.Fprintln(os.Stdout, "No webhooks found.")
+		return nil
+	}
+	for _, h := range hooks {
+		printWebhook(h)
+	}
+	return nil
+}
+
+// runWebhookAdd registers a new webhook.
+//
+// Example usage:
+//   gohabitica webhook add -url "https://example.com/habitica?token=s3cret" -label automation -events scored,checklistScored
+//   gohabitica webhook add -url "https://example.com/chat" -type groupChatReceived -group party
+//   gohabitica webhook add -url "https://example.com/hook" -type userActivity -events leveledUp -disabled
+func runWebhookAdd(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("webhook add", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		url      string
+		label    string
+		typ      string
+		events   string
+		group    string
+		disabled bool
+	)
+
+	fs.StringVar(&url, "url", "", "URL Habitica posts the events to (required)")
+	fs.StringVar(&label, "label", "", "Label of the webhook")
+	fs.StringVar(&typ, "type", "taskActivity", "Webhook type: taskActivity, groupChatReceived, userActivity, questActivity or globalActivity")
+	fs.StringVar(&events, "events", "", "Comma-separated events, e.g. scored,checklistScored (default: Habitica's defaults)")
+	fs.StringVar(&group, "group", "", "Group ID for groupChatReceived webhooks (\"party\" for your party)")
+	fs.BoolVar(&disabled, "disabled", false, "Create the webhook disabled")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(url) == "" {
+		return fmt.Errorf("flag -url is required")
+	}
+	webhookType, err := parseWebhookType(typ)
+	if err != nil {
+		return err
+	}
+
+	req := &habitica.WebhookCreateRequest{URL: url, Label: label, Type: webhookType}
+	if strings.TrimSpace(events) != "" || strings.TrimSpace(group) != "" || webhookType == habitica.WebhookGroupChatReceived {
+		if req.Options, err = webhookOptions(webhookType, events, group); err != nil {
+			return err
+		}
+	}
+	if disabled {
+		enabled := false
+		req.Enabled = &enabled
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := resolveWebhookGroup(ctx, client, req.Options); err != nil {
+		return err
+	}
+	hook, err := client.Webhooks.CreateWebhook(ctx, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, "Webhook created:")
+	printWebhook(hook)
+	return nil
+}
+
+// runWebhookEdit changes a webhook. Only flags that are given are changed;
+// -events replaces the selected events.
+//
+// Example usage:
+//   gohabitica webhook edit -id automation -events scored,created
+//   gohabitica webhook edit -id "3f0c..." -url "https://example.com/new" -label "New label"
+func runWebhookEdit(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("webhook edit", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		id     string
+		url    string
+		label  string
+		typ    string
+		events string
+		group  string
+	)
+
+	fs.StringVar(&id, "id", "", "ID or label of the webhook (required)")
+	fs.StringVar(&url, "url", "", "New URL")
+	fs.StringVar(&label, "label", "", "New label")
+	fs.StringVar(&typ, "type", "", "New webhook type; requires -events or -group unless it is globalActivity")
+	fs.StringVar(&events, "events", "", "Comma-separated events replacing the current ones")
+	fs.StringVar(&group, "group", "", "New group ID for groupChatReceived webhooks (\"party\" for your party)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := requireID(id); err != nil {
+		return err
+	}
+
+	set := setFlags(fs)
+	if !set["url"] && !set["label"] && !set["type"] && !set["events"] && !set["group"] {
+		return fmt.Errorf("nothing to change; use -url, -label, -type, -events or -group")
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hook, err := findWebhook(ctx, client, strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+
+	req := &habitica.WebhookUpdateRequest{}
+	if set["url"] {
+		req.URL = &url
+	}
+	if set["label"] {
+		req.Label = &label
+	}
+	webhookType := hook.Type
+	if set["type"] {
+		if webhookType, err = parseWebhookType(typ); err != nil {
+			return err
+		}
+		req.Type = webhookType
+	}
+	if set["events"] || set["group"] || (set["type"] && webhookType != hook.Type) {
+		if req.Options, err = webhookOptions(webhookType, events, group); err != nil {
+			return err
+		}
+		req.Type = webhookType
+	}
+
+	if err := resolveWebhookGroup(ctx, client, req.Options); err != nil {
+		return err
+	}
+	updated, err := client.Webhooks.UpdateWebhook(ctx, hook.ID, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, "Webhook updated:")
+	printWebhook(updated)
+	return nil
+}
+
+// runWebhookRemove deletes a webhook.
+//
+// Example usage:
+//   gohabitica webhook rm -id automation
+func runWebhookRemove(cfgPath string, args []string) error {
+	fs := flag.NewFlagSet("webhook rm", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var id string
+	fs.StringVar(&id, "id", "", "ID or label of the webhook (required)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := requireID(id); err != nil {
+		return err
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hook, err := findWebhook(ctx, client, strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+	if err := client.Webhooks.DeleteWebhook(ctx, hook.ID); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Webhook %s deleted.\n", hook.ID)
+	return nil
+}
+
+// runWebhookEnable enables or disables a webhook.
+//
+// Example usage:
+//   gohabitica webhook enable -id automation
+//   gohabitica webhook disable -id automation
+func runWebhookEnable(cfgPath, name string, enabled bool, args []string) error {
+	fs := flag.NewFlagSet("webhook "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var id string
+	fs.StringVar(&id, "id", "", "ID or label of the webhook (required)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := requireID(id); err != nil {
+		return err
+	}
+
+	client, err := newClient(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hook, err := findWebhook(ctx, client, strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+	updated, err := client.Webhooks.SetWebhookEnabled(ctx, hook.ID, enabled)
+	if err != nil {
+		return err
+	}
+	printWebhook(updated)
+	return nil
+}
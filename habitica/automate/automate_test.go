@@ -0,0 +1,317 @@
+package automate_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/automate"
+	"github.com/danielrichardt/gohabitica/habitica/mock"
+	"github.com/danielrichardt/gohabitica/habitica/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+const rulesYAML = `
+rules:
+  - name: standup notes
+    when:
+      event: taskActivity.scored
+      if:
+        task.text: standup
+        direction: up
+        user.stats.lvl: {min: 10}
+    do:
+      - action: checkItem
+        task: Write report
+        item: Standup notes
+  - name: undo standup
+    when:
+      event: taskActivity.scored
+      if:
+        direction: down
+    do:
+      - action: uncheckItem
+        task: Write report
+        item: Standup notes
+  - when:
+      event: userActivity.leveledUp
+    do:
+      - action: allocate
+        stat: int
+      - action: createTodo
+        text: "Celebrate level {{.finalLvl}}"
+  - name: mounts
+    when:
+      event: userActivity.mountRaised
+      if:
+        mount: {matches: "^Dragon-"}
+    do:
+      - action: score
+        task: Write report
+`
+
+// events reads recorded webhook payloads.
+func events(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, name := range names {
+		b, err := os.ReadFile("../webhook/testdata/" + name + ".json")
+		require.NoError(t, err)
+		buf.Write(b)
+	}
+	return buf.Bytes()
+}
+
+func newAccount() *mock.Account {
+	account := mock.NewAccount([]*habitica.Task{
+		{ID: "2a9c0d34-7b5a-4e2e-b9f1-41f0c7e2d302", Type: habitica.TaskTypeDaily, Text: "Standup"},
+		{ID: "report", Type: habitica.TaskTypeTodo, Text: "Write report", Checklist: []habitica.ChecklistItem{
+			{ID: "item-1", Text: "Outline"},
+			{ID: "item-2", Text: "Standup notes"},
+		}},
+	}, nil)
+	account.SetUser(&habitica.User{ID: "b0413351-405f-416f-8787-947ec1c85199", Stats: habitica.UserStats{Lvl: 12, Points: 1}})
+	return account
+}
+
+func TestReplay(t *testing.T) {
+	rules, err := automate.Parse([]byte(rulesYAML))
+	require.NoError(t, err)
+	require.Equal(t, "rule 3", rules.Rules[2].Name)
+
+	account := newAccount()
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	engine := automate.New(srv.Client, rules, automate.Options{})
+	results, err := automate.Replay(context.Background(), engine, bytes.NewReader(events(t, "task_scored", "leveled_up", "pet_hatched", "mount_raised")))
+	require.NoError(t, err)
+
+	var got []string
+	for _, r := range results {
+		got = append(got, r.String())
+	}
+	require.Equal(t, []string{
+		`rule "standup notes": check "Standup notes" on "Write report"`,
+		`rule "rule 3": allocate a point to int`,
+		`rule "rule 3": create todo "Celebrate level 12"`,
+	}, got)
+
+	report := account.Task("report")
+	require.False(t, report.Checklist[0].Completed)
+	require.True(t, report.Checklist[1].Completed)
+	require.Equal(t, 1, account.User().Stats.Int)
+	require.Equal(t, 0, account.User().Stats.Points)
+	require.Len(t, account.Tasks(), 3)
+	require.Equal(t, "Celebrate level 12", account.Tasks()[2].Text)
+
+	// Checking the item again changes nothing.
+	results, err = automate.Replay(context.Background(), engine, bytes.NewReader(events(t, "task_scored")))
+	require.NoError(t, err)
+	require.Equal(t, `rule "standup notes": check "Standup notes" on "Write report" (unchanged)`, results[0].String())
+
+	// Without attribute points the allocation fails and the todo is not created.
+	results, err = automate.Replay(context.Background(), engine, bytes.NewReader(events(t, "leveled_up")))
+	require.ErrorContains(t, err, `rule "rule 3": allocate a point to int`)
+	require.Len(t, results, 1)
+	require.Len(t, account.Tasks(), 3)
+}
+
+func TestHandle_DryRun(t *testing.T) {
+	rules, err := automate.Parse([]byte(rulesYAML))
+	require.NoError(t, err)
+
+	account := newAccount()
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	engine := automate.New(srv.Client, rules, automate.Options{DryRun: true, Log: newLogger(&logs)})
+
+	ev, err := webhook.Parse(events(t, "leveled_up"))
+	require.NoError(t, err)
+	results, err := engine.Handle(context.Background(), ev)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.True(t, results[0].DryRun)
+	require.Contains(t, logs.String(), `[dry-run] rule "rule 3": create todo "Celebrate level 12"`)
+	require.Len(t, account.Tasks(), 2)
+	require.Equal(t, 1, account.User().Stats.Points)
+
+	// References are still checked.
+	rules.Rules[0].Do[0].Item = "Missing"
+	ev, err = webhook.Parse(events(t, "task_scored"))
+	require.NoError(t, err)
+	_, err = engine.Handle(context.Background(), ev)
+	require.ErrorContains(t, err, `task "Write report" has no checklist item "Missing"`)
+}
+
+func TestRegister(t *testing.T) {
+	rules, err := automate.Parse([]byte(rulesYAML))
+	require.NoError(t, err)
+
+	account := newAccount()
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	h := webhook.NewHandler()
+	automate.New(srv.Client, rules, automate.Options{Log: newLogger(&logs)}).Register(h)
+
+	ev, err := webhook.Parse(events(t, "task_scored"))
+	require.NoError(t, err)
+	require.NoError(t, h.Dispatch(context.Background(), ev))
+	require.True(t, account.Task("report").Checklist[1].Completed)
+
+	// Failed actions are logged, but do not fail the request by default.
+	account.SetUser(&habitica.User{ID: "b0413351-405f-416f-8787-947ec1c85199"})
+	ev, err = webhook.Parse(events(t, "leveled_up"))
+	require.NoError(t, err)
+	require.NoError(t, h.Dispatch(context.Background(), ev))
+	require.Contains(t, logs.String(), `rule "rule 3": allocate a point to int: `)
+
+	h = webhook.NewHandler()
+	automate.New(srv.Client, rules, automate.Options{FailRequests: true}).Register(h)
+	require.Error(t, h.Dispatch(context.Background(), ev))
+}
+
+func TestHandle_Echo(t *testing.T) {
+	rules, err := automate.Parse([]byte(`
+rules:
+  - name: double standup
+    when:
+      event: taskActivity.scored
+      if:
+        task.text: Standup
+    do:
+      - action: score
+        task: Standup
+`))
+	require.NoError(t, err)
+
+	account := newAccount()
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	engine := automate.New(srv.Client, rules, automate.Options{Log: newLogger(&logs)})
+	ev, err := webhook.Parse(events(t, "task_scored"))
+	require.NoError(t, err)
+
+	results, err := engine.Handle(context.Background(), ev)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	// The webhook of the rule's own score is ignored once.
+	results, err = engine.Handle(context.Background(), ev)
+	require.NoError(t, err)
+	require.Empty(t, results)
+	require.Contains(t, logs.String(), `taskActivity.scored of "Standup" ignored: caused by a rule`)
+
+	results, err = engine.Handle(context.Background(), ev)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+}
+
+func TestHandleWatch(t *testing.T) {
+	rules, err := automate.Parse([]byte(rulesYAML))
+	require.NoError(t, err)
+	// Watched changes carry no user stats.
+	delete(rules.Rules[0].When.If, "user.stats.lvl")
+
+	account := newAccount()
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	ctx := context.Background()
+	engine := automate.New(srv.Client, rules, automate.Options{})
+	w := habitica.NewWatcher(srv.Client, habitica.WatchOptions{})
+	_, err = w.Poll(ctx)
+	require.NoError(t, err)
+
+	_, err = srv.Client.Tasks.ScoreTask(ctx, "2a9c0d34-7b5a-4e2e-b9f1-41f0c7e2d302", "up")
+	require.NoError(t, err)
+	changes, err := w.Poll(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, changes)
+
+	var got []string
+	for _, change := range changes {
+		results, err := engine.HandleWatch(ctx, "b0413351-405f-416f-8787-947ec1c85199", change)
+		require.NoError(t, err)
+		for _, r := range results {
+			got = append(got, r.String())
+		}
+	}
+	require.Equal(t, []string{`rule "standup notes": check "Standup notes" on "Write report"`}, got)
+	require.True(t, account.Task("report").Checklist[1].Completed)
+}
+
+func TestConditions(t *testing.T) {
+	ev, err := webhook.Parse(events(t, "task_scored"))
+	require.NoError(t, err)
+
+	for cond, want := range map[string]bool{
+		`task.text: STANDUP`:              true,
+		`task.type: {not: habit}`:         true,
+		`task.text: {contains: "stand"}`:  true,
+		`task.text: {matches: "^Stand"}`:  true,
+		`task.text: {matches: "^stand"}`:  false,
+		`delta: {min: 0.5, max: 1}`:       true,
+		`user.stats.hp: {max: 40}`:        false,
+		`task.streak: 6`:                  true,
+		`task.repeat.s: false`:            true,
+		`user._tmp.leveledUp.newLvl: 12`:  true,
+		`webhookType: taskActivity`:       true,
+		`type: scored`:                    true,
+		`user.stats.class: rogue`:         true,
+		`task.checklist.0.text: anything`: false,
+		`missing.field: {not: x}`:         false,
+		`task.text: {min: 1}`:             false,
+	} {
+		rules, err := automate.Parse([]byte(`
+rules:
+  - when:
+      event: taskActivity.scored
+      if:
+        ` + cond + `
+    do:
+      - action: createTodo
+        text: matched
+`))
+		require.NoError(t, err, cond)
+
+		results, err := automate.New(nil, rules, automate.Options{DryRun: true}).Handle(context.Background(), ev)
+		require.NoError(t, err, cond)
+		require.Equal(t, want, len(results) == 1, cond)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for rules, msg := range map[string]string{
+		`rules: []`: "no rules defined",
+		`rules: [{when: {event: x}, do: [{action: fly}]}]`:                                          `unknown action "fly"`,
+		`rules: [{when: {event: x}, do: [{action: checkItem, task: t}]}]`:                           "checkItem requires item",
+		`rules: [{when: {event: x}, do: [{action: allocate, stat: luck}]}]`:                         `invalid stat "luck"`,
+		`rules: [{when: {event: x}, do: [{action: score, task: t, direction: sideways}]}]`:          `invalid direction "sideways"`,
+		`rules: [{when: {event: x, if: {a: {matches: "("}}}, do: [{action: createTodo, text: t}]}]`: "condition on a",
+		`rules: [{when: {event: x}, do: [{action: createTodo, text: "{{.x"}]}]`:                     "unclosed action",
+		`rules: [{when: {}, do: [{action: createTodo, text: t}]}]`:                                  "when.event is required",
+		`rules: [{when: {event: x}, then: []}]`:                                                     "field then not found",
+	} {
+		_, err := automate.Parse([]byte(rules))
+		require.ErrorContains(t, err, msg, rules)
+	}
+}
+
+func newLogger(w *bytes.Buffer) *log.Logger {
+	return log.New(w, "", 0)
+}
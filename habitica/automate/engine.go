@@ -0,0 +1,406 @@
+package automate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/webhook"
+)
+
+// Options controls an Engine.
+type Options struct {
+	// DryRun logs the actions instead of running them. Tasks and checklist
+	// items are still looked up, so that broken references are reported.
+	DryRun bool
+	// Log receives a line per action; nil discards them.
+	Log *log.Logger
+	// FailRequests answers webhook requests with status 500 when an action
+	// fails. By default failures are only logged, because Habitica disables
+	// webhooks that keep failing.
+	FailRequests bool
+	// EchoTTL is how long the event caused by an action is expected, see
+	// Engine; default one minute. With Watch it should exceed the poll
+	// interval.
+	EchoTTL time.Duration
+}
+
+// Engine evaluates rules for events and runs the actions of matching rules.
+//
+// Actions cause webhooks of their own, e.g. scoring a task sends
+// taskActivity.scored. The engine ignores the first task event of the
+// expected kind for a task it acted on within Options.EchoTTL, so a rule
+// scoring the task that triggered it does not loop. Other events caused by actions,
+// such as userActivity.leveledUp, are handled as usual.
+type Engine struct {
+	client *habitica.Client
+	rules  *Rules
+	opts   Options
+
+	mu     sync.Mutex
+	echoes map[echo]time.Time // expected webhooks and when they expire
+}
+
+// echo identifies the webhook an action causes.
+type echo struct {
+	kind webhook.Kind
+	task habitica.UUID
+}
+
+// Result is the outcome of one action.
+type Result struct {
+	Rule string
+	// Action describes the action, e.g. `check "Outline" on "Write report"`.
+	Action string
+	DryRun bool
+	Err    error
+}
+
+// String formats the result for logs.
+func (r Result) String() string {
+	s := fmt.Sprintf("rule %q: %s", r.Rule, r.Action)
+	if r.DryRun {
+		s = "[dry-run] " + s
+	}
+	if r.Err != nil {
+		s += ": " + r.Err.Error()
+	}
+	return s
+}
+
+// New creates an engine for validated rules.
+func New(client *habitica.Client, rules *Rules, opts Options) *Engine {
+	if opts.EchoTTL <= 0 {
+		opts.EchoTTL = time.Minute
+	}
+	return &Engine{client: client, rules: rules, opts: opts, echoes: make(map[echo]time.Time)}
+}
+
+// Register makes h pass all events to the engine. Failed actions are
+// logged and only fail the webhook request with Options.FailRequests.
+func (e *Engine) Register(h *webhook.Handler) {
+	h.OnAny(func(ctx context.Context, ev webhook.Event) error {
+		_, err := e.Handle(ctx, ev)
+		if err != nil && !e.opts.FailRequests {
+			return nil
+		}
+		return err
+	})
+}
+
+// Handle runs the actions of all rules matching the event. The actions of a
+// rule stop at the first error; the other rules still run. The returned
+// error joins the errors of all actions.
+func (e *Engine) Handle(ctx context.Context, ev webhook.Event) ([]Result, error) {
+	if t := taskOf(ev); t != nil && e.consumeEcho(echo{ev.Kind(), t.ID}) {
+		e.logf("%s of %q ignored: caused by a rule", ev.Kind(), t.Text)
+		return nil, nil
+	}
+	payload, err := payloadOf(ev)
+	if err != nil {
+		e.logf("%s: %v", ev.Kind(), err)
+		return nil, err
+	}
+
+	var (
+		results []Result
+		errs    []error
+	)
+	for _, rule := range e.rules.Rules {
+		if !rule.When.matches(ev.Kind(), payload) {
+			continue
+		}
+		for i := range rule.Do {
+			res := e.run(ctx, rule, &rule.Do[i], payload)
+			results = append(results, res)
+			e.logf("%s", res)
+			if res.Err != nil {
+				errs = append(errs, fmt.Errorf("rule %q: %s: %w", res.Rule, res.Action, res.Err))
+				break
+			}
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// HandleWatch runs the rules for a change detected by a habitica.Watcher of
+// the given user, converted with webhook.FromWatch. Rules see the events
+// without their payload, so only fields of the typed events are available.
+func (e *Engine) HandleWatch(ctx context.Context, user habitica.UUID, change habitica.WatchEvent) ([]Result, error) {
+	var (
+		results []Result
+		errs    []error
+	)
+	for _, ev := range webhook.FromWatch(user, change) {
+		res, err := e.Handle(ctx, ev)
+		results = append(results, res...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// Watch runs the rules for the changes detected by w until ctx is done, for
+// setups that cannot receive webhooks. Failed polls and actions are logged.
+func (e *Engine) Watch(ctx context.Context, w *habitica.Watcher) error {
+	user, err := e.client.User.GetCurrent(ctx)
+	if err != nil {
+		return err
+	}
+	for change := range w.Watch(ctx) {
+		if failed, ok := change.(*habitica.PollFailed); ok {
+			e.logf("poll failed: %v; retrying in %s", failed.Err, failed.Retry)
+			continue
+		}
+		// Handle logs the results and errors.
+		_, _ = e.HandleWatch(ctx, user.ID, change)
+	}
+	return nil
+}
+
+// Replay feeds a stream of recorded webhook payloads (JSON objects, e.g.
+// one per line) to the engine and returns the results of all actions.
+func Replay(ctx context.Context, e *Engine, r io.Reader) ([]Result, error) {
+	dec := json.NewDecoder(r)
+	var (
+		results []Result
+		errs    []error
+	)
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return results, fmt.Errorf("event %d: %w", n, err)
+		}
+		ev, err := webhook.Parse(raw)
+		if err != nil {
+			return results, fmt.Errorf("event %d: %w", n, err)
+		}
+		res, err := e.Handle(ctx, ev)
+		results = append(results, res...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("event %d: %w", n, err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// payloadOf returns the event as decoded JSON, the form conditions and
+// templates see: the payload it was parsed from, so fields the typed events
+// leave out are available too, or else the encoded event.
+func payloadOf(ev webhook.Event) (map[string]any, error) {
+	raw := webhook.Raw(ev)
+	if raw == nil {
+		var err error
+		if raw, err = json.Marshal(ev); err != nil {
+			return nil, err
+		}
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// taskOf returns the task of task events.
+func taskOf(ev webhook.Event) *habitica.Task {
+	switch ev := ev.(type) {
+	case *webhook.TaskScored:
+		return ev.Task
+	case *webhook.TaskCreated:
+		return ev.Task
+	case *webhook.TaskUpdated:
+		return ev.Task
+	case *webhook.TaskDeleted:
+		return ev.Task
+	case *webhook.ChecklistScored:
+		return ev.Task
+	}
+	return nil
+}
+
+// expectEcho records the webhook a successful action will cause.
+func (e *Engine) expectEcho(kind webhook.Kind, task habitica.UUID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	for k, expires := range e.echoes {
+		if now.After(expires) {
+			delete(e.echoes, k)
+		}
+	}
+	e.echoes[echo{kind, task}] = now.Add(e.opts.EchoTTL)
+}
+
+// consumeEcho reports whether the webhook was expected and forgets it.
+func (e *Engine) consumeEcho(k echo) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	expires, ok := e.echoes[k]
+	delete(e.echoes, k)
+	return ok && time.Now().Before(expires)
+}
+
+func (e *Engine) logf(format string, args ...any) {
+	if e.opts.Log != nil {
+		e.opts.Log.Printf(format, args...)
+	}
+}
+
+// run executes one action of a rule.
+func (e *Engine) run(ctx context.Context, rule *Rule, a *Action, payload map[string]any) Result {
+	res := Result{Rule: rule.Name, Action: a.Action, DryRun: e.opts.DryRun}
+
+	var expanded [4]string
+	for i, s := range []string{a.Task, a.Item, a.Text, a.Notes} {
+		out, err := expand(s, payload)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		expanded[i] = out
+	}
+	taskRef, item, text, notes := expanded[0], expanded[1], expanded[2], expanded[3]
+
+	switch a.Action {
+	case ActionScore:
+		direction := a.Direction
+		if direction == "" {
+			direction = "up"
+		}
+		res.Action = fmt.Sprintf("score %q %s", taskRef, direction)
+		task, err := e.findTask(ctx, taskRef)
+		if err != nil || e.opts.DryRun {
+			res.Err = err
+			return res
+		}
+		if _, res.Err = e.client.Tasks.ScoreTask(ctx, task.ID, direction); res.Err == nil {
+			e.expectEcho(webhook.KindTaskScored, task.ID)
+		}
+
+	case ActionCheckItem, ActionUncheckItem:
+		completed := a.Action == ActionCheckItem
+		verb := "check"
+		if !completed {
+			verb = "uncheck"
+		}
+		res.Action = fmt.Sprintf("%s %q on %q", verb, item, taskRef)
+		task, err := e.findTask(ctx, taskRef)
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		found := findItem(task, item)
+		if found == nil {
+			res.Err = fmt.Errorf("task %q has no checklist item %q", task.Text, item)
+			return res
+		}
+		if found.Completed == completed {
+			res.Action += " (unchanged)"
+			return res
+		}
+		if e.opts.DryRun {
+			return res
+		}
+		if res.Err = e.client.Tasks.UpdateChecklistItemCompleted(ctx, task.ID, found.ID, completed); res.Err == nil {
+			e.expectEcho(webhook.KindChecklistScored, task.ID)
+		}
+
+	case ActionAddItem:
+		res.Action = fmt.Sprintf("add %q to %q", item, taskRef)
+		task, err := e.findTask(ctx, taskRef)
+		if err != nil || e.opts.DryRun {
+			res.Err = err
+			return res
+		}
+		if _, res.Err = e.client.Tasks.AddChecklistItem(ctx, task.ID, item); res.Err == nil {
+			e.expectEcho(webhook.KindTaskUpdated, task.ID)
+		}
+
+	case ActionCreateTodo:
+		res.Action = fmt.Sprintf("create todo %q", text)
+		if e.opts.DryRun {
+			return res
+		}
+		var created *habitica.Task
+		created, res.Err = e.client.Tasks.CreateTask(ctx, &habitica.TaskCreateRequest{
+			Text:  text,
+			Notes: notes,
+			Type:  habitica.TaskTypeTodo,
+		})
+		if res.Err == nil {
+			e.expectEcho(webhook.KindTaskCreated, created.ID)
+		}
+
+	case ActionAllocate:
+		res.Action = "allocate a point to " + a.Stat
+		if e.opts.DryRun {
+			return res
+		}
+		_, res.Err = e.client.User.Allocate(ctx, a.Stat)
+	}
+	return res
+}
+
+// expand executes s as a template with the event payload.
+func expand(s string, payload map[string]any) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// findTask finds an active task by ID, alias or case-insensitive text.
+func (e *Engine) findTask(ctx context.Context, ref string) (*habitica.Task, error) {
+	tasks, err := e.client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var byText []*habitica.Task
+	for _, t := range tasks {
+		if string(t.ID) == ref || (t.Alias != "" && t.Alias == ref) {
+			return t, nil
+		}
+		if strings.EqualFold(t.Text, ref) {
+			byText = append(byText, t)
+		}
+	}
+	switch len(byText) {
+	case 0:
+		return nil, fmt.Errorf("no task %q", ref)
+	case 1:
+		return byText[0], nil
+	default:
+		return nil, fmt.Errorf("%d tasks are named %q; use the ID or an alias", len(byText), ref)
+	}
+}
+
+// findItem finds a checklist item by case-insensitive text.
+func findItem(t *habitica.Task, text string) *habitica.ChecklistItem {
+	for i := range t.Checklist {
+		if strings.EqualFold(t.Checklist[i].Text, text) {
+			return &t.Checklist[i]
+		}
+	}
+	return nil
+}
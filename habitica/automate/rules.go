@@ -0,0 +1,322 @@
+// Package automate runs rules on Habitica webhook events.
+//
+// A rules file lists rules that match events by kind and by conditions on
+// the fields of the event payload, and run actions on the account when they
+// match:
+//
+//	rules:
+//	  - name: standup notes
+//	    when:
+//	      event: taskActivity.scored
+//	      if:
+//	        task.text: Standup
+//	        direction: up
+//	    do:
+//	      - action: checkItem
+//	        task: Weekly report
+//	        item: Standup notes
+//	  - name: level up
+//	    when:
+//	      event: userActivity.leveledUp
+//	    do:
+//	      - action: allocate
+//	        stat: int
+//
+// Engine evaluates the rules for each event; Register connects it to a
+// webhook.Handler and Replay feeds it recorded payloads.
+package automate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/danielrichardt/gohabitica/habitica/webhook"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules is the content of a rules file.
+type Rules struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule runs its actions, in order, for every event matching When.
+type Rule struct {
+	Name string   `yaml:"name"`
+	When Trigger  `yaml:"when"`
+	Do   []Action `yaml:"do"`
+}
+
+// Trigger selects events by kind and conditions.
+type Trigger struct {
+	// Event is the kind of event, e.g. taskActivity.scored.
+	Event webhook.Kind `yaml:"event"`
+	// If maps field paths of the payload, such as task.text or
+	// user.stats.lvl, to conditions that must all hold.
+	If map[string]*Condition `yaml:"if"`
+}
+
+// Condition tests a field of an event. A scalar in YAML is short for Equals.
+// A condition on a missing field never holds.
+type Condition struct {
+	// Equals compares case-insensitively with the field formatted as text.
+	Equals *string `yaml:"equals"`
+	// Not is the negation of Equals.
+	Not *string `yaml:"not"`
+	// Contains tests for a case-insensitive substring.
+	Contains string `yaml:"contains"`
+	// Matches is a regular expression.
+	Matches string   `yaml:"matches"`
+	Min     *float64 `yaml:"min"`
+	Max     *float64 `yaml:"max"`
+
+	re *regexp.Regexp
+}
+
+// UnmarshalYAML accepts a scalar as Equals as well as a mapping.
+func (c *Condition) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		v := n.Value
+		*c = Condition{Equals: &v}
+		return nil
+	}
+	type plain Condition
+	return n.Decode((*plain)(c))
+}
+
+// Action kinds.
+const (
+	// ActionScore scores Task in Direction (default up).
+	ActionScore = "score"
+	// ActionCheckItem checks the checklist item Item of Task.
+	ActionCheckItem = "checkItem"
+	// ActionUncheckItem unchecks the checklist item Item of Task.
+	ActionUncheckItem = "uncheckItem"
+	// ActionAddItem appends the checklist item Item to Task.
+	ActionAddItem = "addItem"
+	// ActionCreateTodo creates a todo with Text and Notes.
+	ActionCreateTodo = "createTodo"
+	// ActionAllocate spends an attribute point on Stat (str, con, int, per).
+	ActionAllocate = "allocate"
+)
+
+// Action is a change made when a rule matches. Tasks are referenced by ID,
+// alias or text (case-insensitive) and checklist items by text. Task, Item,
+// Text and Notes are templates executed with the event payload, e.g.
+// "Follow up on {{.task.text}}".
+type Action struct {
+	Action    string `yaml:"action"`
+	Task      string `yaml:"task,omitempty"`
+	Item      string `yaml:"item,omitempty"`
+	Direction string `yaml:"direction,omitempty"`
+	Text      string `yaml:"text,omitempty"`
+	Notes     string `yaml:"notes,omitempty"`
+	Stat      string `yaml:"stat,omitempty"`
+}
+
+// Load reads and validates a rules file.
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Parse decodes and validates rules. Unknown keys are rejected.
+func Parse(data []byte) (*Rules, error) {
+	var r Rules
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&r); err != nil {
+		return nil, fmt.Errorf("decode rules: %w", err)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Validate checks the rules, names unnamed rules "rule N" and compiles the
+// regular expressions.
+func (r *Rules) Validate() error {
+	if len(r.Rules) == 0 {
+		return fmt.Errorf("no rules defined")
+	}
+	for i, rule := range r.Rules {
+		if rule.Name == "" {
+			rule.Name = "rule " + strconv.Itoa(i+1)
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func (rule *Rule) validate() error {
+	if rule.When.Event == "" {
+		return fmt.Errorf("when.event is required")
+	}
+	for field, c := range rule.When.If {
+		if c == nil {
+			return fmt.Errorf("condition on %s is empty", field)
+		}
+		if c.Matches != "" {
+			re, err := regexp.Compile(c.Matches)
+			if err != nil {
+				return fmt.Errorf("condition on %s: %w", field, err)
+			}
+			c.re = re
+		}
+	}
+	if len(rule.Do) == 0 {
+		return fmt.Errorf("no actions defined")
+	}
+	for i := range rule.Do {
+		if err := rule.Do[i].validate(); err != nil {
+			return fmt.Errorf("action %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (a *Action) validate() error {
+	require := func(fields ...string) error {
+		values := map[string]string{"task": a.Task, "item": a.Item, "text": a.Text, "stat": a.Stat}
+		for _, f := range fields {
+			if strings.TrimSpace(values[f]) == "" {
+				return fmt.Errorf("%s requires %s", a.Action, f)
+			}
+		}
+		return nil
+	}
+
+	var err error
+	switch a.Action {
+	case ActionScore:
+		err = require("task")
+		if a.Direction != "" && a.Direction != "up" && a.Direction != "down" {
+			return fmt.Errorf("invalid direction %q; expected up or down", a.Direction)
+		}
+	case ActionCheckItem, ActionUncheckItem, ActionAddItem:
+		err = require("task", "item")
+	case ActionCreateTodo:
+		err = require("text")
+	case ActionAllocate:
+		switch a.Stat {
+		case "str", "con", "int", "per":
+		case "":
+			err = require("stat")
+		default:
+			return fmt.Errorf("invalid stat %q; expected str, con, int or per", a.Stat)
+		}
+	case "":
+		return fmt.Errorf("action is required")
+	default:
+		return fmt.Errorf("unknown action %q", a.Action)
+	}
+	if err != nil {
+		return err
+	}
+	for _, s := range []string{a.Task, a.Item, a.Text, a.Notes} {
+		if _, err := template.New("").Parse(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to an event of the given kind
+// with the given payload.
+func (t *Trigger) matches(kind webhook.Kind, payload map[string]any) bool {
+	if t.Event != kind {
+		return false
+	}
+	fields := make([]string, 0, len(t.If))
+	for f := range t.If {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		v, ok := lookup(payload, f)
+		if !ok || !t.If[f].holds(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Condition) holds(v any) bool {
+	s := format(v)
+	if c.Equals != nil && !strings.EqualFold(s, *c.Equals) {
+		return false
+	}
+	if c.Not != nil && strings.EqualFold(s, *c.Not) {
+		return false
+	}
+	if c.Contains != "" && !strings.Contains(strings.ToLower(s), strings.ToLower(c.Contains)) {
+		return false
+	}
+	if c.re != nil && !c.re.MatchString(s) {
+		return false
+	}
+	if c.Min != nil || c.Max != nil {
+		n, ok := v.(float64)
+		if !ok {
+			return false
+		}
+		if (c.Min != nil && n < *c.Min) || (c.Max != nil && n > *c.Max) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup resolves a dotted path such as task.checklist.0.text in a decoded
+// JSON payload.
+func lookup(payload map[string]any, path string) (any, bool) {
+	var cur any = payload
+	for _, key := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			cur = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// format renders a decoded JSON value as text; 12.0 becomes "12".
+func format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,237 @@
+// Package backup exports all tasks and tags of a Habitica account and
+// imports them into another account.
+//
+// A Backup can be written as JSON, YAML or CSV (see Encode and Decode).
+// Import recreates the tasks with CreateTask, maps tags to the target
+// account by name and skips tasks that already exist there.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// Version is the current format version of a Backup.
+const Version = 1
+
+// Backup is a snapshot of the tasks and tags of an account.
+type Backup struct {
+	Version    int                `json:"version"`
+	ExportedAt habitica.Timestamp `json:"exportedAt"`
+	Tags       []*habitica.Tag    `json:"tags"`
+	Tasks      []*habitica.Task   `json:"tasks"`
+}
+
+// Export reads all tasks of every type, including completed todos, and all
+// tags of the account. Note that Habitica only returns completed todos that
+// have not been archived yet.
+func Export(ctx context.Context, client *habitica.Client) (*Backup, error) {
+	tasks, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	completed, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{Type: "completedTodos"})
+	if err != nil {
+		return nil, fmt.Errorf("list completed todos: %w", err)
+	}
+	tags, err := client.Tags.ListTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	return &Backup{
+		Version:    Version,
+		ExportedAt: habitica.NewTimestamp(time.Now()),
+		Tags:       tags,
+		Tasks:      append(tasks, completed...),
+	}, nil
+}
+
+// ImportOptions controls Import.
+type ImportOptions struct {
+	// Completed also imports completed todos. They are created and then
+	// scored up, which grants experience and gold on the target account.
+	Completed bool
+	// DryRun reports what would be imported without changing anything.
+	DryRun bool
+}
+
+// ImportReport describes the outcome of Import.
+type ImportReport struct {
+	// TagsCreated lists the tags created on the target account.
+	TagsCreated []*habitica.Tag
+	// Created lists the created tasks (the backup tasks on a dry run).
+	Created []*habitica.Task
+	// Skipped lists the tasks that were not imported.
+	Skipped []Skipped
+}
+
+// Skipped is a task that Import did not recreate.
+type Skipped struct {
+	Task   *habitica.Task
+	Reason string
+}
+
+// Import recreates the tags and tasks of b on the account of client.
+//
+// Tags are matched by case-insensitive name and created when missing;
+// challenge tags are not imported. Task tag IDs are remapped to the target
+// tags. A task is skipped as a duplicate if the target account already has a
+// task with the same alias, or with the same type and text. Challenge and
+// group tasks are skipped as well, since they cannot be recreated as such.
+//
+// On error the report lists everything imported so far.
+func Import(ctx context.Context, client *habitica.Client, b *Backup, opts ImportOptions) (*ImportReport, error) {
+	report := &ImportReport{}
+
+	targetTags, err := client.Tags.ListTags(ctx)
+	if err != nil {
+		return report, fmt.Errorf("list tags: %w", err)
+	}
+	existing, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{})
+	if err != nil {
+		return report, fmt.Errorf("list tasks: %w", err)
+	}
+	completed, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{Type: "completedTodos"})
+	if err != nil {
+		return report, fmt.Errorf("list completed todos: %w", err)
+	}
+
+	// Map backup tag IDs to tags of the target account.
+	index := habitica.NewTagIndex(targetTags)
+	tagIDs := make(map[habitica.UUID]habitica.UUID, len(b.Tags))
+	for _, tag := range b.Tags {
+		if tag.Challenge {
+			continue
+		}
+		target := index.Lookup(tag.Name)
+		if target == nil || !strings.EqualFold(target.Name, tag.Name) {
+			target = &habitica.Tag{Name: tag.Name}
+			if !opts.DryRun {
+				if target, err = client.Tags.CreateTag(ctx, tag.Name); err != nil {
+					return report, fmt.Errorf("create tag %q: %w", tag.Name, err)
+				}
+			}
+			targetTags = append(targetTags, target)
+			index = habitica.NewTagIndex(targetTags)
+			report.TagsCreated = append(report.TagsCreated, target)
+		}
+		tagIDs[tag.ID] = target.ID
+	}
+
+	seen := newDuplicates(append(existing, completed...))
+	for _, t := range b.Tasks {
+		switch {
+		case t.Challenge != nil && t.Challenge.ID != "":
+			report.Skipped = append(report.Skipped, Skipped{Task: t, Reason: "challenge task"})
+			continue
+		case t.Group != nil && t.Group.ID != "":
+			report.Skipped = append(report.Skipped, Skipped{Task: t, Reason: "group task"})
+			continue
+		case t.Type == habitica.TaskTypeTodo && t.Completed && !opts.Completed:
+			report.Skipped = append(report.Skipped, Skipped{Task: t, Reason: "completed todo"})
+			continue
+		}
+		if reason := seen.match(t); reason != "" {
+			report.Skipped = append(report.Skipped, Skipped{Task: t, Reason: reason})
+			continue
+		}
+
+		var tags []habitica.UUID
+		for _, id := range t.Tags {
+			if target, ok := tagIDs[id]; ok {
+				tags = append(tags, target)
+			}
+		}
+
+		created := t
+		if !opts.DryRun {
+			if created, err = client.Tasks.CreateTask(ctx, createRequest(t, tags)); err != nil {
+				return report, fmt.Errorf("create %s %q: %w", t.Type, t.Text, err)
+			}
+			if t.Type == habitica.TaskTypeTodo && t.Completed {
+				if _, err := client.Tasks.ScoreTask(ctx, created.ID, "up"); err != nil {
+					return report, fmt.Errorf("complete todo %q: %w", t.Text, err)
+				}
+				created.Completed = true
+			}
+		}
+		seen.add(t)
+		report.Created = append(report.Created, created)
+	}
+
+	return report, nil
+}
+
+// duplicates tracks the tasks present on the target account.
+type duplicates struct {
+	aliases map[string]bool
+	texts   map[string]bool
+}
+
+func newDuplicates(tasks []*habitica.Task) *duplicates {
+	d := &duplicates{aliases: make(map[string]bool), texts: make(map[string]bool)}
+	for _, t := range tasks {
+		d.add(t)
+	}
+	return d
+}
+
+func (d *duplicates) add(t *habitica.Task) {
+	if t.Alias != "" {
+		d.aliases[t.Alias] = true
+	}
+	d.texts[string(t.Type)+"\x00"+t.Text] = true
+}
+
+// match returns why t duplicates a known task, or "" if it does not.
+func (d *duplicates) match(t *habitica.Task) string {
+	if t.Alias != "" && d.aliases[t.Alias] {
+		return fmt.Sprintf("alias %q already exists", t.Alias)
+	}
+	if d.texts[string(t.Type)+"\x00"+t.Text] {
+		return fmt.Sprintf("%s with the same text already exists", t.Type)
+	}
+	return ""
+}
+
+// createRequest builds the request recreating t with the given tag IDs.
+func createRequest(t *habitica.Task, tags []habitica.UUID) *habitica.TaskCreateRequest {
+	req := &habitica.TaskCreateRequest{
+		Text:      t.Text,
+		Notes:     t.Notes,
+		Type:      t.Type,
+		Alias:     t.Alias,
+		Priority:  t.Priority,
+		Tags:      tags,
+		Attribute: t.Attribute,
+		Date:      t.Date,
+	}
+	for _, item := range t.Checklist {
+		req.Checklist = append(req.Checklist, habitica.ChecklistItem{Text: item.Text, Completed: item.Completed})
+	}
+	for _, r := range t.Reminders {
+		req.Reminders = append(req.Reminders, habitica.TaskReminder{StartDate: r.StartDate, Time: r.Time})
+	}
+
+	switch t.Type {
+	case habitica.TaskTypeHabit:
+		up, down := t.Up, t.Down
+		req.Up, req.Down = &up, &down
+		req.Frequency = t.Frequency
+	case habitica.TaskTypeDaily:
+		req.Frequency = t.Frequency
+		req.Repeat = t.Repeat
+		req.EveryX = t.EveryX
+		req.StartDate = t.StartDate
+		req.DaysOfMonth = t.DaysOfMonth
+		req.WeeksOfMonth = t.WeeksOfMonth
+	case habitica.TaskTypeReward:
+		req.Value = t.Value
+	}
+	return req
+}
@@ -0,0 +1,160 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/backup"
+	"github.com/danielrichardt/gohabitica/habitica/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func loadFixture(t *testing.T) *backup.Backup {
+	t.Helper()
+	raw, err := os.ReadFile("../testdata/tasks.json")
+	require.NoError(t, err)
+
+	var resp habitica.APIResponse[[]*habitica.Task]
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	return &backup.Backup{
+		Version:    backup.Version,
+		ExportedAt: "2025-03-01T08:00:00.000Z",
+		Tags:       []*habitica.Tag{{ID: "6b4f4a0e-1f6f-4a53-8e49-0c7b0b1ce0d2", Name: "Health"}},
+		Tasks:      resp.Data,
+	}
+}
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	for _, f := range []backup.Format{backup.FormatJSON, backup.FormatYAML} {
+		t.Run(string(f), func(t *testing.T) {
+			b := loadFixture(t)
+
+			var buf bytes.Buffer
+			require.NoError(t, backup.Encode(&buf, b, f))
+			got, err := backup.Decode(&buf, f)
+			require.NoError(t, err)
+
+			// Compare the JSON forms, since empty lists decode as nil.
+			want, err := json.Marshal(b)
+			require.NoError(t, err)
+			have, err := json.Marshal(got)
+			require.NoError(t, err)
+			require.JSONEq(t, string(want), string(have))
+		})
+	}
+}
+
+func TestCSV_RoundTrip(t *testing.T) {
+	b := loadFixture(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, backup.Encode(&buf, b, backup.FormatCSV))
+	got, err := backup.Decode(&buf, backup.FormatCSV)
+	require.NoError(t, err)
+
+	require.Equal(t, []*habitica.Tag{{ID: "Health", Name: "Health"}}, got.Tags)
+	require.Len(t, got.Tasks, len(b.Tasks))
+	for i, want := range b.Tasks {
+		have := got.Tasks[i]
+		require.Equal(t, want.ID, have.ID)
+		require.Equal(t, want.Type, have.Type)
+		require.Equal(t, want.Alias, have.Alias)
+		require.Equal(t, want.Text, have.Text)
+		require.Equal(t, want.Priority, have.Priority)
+		require.Equal(t, want.Repeat, have.Repeat)
+		require.Len(t, have.Checklist, len(want.Checklist))
+		for j, item := range want.Checklist {
+			require.Equal(t, item.Text, have.Checklist[j].Text)
+			require.Equal(t, item.Completed, have.Checklist[j].Completed)
+		}
+		require.Len(t, have.Tags, len(want.Tags))
+	}
+}
+
+func TestDecodeCSV_Errors(t *testing.T) {
+	_, err := backup.Decode(bytes.NewBufferString("type,notes\ntodo,x\n"), backup.FormatCSV)
+	require.ErrorContains(t, err, `missing column "text"`)
+
+	_, err = backup.Decode(bytes.NewBufferString("type,text,repeat\ndaily,Walk,mon,funday\n"), backup.FormatCSV)
+	require.Error(t, err)
+
+	_, err = backup.Decode(bytes.NewBufferString("type,text,repeat\ndaily,Walk,\"mon,funday\"\n"), backup.FormatCSV)
+	require.ErrorContains(t, err, `line 2: column repeat: invalid weekday "funday"`)
+}
+
+func TestFormatFromPath(t *testing.T) {
+	require.Equal(t, backup.FormatYAML, backup.FormatFromPath("tasks.yml"))
+	require.Equal(t, backup.FormatCSV, backup.FormatFromPath("out/tasks.CSV"))
+	require.Equal(t, backup.FormatJSON, backup.FormatFromPath("tasks"))
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+
+	source := mock.NewAccount([]*habitica.Task{
+		{ID: "s-1", Type: habitica.TaskTypeHabit, Alias: "floss", Text: "Floss", Up: true, Tags: []habitica.UUID{"s-tag-1"}},
+		{ID: "s-2", Type: habitica.TaskTypeTodo, Text: "Buy milk", Checklist: []habitica.ChecklistItem{{ID: "i-1", Text: "Oat", Completed: true}}, Tags: []habitica.UUID{"s-tag-1", "s-tag-2"}},
+		{ID: "s-3", Type: habitica.TaskTypeTodo, Text: "Done already", Completed: true},
+		{ID: "s-4", Type: habitica.TaskTypeDaily, Text: "Challenge daily", Challenge: &habitica.TaskChallenge{ID: "c-1"}},
+		{ID: "s-5", Type: habitica.TaskTypeReward, Text: "Existing reward", Value: 5},
+	}, []*habitica.Tag{{ID: "s-tag-1", Name: "Health"}, {ID: "s-tag-2", Name: "Errands"}})
+	srcSrv, err := mock.NewServer(source)
+	require.NoError(t, err)
+	defer srcSrv.Close()
+
+	b, err := backup.Export(ctx, srcSrv.Client)
+	require.NoError(t, err)
+	require.Len(t, b.Tasks, 5)
+	require.Len(t, b.Tags, 2)
+
+	target := mock.NewAccount([]*habitica.Task{
+		{ID: "t-1", Type: habitica.TaskTypeReward, Text: "Existing reward"},
+	}, []*habitica.Tag{{ID: "t-tag-1", Name: "health"}})
+	dstSrv, err := mock.NewServer(target)
+	require.NoError(t, err)
+	defer dstSrv.Close()
+
+	dry, err := backup.Import(ctx, dstSrv.Client, b, backup.ImportOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, dry.Created, 2)
+	require.Len(t, target.Tasks(), 1)
+	require.Len(t, target.Tags(), 1)
+
+	report, err := backup.Import(ctx, dstSrv.Client, b, backup.ImportOptions{})
+	require.NoError(t, err)
+	require.Len(t, report.TagsCreated, 1)
+	require.Equal(t, "Errands", report.TagsCreated[0].Name)
+	require.Len(t, report.Created, 2)
+
+	reasons := make(map[string]string)
+	for _, s := range report.Skipped {
+		reasons[s.Task.Text] = s.Reason
+	}
+	require.Equal(t, map[string]string{
+		"Done already":    "completed todo",
+		"Challenge daily": "challenge task",
+		"Existing reward": "reward with the same text already exists",
+	}, reasons)
+
+	floss := target.Task("floss")
+	require.NotNil(t, floss)
+	require.Equal(t, []habitica.UUID{"t-tag-1"}, floss.Tags)
+	require.True(t, floss.Up)
+	require.False(t, floss.Down)
+
+	milk := report.Created[1]
+	require.Equal(t, "Buy milk", milk.Text)
+	require.Equal(t, []habitica.UUID{"t-tag-1", report.TagsCreated[0].ID}, milk.Tags)
+	require.Len(t, milk.Checklist, 1)
+	require.True(t, milk.Checklist[0].Completed)
+
+	// A second import finds everything in place.
+	again, err := backup.Import(ctx, dstSrv.Client, b, backup.ImportOptions{})
+	require.NoError(t, err)
+	require.Empty(t, again.Created)
+	require.Empty(t, again.TagsCreated)
+}
@@ -0,0 +1,375 @@
+package backup
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the file format of a backup.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatCSV  Format = "csv"
+)
+
+// ParseFormat parses a format name ("json", "yaml"/"yml" or "csv").
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "csv":
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("unknown format %q; expected json, yaml or csv", s)
+}
+
+// FormatFromPath derives the format from the file extension of path.
+// It returns FormatJSON for unknown extensions.
+func FormatFromPath(path string) Format {
+	if f, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), ".")); err == nil {
+		return f
+	}
+	return FormatJSON
+}
+
+// Encode writes b to w in the given format.
+//
+// JSON and YAML contain the full task objects as returned by the API. CSV
+// has one row per task with the fields needed to recreate it; tags are
+// stored by name and checklist items one per line.
+func Encode(w io.Writer, b *Backup, f Format) error {
+	switch f {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(b)
+	case FormatYAML:
+		return encodeYAML(w, b)
+	case FormatCSV:
+		return encodeCSV(w, b)
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
+
+// Decode reads a backup in the given format from r.
+func Decode(r io.Reader, f Format) (*Backup, error) {
+	var b Backup
+	switch f {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&b); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		if err := decodeYAML(r, &b); err != nil {
+			return nil, err
+		}
+	case FormatCSV:
+		return decodeCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q", f)
+	}
+	if b.Version > Version {
+		return nil, fmt.Errorf("unsupported backup version %d", b.Version)
+	}
+	return &b, nil
+}
+
+// encodeYAML converts the JSON form to YAML, so that field names and order
+// match the JSON output.
+func encodeYAML(w io.Writer, b *Backup) error {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return err
+	}
+	resetStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// resetStyle drops the JSON flow and quoting styles so the encoder uses block style.
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
+
+func decodeYAML(r io.Reader, b *Backup) error {
+	var v any
+	if err := yaml.NewDecoder(r).Decode(&v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("empty backup")
+		}
+		return err
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, b)
+}
+
+var csvColumns = []string{
+	"id", "type", "alias", "text", "notes", "tags", "checklist", "completed",
+	"priority", "attribute", "value", "up", "down", "frequency", "everyX", "repeat",
+	"daysOfMonth", "weeksOfMonth", "startDate", "date", "createdAt",
+}
+
+func encodeCSV(w io.Writer, b *Backup) error {
+	tags := habitica.NewTagIndex(b.Tags)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, t := range b.Tasks {
+		names := make([]string, 0, len(t.Tags))
+		for _, id := range t.Tags {
+			if tag := tags.Lookup(string(id)); tag != nil {
+				names = append(names, tag.Name)
+			}
+		}
+		checklist := make([]string, len(t.Checklist))
+		for i, item := range t.Checklist {
+			mark := "[ ] "
+			if item.Completed {
+				mark = "[x] "
+			}
+			checklist[i] = mark + item.Text
+		}
+
+		row := map[string]string{
+			"id":           string(t.ID),
+			"type":         string(t.Type),
+			"alias":        t.Alias,
+			"text":         t.Text,
+			"notes":        t.Notes,
+			"tags":         strings.Join(names, ";"),
+			"checklist":    strings.Join(checklist, "\n"),
+			"completed":    strconv.FormatBool(t.Completed),
+			"priority":     strconv.FormatFloat(t.Priority, 'f', -1, 64),
+			"attribute":    t.Attribute,
+			"frequency":    string(t.Frequency),
+			"daysOfMonth":  formatInts(t.DaysOfMonth),
+			"weeksOfMonth": formatInts(t.WeeksOfMonth),
+			"startDate":    string(t.StartDate),
+			"date":         string(t.Date),
+			"createdAt":    string(t.CreatedAt),
+		}
+		if t.Type == habitica.TaskTypeReward {
+			row["value"] = strconv.FormatFloat(t.Value, 'f', -1, 64)
+		}
+		if t.Type == habitica.TaskTypeHabit {
+			row["up"] = strconv.FormatBool(t.Up)
+			row["down"] = strconv.FormatBool(t.Down)
+		}
+		if t.Type == habitica.TaskTypeDaily {
+			row["everyX"] = strconv.Itoa(t.EveryX)
+		}
+		if t.Repeat != nil {
+			row["repeat"] = t.Repeat.String()
+		}
+
+		record := make([]string, len(csvColumns))
+		for i, col := range csvColumns {
+			record[i] = row[col]
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// decodeCSV reads the CSV written by encodeCSV. Columns are matched by the
+// header, so they may be reordered or omitted; only "type" and "text" are
+// required. Since CSV stores tags by name, the tags of the resulting backup
+// use their name as ID.
+func decodeCSV(r io.Reader) (*Backup, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty backup")
+	}
+
+	header := make(map[string]int)
+	for i, col := range records[0] {
+		header[strings.TrimSpace(col)] = i
+	}
+	for _, col := range []string{"type", "text"} {
+		if _, ok := header[col]; !ok {
+			return nil, fmt.Errorf("missing column %q", col)
+		}
+	}
+
+	b := &Backup{Version: Version, ExportedAt: habitica.NewTimestamp(time.Now())}
+	tagIDs := make(map[string]habitica.UUID)
+	for n, record := range records[1:] {
+		get := func(col string) string {
+			if i, ok := header[col]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		t, err := parseCSVTask(get)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+2, err)
+		}
+		for i, id := range t.Tags {
+			key := strings.ToLower(string(id))
+			if canonical, ok := tagIDs[key]; ok {
+				t.Tags[i] = canonical
+				continue
+			}
+			tagIDs[key] = id
+			b.Tags = append(b.Tags, &habitica.Tag{ID: id, Name: string(id)})
+		}
+		b.Tasks = append(b.Tasks, t)
+	}
+	return b, nil
+}
+
+func parseCSVTask(get func(col string) string) (*habitica.Task, error) {
+	t := &habitica.Task{
+		ID:        habitica.UUID(get("id")),
+		Type:      habitica.TaskType(get("type")),
+		Alias:     get("alias"),
+		Text:      get("text"),
+		Notes:     get("notes"),
+		Attribute: get("attribute"),
+		Frequency: habitica.TaskFrequency(get("frequency")),
+		StartDate: habitica.Timestamp(get("startDate")),
+		Date:      habitica.Timestamp(get("date")),
+		CreatedAt: habitica.Timestamp(get("createdAt")),
+	}
+	switch t.Type {
+	case habitica.TaskTypeHabit, habitica.TaskTypeDaily, habitica.TaskTypeTodo, habitica.TaskTypeReward:
+	default:
+		return nil, fmt.Errorf("invalid task type %q", t.Type)
+	}
+	if strings.TrimSpace(t.Text) == "" {
+		return nil, errors.New("text is empty")
+	}
+
+	for _, name := range strings.Split(get("tags"), ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			t.Tags = append(t.Tags, habitica.UUID(name))
+		}
+	}
+	for _, line := range strings.Split(get("checklist"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		item := habitica.ChecklistItem{Text: line}
+		switch {
+		case strings.HasPrefix(line, "[x] "), strings.HasPrefix(line, "[X] "):
+			item.Text, item.Completed = line[4:], true
+		case strings.HasPrefix(line, "[ ] "):
+			item.Text = line[4:]
+		}
+		t.Checklist = append(t.Checklist, item)
+	}
+
+	var err error
+	parseBool := func(col string, dst *bool, def bool) {
+		*dst = def
+		if v := get(col); v != "" && err == nil {
+			if *dst, err = strconv.ParseBool(v); err != nil {
+				err = fmt.Errorf("column %s: %w", col, err)
+			}
+		}
+	}
+	parseFloat := func(col string, dst *float64) {
+		if v := get(col); v != "" && err == nil {
+			if *dst, err = strconv.ParseFloat(v, 64); err != nil {
+				err = fmt.Errorf("column %s: %w", col, err)
+			}
+		}
+	}
+	parseBool("completed", &t.Completed, false)
+	parseBool("up", &t.Up, t.Type == habitica.TaskTypeHabit)
+	parseBool("down", &t.Down, t.Type == habitica.TaskTypeHabit)
+	parseFloat("priority", &t.Priority)
+	parseFloat("value", &t.Value)
+	if v := get("everyX"); v != "" && err == nil {
+		if t.EveryX, err = strconv.Atoi(v); err != nil {
+			err = fmt.Errorf("column everyX: %w", err)
+		}
+	}
+	if err == nil {
+		t.Repeat, err = parseRepeat(get("repeat"))
+	}
+	if err == nil {
+		t.DaysOfMonth, err = parseInts("daysOfMonth", get("daysOfMonth"))
+	}
+	if err == nil {
+		t.WeeksOfMonth, err = parseInts("weeksOfMonth", get("weeksOfMonth"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parseRepeat parses the repeat column. An empty column leaves the weekdays
+// unset.
+func parseRepeat(s string) (*habitica.TaskRepeat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	r, err := habitica.ParseWeekdays(strings.Split(s, ",")...)
+	if err != nil {
+		return nil, fmt.Errorf("column repeat: %w", err)
+	}
+	return r, nil
+}
+
+func formatInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+func parseInts(col, s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var out []int
+	for _, part := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col, err)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
@@ -0,0 +1,279 @@
+package habitica
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStatusHeader is set on responses served by a DiskCache. Its value is
+// "hit" for responses served without contacting Habitica and "revalidated"
+// for responses confirmed by a 304 Not Modified.
+const CacheStatusHeader = "X-Gohabitica-Cache"
+
+// DiskCache is an HTTP cache for GET responses stored as files in a directory.
+// It is primarily meant for large, rarely changing payloads such as /content.
+//
+// Entries younger than the TTL are served without contacting Habitica. Older
+// entries are revalidated with If-None-Match/If-Modified-Since, so a 304
+// response avoids downloading the payload again. With a TTL of zero every
+// request is revalidated and only responses carrying an ETag or Last-Modified
+// header are stored.
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewDiskCache creates a cache storing its entries below dir.
+func NewDiskCache(dir string, ttl time.Duration) *DiskCache {
+	return &DiskCache{
+		dir: dir,
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// DefaultCacheDir returns the gohabitica directory below the user's cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gohabitica"), nil
+}
+
+// WithCache enables caching of GET responses in cache. If paths are given,
+// only requests whose path (relative to the base URL) starts with one of
+// them are cached, e.g. WithCache(cache, "/content").
+func WithCache(cache *DiskCache, paths ...string) Option {
+	return func(c *Client) {
+		c.cache = cache
+		c.cachePaths = paths
+	}
+}
+
+// cacheEntry is the on-disk representation of a cached response.
+type cacheEntry struct {
+	URL          string      `json:"url"`
+	StatusCode   int         `json:"status"`
+	Header       http.Header `json:"header"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"lastModified,omitempty"`
+	StoredAt     time.Time   `json:"storedAt"`
+	Body         []byte      `json:"body"`
+}
+
+// response builds an HTTP response from the entry.
+func (e *cacheEntry) response(req *http.Request, status string) *http.Response {
+	h := e.Header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	h.Set(CacheStatusHeader, status)
+	return &http.Response{
+		Status:        http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheKey identifies a request in the cache. Responses are user specific,
+// so the user ID is part of the key.
+func cacheKey(req *http.Request) string {
+	return req.Header.Get("x-api-user") + " " + req.URL.String()
+}
+
+func (dc *DiskCache) file(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dc.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (dc *DiskCache) load(key string) *cacheEntry {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	raw, err := os.ReadFile(dc.file(key))
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		// A corrupt entry is treated like a miss and overwritten later.
+		return nil
+	}
+	return &e
+}
+
+func (dc *DiskCache) store(key string, e *cacheEntry) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dc.dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dc.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), dc.file(key))
+}
+
+// invalidate removes a single entry.
+func (dc *DiskCache) invalidate(key string) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if err := os.Remove(dc.file(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Clear removes all entries from the cache.
+func (dc *DiskCache) Clear() error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(dc.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// middleware serves and stores GET responses matched by match.
+func (dc *DiskCache) middleware(match func(*http.Request) bool) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet || !match(req) {
+				return next.Do(req)
+			}
+
+			key := cacheKey(req)
+			entry := dc.load(key)
+			now := dc.now()
+			if entry != nil && dc.ttl > 0 && now.Sub(entry.StoredAt) < dc.ttl {
+				return entry.response(req, "hit"), nil
+			}
+
+			if entry != nil {
+				req = req.Clone(req.Context())
+				if entry.ETag != "" {
+					req.Header.Set("If-None-Match", entry.ETag)
+				}
+				if entry.LastModified != "" {
+					req.Header.Set("If-Modified-Since", entry.LastModified)
+				}
+			}
+
+			resp, err := next.Do(req)
+			if err != nil {
+				return nil, err
+			}
+
+			if resp.StatusCode == http.StatusNotModified && entry != nil {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				entry.StoredAt = now
+				if etag := resp.Header.Get("ETag"); etag != "" {
+					entry.ETag = etag
+				}
+				// A failing write only costs a download next time.
+				_ = dc.store(key, entry)
+				return entry.response(req, "revalidated"), nil
+			}
+
+			etag := resp.Header.Get("ETag")
+			lastModified := resp.Header.Get("Last-Modified")
+			if resp.StatusCode != http.StatusOK || (etag == "" && lastModified == "" && dc.ttl <= 0) {
+				return resp, nil
+			}
+
+			raw, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(raw))
+
+			_ = dc.store(key, &cacheEntry{
+				URL:          req.URL.String(),
+				StatusCode:   resp.StatusCode,
+				Header:       resp.Header.Clone(),
+				ETag:         etag,
+				LastModified: lastModified,
+				StoredAt:     now,
+				Body:         raw,
+			})
+			return resp, nil
+		})
+	}
+}
+
+// cacheMatcher returns a predicate selecting the requests the client caches.
+func (c *Client) cacheMatcher() func(*http.Request) bool {
+	return func(req *http.Request) bool {
+		if len(c.cachePaths) == 0 {
+			return true
+		}
+		rel := "/" + strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, c.baseURL.Path), "/")
+		for _, p := range c.cachePaths {
+			if strings.HasPrefix(rel, "/"+strings.TrimPrefix(p, "/")) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// InvalidateCache removes the cached response of GET p (relative to the base
+// URL, with optional query) so that the next request downloads it again.
+// It is a no-op if the client has no cache.
+func (c *Client) InvalidateCache(p string, query url.Values) error {
+	if c.cache == nil {
+		return nil
+	}
+	req, err := c.newRequest(context.Background(), http.MethodGet, p, query, nil)
+	if err != nil {
+		return err
+	}
+	return c.cache.invalidate(cacheKey(req))
+}
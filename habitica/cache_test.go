@@ -0,0 +1,137 @@
+package habitica_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// contentHandler serves /content with an ETag and answers conditional requests with 304.
+func contentHandler(downloads, notModified *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `W/"content-v1"`)
+		if r.Header.Get("If-None-Match") == `W/"content-v1"` {
+			atomic.AddInt32(notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(downloads, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"success": true,
+			"data": map[string]any{
+				"eggs": map[string]any{"Wolf": map[string]any{"key": "Wolf", "text": "Wolf"}},
+			},
+		})
+	}
+}
+
+func TestDiskCache_ConditionalRequests(t *testing.T) {
+	var downloads, notModified int32
+	cache := habitica.NewDiskCache(t.TempDir(), 0)
+
+	srv, err := mock.NewServer(contentHandler(&downloads, &notModified), habitica.WithCache(cache, "/content"))
+	require.NoError(t, err)
+	defer srv.Close()
+
+	for i := 0; i < 3; i++ {
+		c, err := srv.Client.Content.GetContent(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, c)
+	}
+
+	require.EqualValues(t, 1, atomic.LoadInt32(&downloads))
+	require.EqualValues(t, 2, atomic.LoadInt32(&notModified))
+}
+
+func TestDiskCache_TTLServesWithoutRequest(t *testing.T) {
+	var downloads, notModified int32
+	cache := habitica.NewDiskCache(t.TempDir(), time.Hour)
+
+	srv, err := mock.NewServer(contentHandler(&downloads, &notModified), habitica.WithCache(cache))
+	require.NoError(t, err)
+	defer srv.Close()
+
+	var meta habitica.ResponseMeta
+	_, err = srv.Client.Content.GetContent(context.Background())
+	require.NoError(t, err)
+	_, err = srv.Client.Content.GetContent(habitica.WithResponseMeta(context.Background(), &meta))
+	require.NoError(t, err)
+
+	require.EqualValues(t, 1, atomic.LoadInt32(&downloads))
+	require.EqualValues(t, 0, atomic.LoadInt32(&notModified))
+	require.Equal(t, "hit", meta.Header.Get(habitica.CacheStatusHeader))
+}
+
+func TestDiskCache_Invalidation(t *testing.T) {
+	var downloads, notModified int32
+	cache := habitica.NewDiskCache(t.TempDir(), time.Hour)
+
+	srv, err := mock.NewServer(contentHandler(&downloads, &notModified), habitica.WithCache(cache))
+	require.NoError(t, err)
+	defer srv.Close()
+
+	ctx := context.Background()
+	_, err = srv.Client.Content.GetContent(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, srv.Client.InvalidateCache("/content", nil))
+	_, err = srv.Client.Content.GetContent(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, atomic.LoadInt32(&downloads))
+
+	require.NoError(t, cache.Clear())
+	_, err = srv.Client.Content.GetContent(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, atomic.LoadInt32(&downloads))
+}
+
+func TestDiskCache_OnlyConfiguredPathsAndGET(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"success": true, "data": map[string]any{}})
+	}
+
+	cache := habitica.NewDiskCache(t.TempDir(), time.Hour)
+	srv, err := mock.NewServer(http.HandlerFunc(handler), habitica.WithCache(cache, "/content"))
+	require.NoError(t, err)
+	defer srv.Close()
+
+	ctx := context.Background()
+	_, err = srv.Client.User.GetCurrent(ctx)
+	require.NoError(t, err)
+	_, err = srv.Client.User.GetCurrent(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, atomic.LoadInt32(&requests))
+}
+
+func TestDiskCache_SharedAcrossClients(t *testing.T) {
+	var downloads, notModified int32
+	dir := t.TempDir()
+
+	httpSrv := httptest.NewServer(contentHandler(&downloads, &notModified))
+	defer httpSrv.Close()
+
+	for i := 0; i < 2; i++ {
+		srv, err := mock.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+			habitica.WithBaseURL(httpSrv.URL), habitica.WithCache(habitica.NewDiskCache(dir, 0)))
+		require.NoError(t, err)
+		_, err = srv.Client.Content.GetContent(context.Background())
+		require.NoError(t, err)
+		srv.Close()
+	}
+
+	require.EqualValues(t, 1, atomic.LoadInt32(&downloads))
+	require.EqualValues(t, 1, atomic.LoadInt32(&notModified))
+}
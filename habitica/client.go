@@ -18,6 +18,12 @@ import (
 const DefaultUserAgent = "gohabitica-client/0.1"
 
 // Client wraps access to the Habitica API.
+//
+// A Client is safe for concurrent use by multiple goroutines. Reads are
+// always executed concurrently. Writes are only serialized when the client
+// is created with WithWriteSerialization; otherwise concurrent writes for the
+// same user may race on the server as described in the Habitica API docs.
+// Options must not be changed after NewClient returns.
 type Client struct {
 	baseURL    *url.URL
 	httpClient *http.Client
@@ -28,16 +34,27 @@ type Client struct {
 	userAgent string
 	clientID  string
 
+	rateLimiter *rateLimiter
+	retrier     *retrier
+	middleware  []Middleware
+	doer        Doer
+
+	notifications *notificationTracker
+	writes        *writeQueue
+	journal       *OfflineJournal
+	cache         *DiskCache
+	cachePaths    []string
+
 	// Services
-	User      *UserService
-	Tasks     *TasksService
-	Groups    *GroupsService
+	User       *UserService
+	Tasks      *TasksService
+	Groups     *GroupsService
 	Challenges *ChallengesService
-	Content   *ContentService
-	Tags      *TagsService
-	Shops     *ShopsService
-	Webhooks  *WebhooksService
-	Admin     *AdminService
+	Content    *ContentService
+	Tags       *TagsService
+	Shops      *ShopsService
+	Webhooks   *WebhooksService
+	Admin      *AdminService
 }
 
 // Option configures the client during construction.
@@ -81,6 +98,15 @@ func WithClientID(id string) Option {
 	}
 }
 
+// WithRateLimiting enables or disables the proactive pause once the
+// X-RateLimit budget reported by Habitica is exhausted. It is enabled by default;
+// the rate limit state is tracked and available via Client.RateLimit either way.
+func WithRateLimiting(enabled bool) Option {
+	return func(c *Client) {
+		c.rateLimiter.enabled = enabled
+	}
+}
+
 // NewClient creates a new Habitica client based on the given configuration.
 func NewClient(cfg *config.Config, opts ...Option) (*Client, error) {
 	if cfg == nil {
@@ -103,16 +129,28 @@ func NewClient(cfg *config.Config, opts ...Option) (*Client, error) {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		userID:    cfg.UserID,
-		apiToken:  cfg.APIToken,
-		userAgent: DefaultUserAgent,
-		clientID:  "gohabitica-client",
+		userID:      cfg.UserID,
+		apiToken:    cfg.APIToken,
+		userAgent:   DefaultUserAgent,
+		clientID:    "gohabitica-client",
+		rateLimiter: newRateLimiter(),
+		retrier:     newRetrier(),
+
+		notifications: &notificationTracker{},
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	var terminal Doer = DoerFunc(c.transport)
+	if c.cache != nil {
+		// The cache sits below user middleware but above the rate limiter,
+		// so cache hits neither consume nor wait for the request budget.
+		terminal = c.cache.middleware(c.cacheMatcher())(terminal)
+	}
+	c.doer = chain(terminal, c.middleware)
+
 	// Initialize services
 	c.User = &UserService{client: c}
 	c.Tasks = &TasksService{client: c}
@@ -127,6 +165,51 @@ func NewClient(cfg *config.Config, opts ...Option) (*Client, error) {
 	return c, nil
 }
 
+// RateLimit returns the rate limit state reported by the most recent response.
+// The zero value is returned as long as Habitica has not reported any limits yet.
+func (c *Client) RateLimit() RateLimit {
+	return c.rateLimiter.snapshot()
+}
+
+// send performs a single attempt of a request and returns the response
+// together with its fully read body.
+func (c *Client) send(ctx context.Context, method, p string, query url.Values, body any) (*http.Response, []byte, error) {
+	req, err := c.newRequest(ctx, method, p, query, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read the full response body so we can include it in error messages if needed.
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, raw, nil
+}
+
+// transport is the innermost Doer of the middleware chain. It applies the
+// rate limiter around the actual HTTP round trip.
+func (c *Client) transport(req *http.Request) (*http.Response, error) {
+	// Pause if the budget of the current rate limit window is used up.
+	if err := c.rateLimiter.wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.rateLimiter.update(resp)
+	return resp, nil
+}
+
 // newRequest builds a new HTTP request relative to the base URL.
 func (c *Client) newRequest(ctx context.Context, method, p string, query url.Values, body any) (*http.Request, error) {
 	if ctx == nil {
@@ -172,46 +255,53 @@ func (c *Client) newRequest(ctx context.Context, method, p string, query url.Val
 
 // doRequest executes an HTTP request and decodes the standardized Habitica response.
 func (c *Client) doRequest(ctx context.Context, method, p string, query url.Values, body any, out any) error {
-	req, err := c.newRequest(ctx, method, p, query, body)
-	if err != nil {
-		return err
+	if ctx == nil {
+		ctx = context.Background()
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
+	if c.writes != nil && isWrite(method) {
+		// Hold the queue across retries so that writes stay in order.
+		if err := c.writes.acquire(ctx); err != nil {
+			return err
+		}
+		defer c.writes.release()
 	}
-	defer resp.Body.Close()
 
-	// Read the full response body so we can include it in error messages if needed.
-	raw, err := io.ReadAll(resp.Body)
+	var (
+		resp *http.Response
+		raw  []byte
+		err  error
+	)
+	for attempt := 1; ; attempt++ {
+		resp, raw, err = c.send(ctx, method, p, query, body)
+		if !c.retrier.retryable(ctx, method, p, attempt, resp, err) {
+			break
+		}
+		d := c.retrier.delay(attempt, resp)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+			// Waiting would exceed the caller's deadline; report the last failure instead.
+			break
+		}
+		if err := c.retrier.sleep(ctx, d); err != nil {
+			return err
+		}
+	}
 	if err != nil {
+		if c.journalable(ctx, method, p, err) {
+			return c.journalRequest(method, p, query, body)
+		}
 		return err
 	}
 
-	// First try to decode into the generic APIResponse schema.
+	// Decode the envelope once; besides data it carries metadata such as notifications.
+	wrapped := &APIResponse[json.RawMessage]{}
+	if err := json.Unmarshal(raw, wrapped); err != nil {
+		wrapped = nil
+	}
+	c.observeResponse(ctx, resp, wrapped)
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var apiErrResp APIResponse[json.RawMessage]
-		if err := json.Unmarshal(raw, &apiErrResp); err != nil {
-			// Fallback to a raw error message
-			return &APIError{
-				StatusCode: resp.StatusCode,
-				Code:       "http_error",
-				Message:    string(bytes.TrimSpace(raw)),
-			}
-		}
-		// For better error visibility, attach the raw response body.
-		msg := apiErrResp.Message
-		if msg == "" {
-			msg = string(bytes.TrimSpace(raw))
-		} else {
-			msg = fmt.Sprintf("%s; body=%s", msg, string(bytes.TrimSpace(raw)))
-		}
-		return &APIError{
-			StatusCode: resp.StatusCode,
-			Code:       apiErrResp.Error,
-			Message:    msg,
-		}
+		return newAPIError(method, p, resp.StatusCode, raw)
 	}
 
 	if out == nil {
@@ -219,10 +309,9 @@ func (c *Client) doRequest(ctx context.Context, method, p string, query url.Valu
 		return nil
 	}
 
-	// Successful response: decode into APIResponse[T] and map Data into out.
+	// Successful response: map Data of the APIResponse into out.
 	// out must be a pointer to the concrete type.
-	wrapped := &APIResponse[json.RawMessage]{}
-	if err := json.Unmarshal(raw, wrapped); err != nil {
+	if wrapped == nil {
 		// Some endpoints might not use the wrapper – fallback to decoding directly into out.
 		if err := json.Unmarshal(raw, out); err != nil {
 			return fmt.Errorf("Antwort konnte nicht dekodiert werden: %w", err)
@@ -231,11 +320,7 @@ func (c *Client) doRequest(ctx context.Context, method, p string, query url.Valu
 	}
 
 	if !wrapped.Success && wrapped.Error != "" {
-		return &APIError{
-			StatusCode: resp.StatusCode,
-			Code:       wrapped.Error,
-			Message:    wrapped.Message,
-		}
+		return newAPIError(method, p, resp.StatusCode, raw)
 	}
 
 	if len(wrapped.Data) == 0 {
@@ -249,4 +334,3 @@ func (c *Client) doRequest(ctx context.Context, method, p string, query url.Valu
 
 	return nil
 }
-
@@ -12,7 +12,7 @@ import (
 )
 
 // newTestClient creates a client wired up against a mocked HTTP server.
-func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) (*Client, *httptest.Server) {
 	t.Helper()
 
 	srv := httptest.NewServer(handler)
@@ -23,7 +23,7 @@ func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.S
 		APIToken: "api-token",
 	}
 
-	c, err := NewClient(cfg)
+	c, err := NewClient(cfg, opts...)
 	require.NoError(t, err)
 
 	return c, srv
@@ -74,4 +74,3 @@ func TestClient_DoRequest_Error(t *testing.T) {
 	require.Equal(t, "NotAuthorized", apiErr.Code)
 	require.True(t, IsUnauthorized(err))
 }
-
@@ -0,0 +1,243 @@
+package habitica
+
+import (
+	"sort"
+	"strings"
+)
+
+// ContentKind identifies a section of the game content.
+type ContentKind string
+
+const (
+	ContentKindGear           ContentKind = "gear"
+	ContentKindEgg            ContentKind = "egg"
+	ContentKindHatchingPotion ContentKind = "hatchingPotion"
+	ContentKindFood           ContentKind = "food"
+	ContentKindQuest          ContentKind = "quest"
+	ContentKindSpell          ContentKind = "spell"
+	ContentKindBackground     ContentKind = "background"
+)
+
+// Currencies used by ContentItem.Currency.
+const (
+	CurrencyGold = "gold"
+	CurrencyGems = "gems"
+	CurrencyMana = "mana"
+)
+
+// ContentItem is a uniform view on a single entry of the game content as
+// returned by Content.Search.
+type ContentItem struct {
+	Kind  ContentKind `json:"kind"`
+	Key   string      `json:"key"`
+	Text  string      `json:"text"`
+	Notes string      `json:"notes,omitempty"`
+	// Class is the character class of gear and spells.
+	Class string `json:"class,omitempty"`
+	// Set is the gear set, background set or quest group.
+	Set string `json:"set,omitempty"`
+	// Type is the gear slot, the quest category or the spell target.
+	Type     string          `json:"type,omitempty"`
+	Value    float64         `json:"value"`
+	Currency string          `json:"currency,omitempty"`
+	Event    *ContentEvent   `json:"event,omitempty"`
+	Drops    []QuestDropItem `json:"drops,omitempty"`
+
+	// Source is the typed entry (*GearItem, *Egg, *Quest, ...).
+	Source any `json:"-"`
+}
+
+// ContentQuery filters game content for Content.Search.
+// Zero-valued fields match everything; all set fields must match.
+type ContentQuery struct {
+	// Kinds restricts the result to the given sections.
+	Kinds []ContentKind
+	// Text matches case-insensitively against key, text and notes.
+	Text string
+	// Class matches the class of gear and spells (warrior, rogue, wizard, healer, ...).
+	Class string
+	// Set matches the gear set, background set or quest group.
+	Set string
+	// Type matches the gear slot, quest category or spell target.
+	Type string
+	// Currency restricts the price to gold, gems or mana.
+	Currency string
+	// MinValue and MaxValue bound the price (inclusive) when set.
+	MinValue *float64
+	MaxValue *float64
+	// Limited restricts the result to limited (event) content.
+	Limited bool
+	// Event matches the season ("winter") or the event key ("winter2024")
+	// of limited content, see ContentEvent.Key.
+	Event string
+	// Drops matches quests dropping items of the given type, e.g. "eggs".
+	Drops string
+}
+
+// Search returns all content entries matching q, sorted by kind and key.
+//
+// Example: all rogue gear under 100 gold:
+//
+//	max := 100.0
+//	items := content.Search(habitica.ContentQuery{
+//		Kinds:    []habitica.ContentKind{habitica.ContentKindGear},
+//		Class:    "rogue",
+//		Currency: habitica.CurrencyGold,
+//		MaxValue: &max,
+//	})
+func (c *Content) Search(q ContentQuery) []ContentItem {
+	var out []ContentItem
+	for _, item := range c.Items() {
+		if q.matches(&item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// Items returns all content entries as ContentItem values, sorted by kind and key.
+func (c *Content) Items() []ContentItem {
+	var items []ContentItem
+
+	for key, g := range c.Equipment.Flat {
+		items = append(items, ContentItem{
+			Kind: ContentKindGear, Key: key, Text: g.Text, Notes: g.Notes,
+			Class: g.Class(), Set: g.Set, Type: g.Type,
+			Value: g.Value, Currency: CurrencyGold, Event: g.Event, Source: g,
+		})
+	}
+	for key, e := range c.Eggs {
+		items = append(items, ContentItem{
+			Kind: ContentKindEgg, Key: key, Text: e.Text, Notes: e.Notes,
+			Value: e.Value, Currency: CurrencyGems, Source: e,
+		})
+	}
+	for key, p := range c.HatchingPotions {
+		items = append(items, ContentItem{
+			Kind: ContentKindHatchingPotion, Key: key, Text: p.Text, Notes: p.Notes,
+			Value: p.Value, Currency: CurrencyGems, Event: p.Event, Source: p,
+		})
+	}
+	for key, f := range c.Food {
+		items = append(items, ContentItem{
+			Kind: ContentKindFood, Key: key, Text: f.Text, Notes: f.Notes,
+			Value: f.Value, Currency: CurrencyGems, Event: f.Event, Source: f,
+		})
+	}
+	for key, qu := range c.Quests {
+		item := ContentItem{
+			Kind: ContentKindQuest, Key: key, Text: qu.Text, Notes: qu.Notes,
+			Set: qu.Group, Type: qu.Category,
+			Value: qu.Value, Currency: CurrencyGems, Event: qu.Event, Drops: qu.Drop.Items, Source: qu,
+		}
+		if qu.GoldValue > 0 {
+			item.Value, item.Currency = qu.GoldValue, CurrencyGold
+		}
+		items = append(items, item)
+	}
+	for class, spells := range c.Spells {
+		for key, s := range spells {
+			item := ContentItem{
+				Kind: ContentKindSpell, Key: key, Text: s.Text, Notes: s.Notes,
+				Class: class, Type: s.Target,
+				Value: s.Mana, Currency: CurrencyMana, Source: s,
+			}
+			if s.Value > 0 {
+				item.Value, item.Currency = s.Value, CurrencyGold
+			}
+			items = append(items, item)
+		}
+	}
+	seen := make(map[string]bool)
+	addBackground := func(key string, b *Background) {
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		currency := b.Currency
+		if currency == "" {
+			currency = CurrencyGems
+		}
+		items = append(items, ContentItem{
+			Kind: ContentKindBackground, Key: key, Text: b.Text, Notes: b.Notes,
+			Set: b.Set, Value: b.Price, Currency: currency, Source: b,
+		})
+	}
+	for key, b := range c.BackgroundsFlat {
+		addBackground(key, b)
+	}
+	for _, set := range c.Backgrounds {
+		for key, b := range set {
+			addBackground(key, b)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+func (q *ContentQuery) matches(item *ContentItem) bool {
+	if len(q.Kinds) > 0 {
+		found := false
+		for _, k := range q.Kinds {
+			if strings.EqualFold(string(k), string(item.Kind)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.Text != "" {
+		needle := strings.ToLower(q.Text)
+		if !strings.Contains(strings.ToLower(item.Key), needle) &&
+			!strings.Contains(strings.ToLower(item.Text), needle) &&
+			!strings.Contains(strings.ToLower(item.Notes), needle) {
+			return false
+		}
+	}
+	if q.Class != "" && !strings.EqualFold(q.Class, item.Class) {
+		return false
+	}
+	if q.Set != "" && !strings.EqualFold(q.Set, item.Set) {
+		return false
+	}
+	if q.Type != "" && !strings.EqualFold(q.Type, item.Type) {
+		return false
+	}
+	if q.Currency != "" && !strings.EqualFold(q.Currency, item.Currency) {
+		return false
+	}
+	if q.MinValue != nil && item.Value < *q.MinValue {
+		return false
+	}
+	if q.MaxValue != nil && item.Value > *q.MaxValue {
+		return false
+	}
+	if q.Limited && item.Event == nil {
+		return false
+	}
+	if q.Event != "" && (item.Event == nil ||
+		!strings.EqualFold(q.Event, item.Event.Season) && !strings.EqualFold(q.Event, item.Event.Key())) {
+		return false
+	}
+	if q.Drops != "" {
+		found := false
+		for _, d := range item.Drops {
+			if strings.EqualFold(d.Type, q.Drops) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package habitica
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func decodeContentFixture(t *testing.T) *Content {
+	t.Helper()
+	var resp APIResponse[Content]
+	require.NoError(t, json.Unmarshal(loadContentFixture(t), &resp))
+	return &resp.Data
+}
+
+func keysOf(items []ContentItem) []string {
+	keys := make([]string, 0, len(items))
+	for _, it := range items {
+		keys = append(keys, it.Key)
+	}
+	return keys
+}
+
+func TestContent_Search_RogueGearUnder100Gold(t *testing.T) {
+	c := decodeContentFixture(t)
+
+	max := 100.0
+	items := c.Search(ContentQuery{
+		Kinds:    []ContentKind{ContentKindGear},
+		Class:    "rogue",
+		Currency: CurrencyGold,
+		MaxValue: &max,
+	})
+	require.Equal(t, []string{"armor_rogue_1", "weapon_rogue_1", "weapon_rogue_2", "weapon_special_winter2024Rogue"}, keysOf(items))
+	require.IsType(t, &GearItem{}, items[0].Source)
+}
+
+func TestContent_Search_QuestsDroppingEggs(t *testing.T) {
+	c := decodeContentFixture(t)
+
+	items := c.Search(ContentQuery{Kinds: []ContentKind{ContentKindQuest}, Drops: "eggs"})
+	require.Equal(t, []string{"wolf"}, keysOf(items))
+}
+
+func TestContent_Search_Filters(t *testing.T) {
+	c := decodeContentFixture(t)
+
+	text := keysOf(c.Search(ContentQuery{Text: "WOLF"}))
+	require.Contains(t, text, "Wolf")
+	require.Contains(t, text, "wolf")
+
+	require.Equal(t, []string{"weapon_special_winter2024Rogue", "Aurora"}, keysOf(c.Search(ContentQuery{Limited: true})))
+	require.Equal(t, []string{"weapon_special_winter2024Rogue", "Aurora"}, keysOf(c.Search(ContentQuery{Event: "Winter"})))
+	require.Equal(t, []string{"weapon_special_winter2024Rogue", "Aurora"}, keysOf(c.Search(ContentQuery{Event: "winter2024"})))
+	require.Empty(t, c.Search(ContentQuery{Event: "winter2023"}))
+	require.Empty(t, c.Search(ContentQuery{Event: "spring"}))
+	require.Equal(t, []string{"atom1"}, keysOf(c.Search(ContentQuery{Set: "questGroupAtom"})))
+	require.Equal(t, []string{"armor_rogue_1"}, keysOf(c.Search(ContentQuery{Type: "armor"})))
+	require.Equal(t, []string{"atom1", "snowball"}, keysOf(c.Search(ContentQuery{
+		Kinds:    []ContentKind{ContentKindSpell, ContentKindQuest},
+		Currency: CurrencyGold,
+	})), "results are sorted by kind, then key")
+
+	min := 50.0
+	require.Equal(t, []string{"head_armoire_lunarCrown", "weapon_rogue_6", "weapon_special_winter2024Rogue"},
+		keysOf(c.Search(ContentQuery{Kinds: []ContentKind{ContentKindGear}, MinValue: &min})))
+
+	spells := c.Search(ContentQuery{Kinds: []ContentKind{ContentKindSpell}, Class: "wizard"})
+	require.Len(t, spells, 1)
+	require.Equal(t, CurrencyMana, spells[0].Currency)
+	require.Equal(t, 10.0, spells[0].Value)
+
+	backgrounds := c.Search(ContentQuery{Kinds: []ContentKind{ContentKindBackground}})
+	require.Equal(t, []string{"beach", "fairy_ring"}, keysOf(backgrounds), "backgrounds must not be listed twice")
+}
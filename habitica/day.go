@@ -0,0 +1,46 @@
+package habitica
+
+import "time"
+
+// Location returns the user's time zone as a fixed offset. Habitica stores
+// TimezoneOffset like JavaScript's Date.getTimezoneOffset: the minutes the
+// local time is behind UTC, so UTC+2 is stored as -120.
+func (p UserPreferences) Location() *time.Location {
+	offset := -int(p.TimezoneOffset * 60)
+	return time.FixedZone("", offset)
+}
+
+// DayWindow is a single Habitica day, i.e. the interval between two crons.
+type DayWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t lies within the window (Start inclusive, End exclusive).
+func (w DayWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// HabiticaDay returns the Habitica day that contains t. A Habitica day does
+// not start at midnight but at the user's custom day start (DayStart, the
+// hour in local time) in the user's time zone.
+func (p UserPreferences) HabiticaDay(t time.Time) DayWindow {
+	loc := p.Location()
+	dayStart := p.DayStart
+	if dayStart < 0 || dayStart > 23 {
+		dayStart = 0
+	}
+
+	local := t.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), dayStart, 0, 0, 0, loc)
+	if local.Before(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return DayWindow{Start: start, End: start.AddDate(0, 0, 1)}
+}
+
+// IsToday reports whether t falls into the same Habitica day as now, i.e.
+// whether it happened within the current cron window.
+func (p UserPreferences) IsToday(t, now time.Time) bool {
+	return p.HabiticaDay(now).Contains(t)
+}
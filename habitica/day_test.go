@@ -0,0 +1,38 @@
+package habitica
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserPreferences_HabiticaDay(t *testing.T) {
+	// UTC+2 with the day starting at 4 o'clock local time.
+	prefs := UserPreferences{DayStart: 4, TimezoneOffset: -120}
+
+	// 01:30 UTC is 03:30 local – still the previous Habitica day.
+	w := prefs.HabiticaDay(time.Date(2024, time.March, 2, 1, 30, 0, 0, time.UTC))
+	require.True(t, w.Start.Equal(time.Date(2024, time.March, 1, 2, 0, 0, 0, time.UTC)), w.Start)
+	require.True(t, w.End.Equal(time.Date(2024, time.March, 2, 2, 0, 0, 0, time.UTC)), w.End)
+
+	// 02:00 UTC is exactly the day start.
+	w = prefs.HabiticaDay(time.Date(2024, time.March, 2, 2, 0, 0, 0, time.UTC))
+	require.True(t, w.Start.Equal(time.Date(2024, time.March, 2, 2, 0, 0, 0, time.UTC)), w.Start)
+	require.Equal(t, 24*time.Hour, w.End.Sub(w.Start))
+}
+
+func TestUserPreferences_IsToday(t *testing.T) {
+	// UTC-5 with the default day start at midnight.
+	prefs := UserPreferences{TimezoneOffset: 300}
+	now := time.Date(2024, time.March, 2, 3, 0, 0, 0, time.UTC) // 22:00 local on March 1st
+
+	require.True(t, prefs.IsToday(time.Date(2024, time.March, 1, 5, 0, 0, 0, time.UTC), now))
+	require.False(t, prefs.IsToday(time.Date(2024, time.March, 1, 4, 59, 0, 0, time.UTC), now))
+	require.False(t, prefs.IsToday(time.Date(2024, time.March, 2, 5, 0, 0, 0, time.UTC), now))
+}
+
+func TestUserPreferences_Location(t *testing.T) {
+	_, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, UserPreferences{TimezoneOffset: -330}.Location()).Zone()
+	require.Equal(t, 5*3600+30*60, offset)
+}
@@ -1,54 +1,163 @@
 package habitica
 
-import "errors"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 // APIResponse describes the generic response format of the Habitica API.
 // success: bool
 // data:    T
 // error:   string (error type)
 // message: string (human readable error message)
+// errors:  []FieldError (validation failures, only on errors)
+// notifications: []UserNotification (pending notifications of the user)
+// userV:   int (version of the user document after the request)
 type APIResponse[T any] struct {
-	Success bool   `json:"success"`
-	Data    T      `json:"data"`
-	Error   string `json:"error"`
+	Success       bool               `json:"success"`
+	Data          T                  `json:"data"`
+	Error         string             `json:"error"`
+	Message       string             `json:"message"`
+	Errors        []FieldError       `json:"errors,omitempty"`
+	Notifications []UserNotification `json:"notifications,omitempty"`
+	UserV         int                `json:"userV,omitempty"`
+}
+
+// FieldError is a single validation failure as reported in the "errors"
+// array of a Habitica error response.
+type FieldError struct {
 	Message string `json:"message"`
+	Param   string `json:"param"`
+	Value   any    `json:"value,omitempty"`
 }
 
 // APIError represents an HTTP or API error returned by Habitica.
 type APIError struct {
 	StatusCode int
-	Code       string
-	Message    string
+	// Code is the Habitica error type, e.g. "NotFound", "BadRequest" or "NotAuthorized".
+	Code    string
+	Message string
+	// Fields holds the validation failures reported by Habitica, if any.
+	Fields []FieldError
+
+	// Method and Path identify the request that failed.
+	Method string
+	Path   string
+	// Body is the raw response body.
+	Body []byte
+}
+
+// newAPIError builds an APIError from an error response.
+func newAPIError(method, p string, status int, raw []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: status,
+		Method:     method,
+		Path:       p,
+		Body:       raw,
+	}
+
+	var resp APIResponse[json.RawMessage]
+	if err := json.Unmarshal(raw, &resp); err != nil || (resp.Error == "" && resp.Message == "") {
+		// Not a Habitica envelope – fall back to the raw body as message.
+		apiErr.Code = "http_error"
+		apiErr.Message = string(bytes.TrimSpace(raw))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(status)
+		}
+		return apiErr
+	}
+
+	apiErr.Code = resp.Error
+	apiErr.Message = resp.Message
+	apiErr.Fields = resp.Errors
+	return apiErr
 }
 
 func (e *APIError) Error() string {
 	if e == nil {
 		return "<nil>"
 	}
-	if e.Message != "" {
-		return e.Message
+
+	var b strings.Builder
+	if e.Method != "" || e.Path != "" {
+		fmt.Fprintf(&b, "%s %s: ", e.Method, e.Path)
+	}
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, "%d ", e.StatusCode)
 	}
-	if e.Code != "" {
-		return e.Code
+	switch {
+	case e.Code != "" && e.Message != "":
+		fmt.Fprintf(&b, "%s: %s", e.Code, e.Message)
+	case e.Message != "":
+		b.WriteString(e.Message)
+	case e.Code != "":
+		b.WriteString(e.Code)
+	default:
+		b.WriteString("habitica API error")
 	}
-	return "habitica API error"
+	for _, f := range e.Fields {
+		if f.Param != "" {
+			fmt.Fprintf(&b, "; %s: %s", f.Param, f.Message)
+		} else {
+			fmt.Fprintf(&b, "; %s", f.Message)
+		}
+	}
+	return b.String()
 }
 
-// IsNotFound reports whether the error represents a 404 response.
-func IsNotFound(err error) bool {
+// asAPIError extracts an *APIError from err.
+func asAPIError(err error) (*APIError, bool) {
 	var apiErr *APIError
-	if errors.As(err, &apiErr) {
-		return apiErr.StatusCode == 404
+	if errors.As(err, &apiErr) && apiErr != nil {
+		return apiErr, true
 	}
-	return false
+	return nil, false
+}
+
+// IsNotFound reports whether the error represents a 404 response.
+func IsNotFound(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusNotFound
 }
 
 // IsUnauthorized reports whether the error represents a 401 response.
 func IsUnauthorized(err error) bool {
-	var apiErr *APIError
-	if errors.As(err, &apiErr) {
-		return apiErr.StatusCode == 401
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// IsRateLimited reports whether the error represents a 429 response.
+func IsRateLimited(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// IsValidation reports whether the error represents rejected input, i.e. a
+// 400 response carrying field validation failures or a Habitica ValidationError.
+func IsValidation(err error) bool {
+	apiErr, ok := asAPIError(err)
+	if !ok {
+		return false
+	}
+	if apiErr.Code == "ValidationError" {
+		return true
 	}
-	return false
+	return apiErr.StatusCode == http.StatusBadRequest && len(apiErr.Fields) > 0
 }
 
+// IsNotAuthorized reports whether Habitica rejected the request with the
+// "NotAuthorized" error code. Unlike IsUnauthorized this also covers requests
+// that were authenticated but not permitted.
+func IsNotAuthorized(err error) bool {
+	return HasCode(err, "NotAuthorized")
+}
+
+// HasCode reports whether the error carries the given Habitica error code.
+func HasCode(err error, code string) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.Code == code
+}
@@ -0,0 +1,85 @@
+package habitica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_DoRequest_ValidationError(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{
+			"success": false,
+			"error": "BadRequest",
+			"message": "Invalid request parameters.",
+			"errors": [
+				{"message": "Task text cannot be empty.", "param": "text"},
+				{"message": "Invalid task type.", "param": "type", "value": "chore"}
+			]
+		}`))
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	err := client.doRequest(context.Background(), http.MethodPost, "/tasks/user", nil, nil, nil)
+	require.Error(t, err)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "BadRequest", apiErr.Code)
+	require.Equal(t, "Invalid request parameters.", apiErr.Message)
+	require.Equal(t, http.MethodPost, apiErr.Method)
+	require.Equal(t, "/tasks/user", apiErr.Path)
+	require.Contains(t, string(apiErr.Body), `"errors"`)
+	require.Len(t, apiErr.Fields, 2)
+	require.Equal(t, "text", apiErr.Fields[0].Param)
+	require.Equal(t, "chore", apiErr.Fields[1].Value)
+
+	require.True(t, IsValidation(err))
+	require.False(t, IsRateLimited(err))
+	require.Equal(t,
+		"POST /tasks/user: 400 BadRequest: Invalid request parameters.; text: Task text cannot be empty.; type: Invalid task type.",
+		err.Error())
+}
+
+func TestClient_DoRequest_NonJSONError(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("<html>bad gateway</html>\n"))
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	err := client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "http_error", apiErr.Code)
+	require.Equal(t, "<html>bad gateway</html>", apiErr.Message)
+	require.Equal(t, "<html>bad gateway</html>\n", string(apiErr.Body))
+}
+
+func TestErrorHelpers(t *testing.T) {
+	wrap := func(e *APIError) error { return fmt.Errorf("wrapped: %w", e) }
+
+	require.True(t, IsNotFound(wrap(&APIError{StatusCode: http.StatusNotFound, Code: "NotFound"})))
+	require.True(t, IsUnauthorized(wrap(&APIError{StatusCode: http.StatusUnauthorized})))
+	require.True(t, IsRateLimited(wrap(&APIError{StatusCode: http.StatusTooManyRequests})))
+	require.True(t, IsNotAuthorized(wrap(&APIError{StatusCode: http.StatusUnauthorized, Code: "NotAuthorized"})))
+	require.True(t, IsValidation(wrap(&APIError{StatusCode: http.StatusBadRequest, Code: "ValidationError"})))
+	require.False(t, IsValidation(wrap(&APIError{StatusCode: http.StatusBadRequest, Code: "BadRequest"})))
+	require.True(t, HasCode(wrap(&APIError{Code: "InternalServerError"}), "InternalServerError"))
+
+	plain := errors.New("boom")
+	require.False(t, IsNotFound(plain))
+	require.False(t, IsRateLimited(plain))
+	require.False(t, HasCode(plain, ""))
+}
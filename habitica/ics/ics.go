@@ -0,0 +1,311 @@
+// Package ics renders Habitica tasks as an iCalendar (RFC 5545) feed.
+//
+// Todos with a due date become VTODO components (or all-day VEVENTs), and
+// dailies become recurring all-day VEVENTs whose RRULE is derived from the
+// frequency, everyX, repeat, daysOfMonth and weeksOfMonth of the daily.
+// Habits, rewards and todos without a due date are skipped.
+package ics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// Options controls Encode.
+type Options struct {
+	// Name is the calendar name shown by calendar apps.
+	Name string
+	// TodosAsEvents renders todos as all-day VEVENTs on their due date
+	// instead of VTODOs, for apps that do not show tasks.
+	TodosAsEvents bool
+	// SkipDailies leaves out the dailies.
+	SkipDailies bool
+	// Tags resolves tag IDs to names for the CATEGORIES property.
+	Tags []*habitica.Tag
+	// Location is the time zone of dates; defaults to time.Local. Use
+	// UserPreferences.Location to match the Habitica day of the user.
+	Location *time.Location
+	// Now is the DTSTAMP of all components; defaults to the current time.
+	Now time.Time
+}
+
+// Export fetches the todos (including completed ones) and dailies of the
+// account and writes them to w.
+func Export(ctx context.Context, client *habitica.Client, w io.Writer, opts Options) error {
+	var tasks []*habitica.Task
+	for _, typ := range []string{"todos", "completedTodos", "dailys"} {
+		if typ == "dailys" && opts.SkipDailies {
+			continue
+		}
+		list, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{Type: typ})
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, list...)
+	}
+	if opts.Tags == nil {
+		tags, err := client.Tags.ListTags(ctx)
+		if err != nil {
+			return err
+		}
+		opts.Tags = tags
+	}
+	return Encode(w, tasks, opts)
+}
+
+// Encode writes the tasks as a VCALENDAR to w.
+func Encode(w io.Writer, tasks []*habitica.Task, opts Options) error {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	tags := habitica.NewTagIndex(opts.Tags)
+
+	e := &encoder{w: w}
+	e.line("BEGIN:VCALENDAR")
+	e.line("VERSION:2.0")
+	e.line("PRODID:-//gohabitica//Habitica tasks//EN")
+	e.line("CALSCALE:GREGORIAN")
+	if opts.Name != "" {
+		e.prop("X-WR-CALNAME", escape(opts.Name))
+	}
+
+	for _, t := range tasks {
+		switch {
+		case t.Type == habitica.TaskTypeTodo && !t.Date.IsZero():
+			encodeTodo(e, t, tags, opts)
+		case t.Type == habitica.TaskTypeDaily && !opts.SkipDailies:
+			rule, err := RRule(t)
+			if err != nil {
+				// Dailies that are never due have no events.
+				continue
+			}
+			encodeDaily(e, t, rule, tags, opts)
+		}
+	}
+
+	e.line("END:VCALENDAR")
+	return e.err
+}
+
+func encodeTodo(e *encoder, t *habitica.Task, tags *habitica.TagIndex, opts Options) {
+	due, err := t.Date.Time()
+	if err != nil {
+		return
+	}
+	due = due.In(opts.Location)
+
+	if opts.TodosAsEvents {
+		e.line("BEGIN:VEVENT")
+		commonProps(e, t, tags, opts)
+		e.prop("DTSTART;VALUE=DATE", formatDate(due))
+		e.prop("DTEND;VALUE=DATE", formatDate(due.AddDate(0, 0, 1)))
+		e.prop("TRANSP", "TRANSPARENT")
+		e.line("END:VEVENT")
+		return
+	}
+
+	e.line("BEGIN:VTODO")
+	commonProps(e, t, tags, opts)
+	e.prop("DUE;VALUE=DATE", formatDate(due))
+	e.prop("PRIORITY", strconv.Itoa(priority(t.Priority)))
+	if t.Completed {
+		e.prop("STATUS", "COMPLETED")
+		if done, err := t.DateCompleted.Time(); err == nil && !done.IsZero() {
+			e.prop("COMPLETED", formatDateTime(done))
+		}
+	} else {
+		e.prop("STATUS", "NEEDS-ACTION")
+	}
+	e.line("END:VTODO")
+}
+
+func encodeDaily(e *encoder, t *habitica.Task, rule string, tags *habitica.TagIndex, opts Options) {
+	start := opts.Now
+	if s, err := t.StartDate.Time(); err == nil && !s.IsZero() {
+		start = s
+	}
+	start = start.In(opts.Location)
+
+	e.line("BEGIN:VEVENT")
+	commonProps(e, t, tags, opts)
+	e.prop("DTSTART;VALUE=DATE", formatDate(start))
+	e.prop("DTEND;VALUE=DATE", formatDate(start.AddDate(0, 0, 1)))
+	e.prop("RRULE", rule)
+	e.prop("TRANSP", "TRANSPARENT")
+	e.line("END:VEVENT")
+}
+
+// commonProps writes the properties shared by all components.
+func commonProps(e *encoder, t *habitica.Task, tags *habitica.TagIndex, opts Options) {
+	e.prop("UID", string(t.ID)+"@habitica.com")
+	e.prop("DTSTAMP", formatDateTime(opts.Now))
+	if updated, err := t.UpdatedAt.Time(); err == nil && !updated.IsZero() {
+		e.prop("LAST-MODIFIED", formatDateTime(updated))
+	}
+	e.prop("SUMMARY", escape(t.Text))
+	if desc := description(t); desc != "" {
+		e.prop("DESCRIPTION", escape(desc))
+	}
+	var names []string
+	for _, id := range t.Tags {
+		if name := tags.Name(id); name != "" {
+			names = append(names, escape(name))
+		}
+	}
+	if len(names) > 0 {
+		e.prop("CATEGORIES", strings.Join(names, ","))
+	}
+}
+
+// description combines the notes and the checklist of a task.
+func description(t *habitica.Task) string {
+	parts := []string{}
+	if t.Notes != "" {
+		parts = append(parts, t.Notes)
+	}
+	if len(t.Checklist) > 0 {
+		items := make([]string, len(t.Checklist))
+		for i, item := range t.Checklist {
+			mark := "[ ]"
+			if item.Completed {
+				mark = "[x]"
+			}
+			items[i] = mark + " " + item.Text
+		}
+		parts = append(parts, strings.Join(items, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// priority maps a Habitica difficulty to an iCalendar priority (1 highest, 9 lowest).
+func priority(p float64) int {
+	switch {
+	case p >= 2:
+		return 1
+	case p >= 1.5:
+		return 5
+	}
+	return 9
+}
+
+var icalDays = map[time.Weekday]string{
+	time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE", time.Thursday: "TH",
+	time.Friday: "FR", time.Saturday: "SA", time.Sunday: "SU",
+}
+
+// RRule returns the recurrence rule of a daily, e.g.
+// "FREQ=WEEKLY;INTERVAL=1;BYDAY=MO,WE,FR". It returns an error for tasks
+// that are not dailies and for dailies that are never due.
+func RRule(t *habitica.Task) (string, error) {
+	if t.Type != habitica.TaskTypeDaily {
+		return "", fmt.Errorf("task %s is a %s, not a daily", t.ID, t.Type)
+	}
+
+	interval := t.EveryX
+	if interval < 1 {
+		interval = 1
+	}
+	var days []string
+	for _, d := range t.Repeat.Days() {
+		days = append(days, icalDays[d])
+	}
+
+	freq := t.Frequency
+	if freq == "" {
+		freq = habitica.FrequencyWeekly
+	}
+	rule := []string{"FREQ=" + strings.ToUpper(string(freq)), "INTERVAL=" + strconv.Itoa(interval)}
+
+	switch freq {
+	case habitica.FrequencyDaily, habitica.FrequencyYearly:
+	case habitica.FrequencyWeekly:
+		if len(days) == 0 {
+			return "", fmt.Errorf("daily %s is not due on any weekday", t.ID)
+		}
+		rule = append(rule, "BYDAY="+strings.Join(days, ","))
+	case habitica.FrequencyMonthly:
+		switch {
+		case len(t.DaysOfMonth) > 0:
+			monthDays := append([]int(nil), t.DaysOfMonth...)
+			sort.Ints(monthDays)
+			rule = append(rule, "BYMONTHDAY="+joinInts(monthDays))
+		case len(t.WeeksOfMonth) > 0:
+			if len(days) == 0 {
+				return "", fmt.Errorf("daily %s is not due on any weekday", t.ID)
+			}
+			weeks := append([]int(nil), t.WeeksOfMonth...)
+			sort.Ints(weeks)
+			var byDay []string
+			for _, w := range weeks {
+				for _, d := range days {
+					// Habitica counts weeks from 0.
+					byDay = append(byDay, strconv.Itoa(w+1)+d)
+				}
+			}
+			rule = append(rule, "BYDAY="+strings.Join(byDay, ","))
+		}
+	default:
+		return "", fmt.Errorf("daily %s has unknown frequency %q", t.ID, freq)
+	}
+	return strings.Join(rule, ";"), nil
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes a TEXT value.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// encoder writes content lines, folded at 75 octets and terminated by CRLF.
+type encoder struct {
+	w   io.Writer
+	err error
+}
+
+func (e *encoder) prop(name, value string) {
+	e.line(name + ":" + value)
+}
+
+func (e *encoder) line(s string) {
+	if e.err != nil {
+		return
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		n := len(string(r))
+		if width+n > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	b.WriteString("\r\n")
+	_, e.err = io.WriteString(e.w, b.String())
+}
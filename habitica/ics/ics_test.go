@@ -0,0 +1,134 @@
+package ics_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/ics"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTasks(t *testing.T) []*habitica.Task {
+	t.Helper()
+	raw, err := os.ReadFile("../testdata/tasks.json")
+	require.NoError(t, err)
+
+	var resp habitica.APIResponse[[]*habitica.Task]
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	return resp.Data
+}
+
+func TestEncode(t *testing.T) {
+	tasks := loadTasks(t)
+	tasks[2].Notes = "Forms; receipts, etc."
+	tasks[2].Tags = []habitica.UUID{"tag-1"}
+
+	var buf bytes.Buffer
+	err := ics.Encode(&buf, tasks, ics.Options{
+		Name:     "Habitica",
+		Tags:     []*habitica.Tag{{ID: "tag-1", Name: "Admin"}},
+		Location: time.FixedZone("CEST", 2*60*60),
+		Now:      time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//gohabitica//Habitica tasks//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Habitica",
+		"BEGIN:VEVENT",
+		"UID:2a9c0d34-7b5a-4e2e-b9f1-41f0c7e2d302@habitica.com",
+		"DTSTAMP:20240302T120000Z",
+		"LAST-MODIFIED:20240301T230004Z",
+		"SUMMARY:Morning workout",
+		`DESCRIPTION:20 minutes\n\n[x] Stretch`,
+		"DTSTART;VALUE=DATE:20240115",
+		"DTEND;VALUE=DATE:20240116",
+		"RRULE:FREQ=WEEKLY;INTERVAL=1;BYDAY=MO,WE,FR",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:3b8e1f45-6c7d-4a8b-9c0d-1e2f3a4b5c03@habitica.com",
+		"DTSTAMP:20240302T120000Z",
+		"LAST-MODIFIED:20240301T100000Z",
+		"SUMMARY:File taxes",
+		`DESCRIPTION:Forms\; receipts\, etc.`,
+		"CATEGORIES:Admin",
+		"DUE;VALUE=DATE:20240501",
+		"PRIORITY:1",
+		"STATUS:NEEDS-ACTION",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	require.Equal(t, want, buf.String())
+}
+
+func TestEncode_TodosAsEvents(t *testing.T) {
+	tasks := loadTasks(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, ics.Encode(&buf, tasks, ics.Options{TodosAsEvents: true, SkipDailies: true, Location: time.UTC}))
+	out := buf.String()
+	require.NotContains(t, out, "VTODO")
+	require.NotContains(t, out, "Morning workout")
+	require.Contains(t, out, "SUMMARY:File taxes\r\nDTSTART;VALUE=DATE:20240430\r\nDTEND;VALUE=DATE:20240501\r\n")
+}
+
+func TestEncode_Folding(t *testing.T) {
+	todo := &habitica.Task{
+		ID:   "t",
+		Type: habitica.TaskTypeTodo,
+		Text: strings.Repeat("Ünïcödé ", 20),
+		Date: "2024-05-01T00:00:00.000Z",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, ics.Encode(&buf, []*habitica.Task{todo}, ics.Options{Location: time.UTC}))
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		require.LessOrEqual(t, len(line), 75, line)
+	}
+	require.Contains(t, strings.ReplaceAll(buf.String(), "\r\n ", ""), "SUMMARY:"+strings.Repeat("Ünïcödé ", 20))
+}
+
+func TestRRule(t *testing.T) {
+	daily := func(mod func(*habitica.Task)) *habitica.Task {
+		task := &habitica.Task{ID: "d", Type: habitica.TaskTypeDaily, Frequency: habitica.FrequencyWeekly, EveryX: 1}
+		mod(task)
+		return task
+	}
+
+	for name, tc := range map[string]struct {
+		task *habitica.Task
+		want string
+	}{
+		"every third day": {daily(func(t *habitica.Task) { t.Frequency, t.EveryX = habitica.FrequencyDaily, 3 }), "FREQ=DAILY;INTERVAL=3"},
+		"all weekdays":    {daily(func(*habitica.Task) {}), "FREQ=WEEKLY;INTERVAL=1;BYDAY=MO,TU,WE,TH,FR,SA,SU"},
+		"biweekly":        {daily(func(t *habitica.Task) { t.EveryX, t.Repeat = 2, habitica.RepeatOn(time.Tuesday) }), "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU"},
+		"days of month": {daily(func(t *habitica.Task) {
+			t.Frequency, t.DaysOfMonth = habitica.FrequencyMonthly, []int{15, 1}
+		}), "FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=1,15"},
+		"first monday": {daily(func(t *habitica.Task) {
+			t.Frequency, t.WeeksOfMonth, t.Repeat = habitica.FrequencyMonthly, []int{0}, habitica.RepeatOn(time.Monday)
+		}), "FREQ=MONTHLY;INTERVAL=1;BYDAY=1MO"},
+		"yearly": {daily(func(t *habitica.Task) { t.Frequency = habitica.FrequencyYearly }), "FREQ=YEARLY;INTERVAL=1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := ics.RRule(tc.task)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := ics.RRule(daily(func(t *habitica.Task) { t.Repeat = habitica.RepeatOn() }))
+	require.ErrorContains(t, err, "not due on any weekday")
+	_, err = ics.RRule(&habitica.Task{ID: "x", Type: habitica.TaskTypeTodo})
+	require.ErrorContains(t, err, "not a daily")
+}
@@ -0,0 +1,105 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// Apply executes the plan in order. It stops at the first failing change;
+// the error names the change, and all earlier changes have been applied.
+func Apply(ctx context.Context, client *habitica.Client, plan *Plan) error {
+	tagIDs := make(map[string]habitica.UUID, len(plan.tagIDs))
+	for name, id := range plan.tagIDs {
+		tagIDs[name] = id
+	}
+	resolve := func(names []string) []habitica.UUID {
+		ids := make([]habitica.UUID, 0, len(names))
+		for _, name := range names {
+			if id, ok := tagIDs[strings.ToLower(strings.TrimSpace(name))]; ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+
+	for _, c := range plan.Changes {
+		var err error
+		switch {
+		case c.Kind == KindTag && c.Action == ActionCreate:
+			var tag *habitica.Tag
+			if tag, err = client.Tags.CreateTag(ctx, c.Key); err == nil {
+				tagIDs[strings.ToLower(c.Key)] = tag.ID
+			}
+		case c.Kind == KindTag && c.Action == ActionDelete:
+			err = client.Tags.DeleteTag(ctx, c.ID)
+		case c.Action == ActionCreate:
+			_, err = client.Tasks.CreateTask(ctx, createRequest(c.Kind, c.spec, resolve(c.spec.Tags)))
+		case c.Action == ActionUpdate:
+			_, err = client.Tasks.UpdateTask(ctx, c.ID, updateRequest(c, resolve(c.spec.Tags)))
+		case c.Action == ActionDelete:
+			err = client.Tasks.DeleteTask(ctx, c.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s %s: %w", c.Action, c.Kind, c.Key, err)
+		}
+	}
+	return nil
+}
+
+// createRequest builds the request creating the task described by spec.
+func createRequest(kind Kind, s *TaskSpec, tagIDs []habitica.UUID) *habitica.TaskCreateRequest {
+	req := &habitica.TaskCreateRequest{
+		Text:      s.Text,
+		Notes:     s.Notes,
+		Type:      kind.taskType(),
+		Alias:     s.Alias,
+		Tags:      tagIDs,
+		Attribute: s.Attribute,
+		Up:        s.Up,
+		Down:      s.Down,
+		Frequency: habitica.TaskFrequency(s.Frequency),
+		EveryX:    s.Every,
+
+		DaysOfMonth:  s.DaysOfMonth,
+		WeeksOfMonth: s.WeeksOfMonth,
+	}
+	if req.Attribute == "" {
+		req.Attribute = "str"
+	}
+	if s.Difficulty != "" {
+		req.Priority, _ = habitica.ParseDifficulty(s.Difficulty)
+	}
+	for _, text := range s.Checklist {
+		req.Checklist = append(req.Checklist, habitica.ChecklistItem{Text: text})
+	}
+	req.Repeat, _ = s.repeat()
+	if s.StartDate != "" {
+		d, _ := habitica.ParseDate(s.StartDate, time.Local)
+		req.StartDate = habitica.NewTimestamp(d)
+	}
+	if s.Due != "" {
+		d, _ := habitica.ParseDate(s.Due, time.Local)
+		req.Date = habitica.NewTimestamp(d)
+	}
+	if s.Value != nil {
+		req.Value = *s.Value
+	}
+	return req
+}
+
+// updateRequest builds a request changing only the differing fields of c.
+func updateRequest(c Change, tagIDs []habitica.UUID) *habitica.TaskUpdateRequest {
+	req := &habitica.TaskUpdateRequest{}
+	for _, fc := range c.Fields {
+		for i := range fields {
+			if fields[i].name == fc.Field {
+				fields[i].set(req, c.spec, c.live, tagIDs)
+			}
+		}
+	}
+	return req
+}
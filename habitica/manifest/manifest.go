@@ -0,0 +1,317 @@
+// Package manifest manages Habitica tasks as code. A manifest is a YAML
+// file listing tags, habits, dailies, todos and rewards; Diff compares it
+// with the live account and Apply executes the resulting plan.
+//
+// Tasks are matched by their alias, so every task in a manifest needs one.
+// Tasks without an alias are never touched, even with pruning enabled.
+//
+// Example manifest:
+//
+//	tags: [health, work]
+//	habits:
+//	  - alias: drink-water
+//	    text: Drink water
+//	    down: false
+//	    tags: [health]
+//	dailies:
+//	  - alias: workout
+//	    text: Workout
+//	    difficulty: hard
+//	    days: [mon, wed, fri]
+//	    checklist: [Stretch, Run]
+//	todos:
+//	  - alias: taxes
+//	    text: File taxes
+//	    due: 2025-04-30
+//	rewards:
+//	  - alias: episode
+//	    text: Episode of a series
+//	    value: 25
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the desired state of an account.
+type Manifest struct {
+	Tags    []string   `yaml:"tags,omitempty"`
+	Habits  []TaskSpec `yaml:"habits,omitempty"`
+	Dailies []TaskSpec `yaml:"dailies,omitempty"`
+	Todos   []TaskSpec `yaml:"todos,omitempty"`
+	Rewards []TaskSpec `yaml:"rewards,omitempty"`
+}
+
+// TaskSpec describes a single task. Fields left empty are not managed: they
+// get Habitica's defaults on creation and are never changed by an update.
+// Lists are managed as soon as they are present, so "tags: []" removes all tags.
+type TaskSpec struct {
+	Alias string `yaml:"alias"`
+	Text  string `yaml:"text"`
+	Notes string `yaml:"notes,omitempty"`
+	// Difficulty is trivial, easy, medium, hard or a numeric priority.
+	Difficulty string `yaml:"difficulty,omitempty"`
+	// Attribute is str, int, con or per.
+	Attribute string   `yaml:"attribute,omitempty"`
+	Tags      []string `yaml:"tags,omitempty"`
+	Checklist []string `yaml:"checklist,omitempty"`
+
+	// Habits.
+	Up   *bool `yaml:"up,omitempty"`
+	Down *bool `yaml:"down,omitempty"`
+
+	// Dailies; Frequency also sets the counter reset of habits.
+	Frequency    string   `yaml:"frequency,omitempty"`
+	Every        int      `yaml:"every,omitempty"`
+	Days         []string `yaml:"days,omitempty"`
+	DaysOfMonth  []int    `yaml:"daysOfMonth,omitempty"`
+	WeeksOfMonth []int    `yaml:"weeksOfMonth,omitempty"`
+	StartDate    string   `yaml:"startDate,omitempty"` // YYYY-MM-DD
+
+	// Todos.
+	Due string `yaml:"due,omitempty"` // YYYY-MM-DD
+
+	// Rewards: price in gold.
+	Value *float64 `yaml:"value,omitempty"`
+}
+
+// Kind identifies what a change applies to.
+type Kind string
+
+const (
+	KindTag    Kind = "tag"
+	KindHabit  Kind = "habit"
+	KindDaily  Kind = "daily"
+	KindTodo   Kind = "todo"
+	KindReward Kind = "reward"
+)
+
+// taskKinds lists the task kinds in plan order.
+var taskKinds = []Kind{KindHabit, KindDaily, KindTodo, KindReward}
+
+// taskType maps a kind to the Habitica task type.
+func (k Kind) taskType() habitica.TaskType {
+	return habitica.TaskType(k)
+}
+
+// specs returns the task specs of the given kind.
+func (m *Manifest) specs(k Kind) []TaskSpec {
+	switch k {
+	case KindHabit:
+		return m.Habits
+	case KindDaily:
+		return m.Dailies
+	case KindTodo:
+		return m.Todos
+	case KindReward:
+		return m.Rewards
+	}
+	return nil
+}
+
+// Load reads and validates the manifest at path.
+func Load(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Parse reads and validates a manifest. Unknown keys are rejected to catch typos.
+func Parse(r io.Reader) (*Manifest, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Validate checks the manifest for missing or malformed fields and duplicate aliases.
+func (m *Manifest) Validate() error {
+	var errs []error
+
+	seenTags := make(map[string]bool)
+	for _, name := range m.Tags {
+		key := strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case key == "":
+			errs = append(errs, errors.New("tags: empty tag name"))
+		case seenTags[key]:
+			errs = append(errs, fmt.Errorf("tags: duplicate tag %q", name))
+		}
+		seenTags[key] = true
+	}
+
+	aliases := make(map[string]Kind)
+	for _, kind := range taskKinds {
+		for i := range m.specs(kind) {
+			spec := &m.specs(kind)[i]
+			where := fmt.Sprintf("%s #%d", kind, i+1)
+			if spec.Alias != "" {
+				where = fmt.Sprintf("%s %q", kind, spec.Alias)
+			}
+
+			switch {
+			case spec.Alias == "":
+				errs = append(errs, fmt.Errorf("%s: alias is required", where))
+			case !aliasPattern.MatchString(spec.Alias):
+				errs = append(errs, fmt.Errorf("%s: alias may only contain letters, digits, '-' and '_'", where))
+			default:
+				if other, ok := aliases[spec.Alias]; ok {
+					errs = append(errs, fmt.Errorf("%s: alias already used by a %s", where, other))
+				}
+				aliases[spec.Alias] = kind
+			}
+			if strings.TrimSpace(spec.Text) == "" {
+				errs = append(errs, fmt.Errorf("%s: text is required", where))
+			}
+			if err := spec.validate(kind); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", where, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *TaskSpec) validate(kind Kind) error {
+	if s.Difficulty != "" {
+		if _, err := habitica.ParseDifficulty(s.Difficulty); err != nil {
+			return err
+		}
+	}
+	switch s.Attribute {
+	case "", "str", "int", "con", "per":
+	default:
+		return fmt.Errorf("invalid attribute %q; expected str, int, con or per", s.Attribute)
+	}
+
+	notFor := func(field string, set bool, kinds ...Kind) error {
+		if !set {
+			return nil
+		}
+		for _, k := range kinds {
+			if k == kind {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s is not supported for a %s", field, kind)
+	}
+	if err := errors.Join(
+		notFor("up", s.Up != nil, KindHabit),
+		notFor("down", s.Down != nil, KindHabit),
+		notFor("frequency", s.Frequency != "", KindHabit, KindDaily),
+		notFor("every", s.Every != 0, KindDaily),
+		notFor("days", s.Days != nil, KindDaily),
+		notFor("daysOfMonth", s.DaysOfMonth != nil, KindDaily),
+		notFor("weeksOfMonth", s.WeeksOfMonth != nil, KindDaily),
+		notFor("startDate", s.StartDate != "", KindDaily),
+		notFor("checklist", s.Checklist != nil, KindDaily, KindTodo),
+		notFor("due", s.Due != "", KindTodo),
+		notFor("value", s.Value != nil, KindReward),
+	); err != nil {
+		return err
+	}
+
+	if s.Frequency != "" {
+		allowed := "daily, weekly, monthly or yearly"
+		valid := map[string]bool{"daily": true, "weekly": true, "monthly": true, "yearly": true}
+		if kind == KindHabit {
+			allowed = "daily, weekly or monthly"
+			delete(valid, "yearly")
+		}
+		if !valid[s.Frequency] {
+			return fmt.Errorf("invalid frequency %q; expected %s", s.Frequency, allowed)
+		}
+	}
+	if s.Every < 0 {
+		return errors.New("every must not be negative")
+	}
+	if _, err := s.repeat(); err != nil {
+		return err
+	}
+	for _, d := range s.DaysOfMonth {
+		if d < 1 || d > 31 {
+			return fmt.Errorf("invalid day of month %d", d)
+		}
+	}
+	for _, w := range s.WeeksOfMonth {
+		if w < 0 || w > 4 {
+			return fmt.Errorf("invalid week of month %d; expected 0-4", w)
+		}
+	}
+	for field, v := range map[string]string{"startDate": s.StartDate, "due": s.Due} {
+		if v == "" {
+			continue
+		}
+		if _, err := habitica.ParseDate(v, time.Local); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	if s.Value != nil && *s.Value < 0 {
+		return errors.New("value must not be negative")
+	}
+	return nil
+}
+
+// difficultyName is the inverse of habitica.ParseDifficulty.
+func difficultyName(p float64) string {
+	switch p {
+	case 0.1:
+		return "trivial"
+	case 1:
+		return "easy"
+	case 1.5:
+		return "medium"
+	case 2:
+		return "hard"
+	}
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// repeat returns the weekdays of a daily, or nil if the manifest leaves them unset.
+func (s *TaskSpec) repeat() (*habitica.TaskRepeat, error) {
+	if s.Days == nil {
+		return nil, nil
+	}
+	return habitica.ParseWeekdays(s.Days...)
+}
+
+// formatDate renders a Habitica timestamp as a local YYYY-MM-DD date.
+func formatDate(ts habitica.Timestamp) string {
+	t, err := ts.Time()
+	if err != nil || t.IsZero() {
+		return ""
+	}
+	return t.In(time.Local).Format("2006-01-02")
+}
@@ -0,0 +1,136 @@
+package manifest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/manifest"
+	"github.com/danielrichardt/gohabitica/habitica/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `
+tags: [Health]
+habits:
+  - alias: drink-water
+    text: Drink water
+    down: false
+    tags: [health]
+dailies:
+  - alias: workout
+    text: Morning workout
+    difficulty: hard
+    days: [mon, wed, fri]
+    checklist: [Stretch, Run]
+todos:
+  - alias: taxes
+    text: File taxes
+    tags: [Admin]
+`
+
+func liveState() *manifest.State {
+	return &manifest.State{
+		Tags: []*habitica.Tag{
+			{ID: "tag-health", Name: "health"},
+			{ID: "tag-old", Name: "Old"},
+			{ID: "tag-challenge", Name: "Challenge", Challenge: true},
+		},
+		Tasks: []*habitica.Task{
+			{ID: "task-1", Alias: "drink-water", Type: habitica.TaskTypeHabit, Text: "Drink water", Priority: 1, Up: true, Down: true, Tags: []habitica.UUID{"tag-health"}},
+			{ID: "task-2", Alias: "workout", Type: habitica.TaskTypeDaily, Text: "Workout", Priority: 1, Repeat: habitica.RepeatOn(1, 2, 3, 4, 5),
+				Checklist: []habitica.ChecklistItem{{ID: "item-1", Text: "Run", Completed: true}}},
+			{ID: "task-3", Alias: "stale", Type: habitica.TaskTypeTodo, Text: "Stale todo", Tags: []habitica.UUID{"tag-old"}},
+			{ID: "task-4", Type: habitica.TaskTypeTodo, Text: "Unmanaged todo"},
+		},
+	}
+}
+
+func TestParse_Validation(t *testing.T) {
+	_, err := manifest.Parse(strings.NewReader(`
+habits:
+  - text: No alias
+  - alias: dup
+    text: Habit
+    due: 2025-01-01
+todos:
+  - alias: dup
+    text: Todo
+    difficulty: impossible
+`))
+	require.Error(t, err)
+	require.ErrorContains(t, err, "habit #1: alias is required")
+	require.ErrorContains(t, err, `habit "dup": due is not supported for a habit`)
+	require.ErrorContains(t, err, `todo "dup": alias already used by a habit`)
+	require.ErrorContains(t, err, `invalid difficulty "impossible"`)
+
+	_, err = manifest.Parse(strings.NewReader("todos:\n  - alias: a\n    txt: typo\n"))
+	require.ErrorContains(t, err, "field txt not found")
+
+	// Day names are parsed like the -days flag of the CLI.
+	m, err := manifest.Parse(strings.NewReader("dailies:\n  - alias: a\n    text: Work\n    days: [weekdays]\n"))
+	require.NoError(t, err)
+	plan := manifest.Diff(m, &manifest.State{}, manifest.Options{})
+	require.Contains(t, plan.String(), `+ create daily a "Work"`)
+}
+
+func TestDiff_Plan(t *testing.T) {
+	m, err := manifest.Parse(strings.NewReader(testManifest))
+	require.NoError(t, err)
+
+	plan := manifest.Diff(m, liveState(), manifest.Options{})
+	require.Equal(t, `+ create tag "Admin"
+~ update habit drink-water
+    down: true => false
+~ update daily workout
+    text: "Workout" => "Morning workout"
+    difficulty: easy => hard
+    checklist: ["Run"] => ["Stretch", "Run"]
+    days: mon,tue,wed,thu,fri => mon,wed,fri
++ create todo taxes "File taxes"
+
+Plan: 2 to create, 2 to update, 0 to delete.
+`, plan.String())
+
+	pruned := manifest.Diff(m, liveState(), manifest.Options{Prune: true})
+	create, update, del := pruned.Counts()
+	require.Equal(t, []int{2, 2, 2}, []int{create, update, del})
+	require.True(t, strings.HasSuffix(pruned.String(), `- delete todo stale "Stale todo"
+- delete tag "Old"
+
+Plan: 2 to create, 2 to update, 2 to delete.
+`), pruned.String())
+}
+
+func TestApply_Converges(t *testing.T) {
+	account := mock.NewAccount(liveState().Tasks, liveState().Tags)
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+	ctx := context.Background()
+
+	m, err := manifest.Parse(strings.NewReader(testManifest))
+	require.NoError(t, err)
+
+	live, err := manifest.Fetch(ctx, srv.Client)
+	require.NoError(t, err)
+	require.NoError(t, manifest.Apply(ctx, srv.Client, manifest.Diff(m, live, manifest.Options{Prune: true})))
+
+	live, err = manifest.Fetch(ctx, srv.Client)
+	require.NoError(t, err)
+	again := manifest.Diff(m, live, manifest.Options{Prune: true})
+	require.True(t, again.Empty(), again.String())
+
+	workout := account.Task("workout")
+	require.Len(t, workout.Checklist, 2)
+	require.Equal(t, habitica.ChecklistItem{ID: "item-1", Text: "Run", Completed: true}, workout.Checklist[1])
+	require.Nil(t, account.Task("stale"))
+	require.NotNil(t, account.Task("task-4"))
+
+	taxes := account.Task("taxes")
+	require.Len(t, taxes.Tags, 1)
+	names := habitica.NewTagIndex(account.Tags())
+	require.Equal(t, "Admin", names.Name(taxes.Tags[0]))
+	require.Nil(t, names.Lookup("Old"))
+}
@@ -0,0 +1,558 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// Action is what a change does.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is a single step of a plan.
+type Change struct {
+	Action Action
+	Kind   Kind
+	// Key is the alias of a task or the name of a tag.
+	Key string
+	// ID is the live task or tag for updates and deletes.
+	ID habitica.UUID
+	// Text is the task title (desired for creates, live for deletes).
+	Text string
+	// Fields lists the differing fields of an update in a fixed order.
+	Fields []FieldChange
+
+	spec *TaskSpec
+	live *habitica.Task
+}
+
+// FieldChange is a differing field of an update.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Plan is the ordered list of changes turning the live account into the
+// manifest. Its order is deterministic: tag creations first, then tasks by
+// kind (habits, dailies, todos, rewards) and alias, then pruned tasks and tags.
+type Plan struct {
+	Changes []Change
+
+	// tagIDs maps lower-case names of live tags to their IDs.
+	tagIDs map[string]habitica.UUID
+}
+
+// Options controls Diff.
+type Options struct {
+	// Prune deletes aliased tasks missing from the manifest and tags that
+	// are neither listed nor used by any remaining task. Tasks without an
+	// alias and challenge or group tasks are never deleted.
+	Prune bool
+}
+
+// State is the live account as seen by Diff.
+type State struct {
+	Tasks []*habitica.Task
+	Tags  []*habitica.Tag
+}
+
+// Fetch loads the tasks and tags of the account. Completed todos are
+// included, since their aliases are still taken.
+func Fetch(ctx context.Context, client *habitica.Client) (*State, error) {
+	tasks, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{})
+	if err != nil {
+		return nil, err
+	}
+	completed, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{Type: "completedTodos"})
+	if err != nil {
+		return nil, err
+	}
+	tasks = append(tasks, completed...)
+	tags, err := client.Tags.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &State{Tasks: tasks, Tags: tags}, nil
+}
+
+// Diff computes the plan that makes live match m.
+func Diff(m *Manifest, live *State, opts Options) *Plan {
+	plan := &Plan{tagIDs: make(map[string]habitica.UUID)}
+	tagIndex := habitica.NewTagIndex(live.Tags)
+	for _, tag := range live.Tags {
+		key := strings.ToLower(tag.Name)
+		if _, ok := plan.tagIDs[key]; !ok {
+			plan.tagIDs[key] = tag.ID
+		}
+	}
+
+	// Tags listed in the manifest or used by a task.
+	desiredTags := make(map[string]bool)
+	var tagNames []string
+	addTag := func(name string) {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" || desiredTags[key] {
+			return
+		}
+		desiredTags[key] = true
+		tagNames = append(tagNames, strings.TrimSpace(name))
+	}
+	for _, name := range m.Tags {
+		addTag(name)
+	}
+	for _, kind := range taskKinds {
+		for _, spec := range m.specs(kind) {
+			for _, name := range spec.Tags {
+				addTag(name)
+			}
+		}
+	}
+	for _, name := range tagNames {
+		if _, ok := plan.tagIDs[strings.ToLower(name)]; !ok {
+			plan.Changes = append(plan.Changes, Change{Action: ActionCreate, Kind: KindTag, Key: name})
+		}
+	}
+
+	byAlias := make(map[string]*habitica.Task)
+	for _, t := range live.Tasks {
+		if t.Alias != "" {
+			byAlias[t.Alias] = t
+		}
+	}
+
+	// Tag references of tasks that survive the plan, for tag pruning.
+	usedTags := make(map[string]bool)
+	matched := make(map[habitica.UUID]bool)
+
+	for _, kind := range taskKinds {
+		specs := append([]TaskSpec(nil), m.specs(kind)...)
+		sort.Slice(specs, func(i, j int) bool { return specs[i].Alias < specs[j].Alias })
+
+		for i := range specs {
+			spec := &specs[i]
+			for _, name := range spec.Tags {
+				usedTags[strings.ToLower(strings.TrimSpace(name))] = true
+			}
+
+			t := byAlias[spec.Alias]
+			if t != nil {
+				matched[t.ID] = true
+			}
+			switch {
+			case t == nil:
+				plan.Changes = append(plan.Changes, Change{Action: ActionCreate, Kind: kind, Key: spec.Alias, Text: spec.Text, spec: spec})
+			case t.Type != kind.taskType():
+				// The type of a task cannot be changed, so it is replaced.
+				plan.Changes = append(plan.Changes,
+					Change{Action: ActionDelete, Kind: Kind(t.Type), Key: t.Alias, ID: t.ID, Text: t.Text, live: t},
+					Change{Action: ActionCreate, Kind: kind, Key: spec.Alias, Text: spec.Text, spec: spec},
+				)
+			default:
+				if spec.Tags == nil {
+					for _, id := range t.Tags {
+						usedTags[strings.ToLower(tagIndex.Name(id))] = true
+					}
+				}
+				if fields := diffFields(kind, spec, t, tagIndex); len(fields) > 0 {
+					plan.Changes = append(plan.Changes, Change{Action: ActionUpdate, Kind: kind, Key: spec.Alias, ID: t.ID, Text: t.Text, Fields: fields, spec: spec, live: t})
+				}
+			}
+		}
+	}
+
+	var pruned []Change
+	for _, t := range live.Tasks {
+		if matched[t.ID] {
+			continue
+		}
+		managed := t.Alias != "" && (t.Challenge == nil || t.Challenge.ID == "") && (t.Group == nil || t.Group.ID == "")
+		if opts.Prune && managed {
+			pruned = append(pruned, Change{Action: ActionDelete, Kind: Kind(t.Type), Key: t.Alias, ID: t.ID, Text: t.Text, live: t})
+			continue
+		}
+		for _, id := range t.Tags {
+			usedTags[strings.ToLower(tagIndex.Name(id))] = true
+		}
+	}
+	sort.SliceStable(pruned, func(i, j int) bool {
+		if pruned[i].Kind != pruned[j].Kind {
+			return kindOrder(pruned[i].Kind) < kindOrder(pruned[j].Kind)
+		}
+		return pruned[i].Key < pruned[j].Key
+	})
+	plan.Changes = append(plan.Changes, pruned...)
+
+	if opts.Prune {
+		var tags []Change
+		for _, tag := range live.Tags {
+			key := strings.ToLower(tag.Name)
+			if tag.Challenge || desiredTags[key] || usedTags[key] {
+				continue
+			}
+			tags = append(tags, Change{Action: ActionDelete, Kind: KindTag, Key: tag.Name, ID: tag.ID})
+		}
+		sort.SliceStable(tags, func(i, j int) bool { return strings.ToLower(tags[i].Key) < strings.ToLower(tags[j].Key) })
+		plan.Changes = append(plan.Changes, tags...)
+	}
+
+	return plan
+}
+
+func kindOrder(k Kind) int {
+	for i, kind := range taskKinds {
+		if kind == k {
+			return i
+		}
+	}
+	return len(taskKinds)
+}
+
+// Empty reports whether the plan has no changes.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Counts returns the number of creations, updates and deletions.
+func (p *Plan) Counts() (create, update, del int) {
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate:
+			create++
+		case ActionUpdate:
+			update++
+		case ActionDelete:
+			del++
+		}
+	}
+	return create, update, del
+}
+
+// Write renders the plan in a human-readable, deterministic format.
+func (p *Plan) Write(w io.Writer) error {
+	var b strings.Builder
+	if p.Empty() {
+		b.WriteString("No changes. The account matches the manifest.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	for _, c := range p.Changes {
+		switch {
+		case c.Kind == KindTag && c.Action == ActionCreate:
+			fmt.Fprintf(&b, "+ create tag %q\n", c.Key)
+		case c.Kind == KindTag:
+			fmt.Fprintf(&b, "- delete tag %q\n", c.Key)
+		case c.Action == ActionCreate:
+			fmt.Fprintf(&b, "+ create %s %s %q\n", c.Kind, c.Key, c.Text)
+		case c.Action == ActionDelete:
+			fmt.Fprintf(&b, "- delete %s %s %q\n", c.Kind, c.Key, c.Text)
+		case c.Action == ActionUpdate:
+			fmt.Fprintf(&b, "~ update %s %s\n", c.Kind, c.Key)
+			for _, f := range c.Fields {
+				fmt.Fprintf(&b, "    %s: %s => %s\n", f.Field, f.Old, f.New)
+			}
+		}
+	}
+	create, update, del := p.Counts()
+	fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to delete.\n", create, update, del)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String returns the rendered plan.
+func (p *Plan) String() string {
+	var b strings.Builder
+	_ = p.Write(&b)
+	return b.String()
+}
+
+// field describes a managed task field.
+type field struct {
+	name  string
+	kinds []Kind // nil means all task kinds
+	// want returns the desired value and whether the spec manages the field.
+	want func(s *TaskSpec) (string, bool)
+	have func(t *habitica.Task, tags *habitica.TagIndex) string
+	// fold compares case-insensitively.
+	fold bool
+	// set copies the desired value into an update request.
+	set func(req *habitica.TaskUpdateRequest, s *TaskSpec, live *habitica.Task, tagIDs []habitica.UUID)
+}
+
+func (f *field) appliesTo(k Kind) bool {
+	if f.kinds == nil {
+		return true
+	}
+	for _, kind := range f.kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+func quoted(s string) string { return strconv.Quote(s) }
+
+func quotedList(items []string) string {
+	q := make([]string, len(items))
+	for i, s := range items {
+		q[i] = strconv.Quote(s)
+	}
+	return "[" + strings.Join(q, ", ") + "]"
+}
+
+func intList(items []int) string {
+	s := make([]string, len(items))
+	for i, v := range items {
+		s[i] = strconv.Itoa(v)
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+func sortedTagNames(names []string) string {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			out = append(out, n)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i]) < strings.ToLower(out[j]) })
+	return quotedList(out)
+}
+
+// fields lists all managed task fields in plan order.
+var fields = []field{
+	{
+		name: "text",
+		want: func(s *TaskSpec) (string, bool) { return quoted(s.Text), true },
+		have: func(t *habitica.Task, _ *habitica.TagIndex) string { return quoted(t.Text) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			req.Text = &s.Text
+		},
+	},
+	{
+		name: "notes",
+		want: func(s *TaskSpec) (string, bool) { return quoted(s.Notes), s.Notes != "" },
+		have: func(t *habitica.Task, _ *habitica.TagIndex) string { return quoted(t.Notes) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			req.Notes = &s.Notes
+		},
+	},
+	{
+		name:  "difficulty",
+		kinds: []Kind{KindHabit, KindDaily, KindTodo},
+		want: func(s *TaskSpec) (string, bool) {
+			p, _ := habitica.ParseDifficulty(s.Difficulty)
+			return difficultyName(p), s.Difficulty != ""
+		},
+		have: func(t *habitica.Task, _ *habitica.TagIndex) string { return difficultyName(t.Priority) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			p, _ := habitica.ParseDifficulty(s.Difficulty)
+			req.Priority = &p
+		},
+	},
+	{
+		name: "attribute",
+		want: func(s *TaskSpec) (string, bool) { return s.Attribute, s.Attribute != "" },
+		have: func(t *habitica.Task, _ *habitica.TagIndex) string { return t.Attribute },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			req.Attribute = &s.Attribute
+		},
+	},
+	{
+		name: "tags",
+		fold: true,
+		want: func(s *TaskSpec) (string, bool) { return sortedTagNames(s.Tags), s.Tags != nil },
+		have: func(t *habitica.Task, tags *habitica.TagIndex) string {
+			names := make([]string, len(t.Tags))
+			for i, id := range t.Tags {
+				names[i] = tags.Name(id)
+			}
+			return sortedTagNames(names)
+		},
+		set: func(req *habitica.TaskUpdateRequest, _ *TaskSpec, _ *habitica.Task, tagIDs []habitica.UUID) {
+			ids := append([]habitica.UUID{}, tagIDs...)
+			req.Tags = &ids
+		},
+	},
+	{
+		name:  "checklist",
+		kinds: []Kind{KindDaily, KindTodo},
+		want:  func(s *TaskSpec) (string, bool) { return quotedList(s.Checklist), s.Checklist != nil },
+		have: func(t *habitica.Task, _ *habitica.TagIndex) string {
+			texts := make([]string, len(t.Checklist))
+			for i, item := range t.Checklist {
+				texts[i] = item.Text
+			}
+			return quotedList(texts)
+		},
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, live *habitica.Task, _ []habitica.UUID) {
+			checklist := mergeChecklist(s.Checklist, live.Checklist)
+			req.Checklist = &checklist
+		},
+	},
+	{
+		name:  "up",
+		kinds: []Kind{KindHabit},
+		want: func(s *TaskSpec) (string, bool) {
+			return strconv.FormatBool(s.Up == nil || *s.Up), s.Up != nil
+		},
+		have: func(t *habitica.Task, _ *habitica.TagIndex) string { return strconv.FormatBool(t.Up) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			req.Up = s.Up
+		},
+	},
+	{
+		name:  "down",
+		kinds: []Kind{KindHabit},
+		want: func(s *TaskSpec) (string, bool) {
+			return strconv.FormatBool(s.Down == nil || *s.Down), s.Down != nil
+		},
+		have: func(t *habitica.Task, _ *habitica.TagIndex) string { return strconv.FormatBool(t.Down) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			req.Down = s.Down
+		},
+	},
+	{
+		name:  "frequency",
+		kinds: []Kind{KindHabit, KindDaily},
+		want:  func(s *TaskSpec) (string, bool) { return s.Frequency, s.Frequency != "" },
+		have:  func(t *habitica.Task, _ *habitica.TagIndex) string { return string(t.Frequency) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			f := habitica.TaskFrequency(s.Frequency)
+			req.Frequency = &f
+		},
+	},
+	{
+		name:  "every",
+		kinds: []Kind{KindDaily},
+		want:  func(s *TaskSpec) (string, bool) { return strconv.Itoa(s.Every), s.Every != 0 },
+		have:  func(t *habitica.Task, _ *habitica.TagIndex) string { return strconv.Itoa(t.EveryX) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			every := s.Every
+			req.EveryX = &every
+		},
+	},
+	{
+		name:  "days",
+		kinds: []Kind{KindDaily},
+		want: func(s *TaskSpec) (string, bool) {
+			r, _ := s.repeat()
+			return r.String(), s.Days != nil
+		},
+		have: func(t *habitica.Task, _ *habitica.TagIndex) string { return t.Repeat.String() },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			req.Repeat, _ = s.repeat()
+		},
+	},
+	{
+		name:  "daysOfMonth",
+		kinds: []Kind{KindDaily},
+		want:  func(s *TaskSpec) (string, bool) { return intList(s.DaysOfMonth), s.DaysOfMonth != nil },
+		have:  func(t *habitica.Task, _ *habitica.TagIndex) string { return intList(t.DaysOfMonth) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			days := append([]int{}, s.DaysOfMonth...)
+			req.DaysOfMonth = &days
+		},
+	},
+	{
+		name:  "weeksOfMonth",
+		kinds: []Kind{KindDaily},
+		want:  func(s *TaskSpec) (string, bool) { return intList(s.WeeksOfMonth), s.WeeksOfMonth != nil },
+		have:  func(t *habitica.Task, _ *habitica.TagIndex) string { return intList(t.WeeksOfMonth) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			weeks := append([]int{}, s.WeeksOfMonth...)
+			req.WeeksOfMonth = &weeks
+		},
+	},
+	{
+		name:  "startDate",
+		kinds: []Kind{KindDaily},
+		want:  func(s *TaskSpec) (string, bool) { return s.StartDate, s.StartDate != "" },
+		have:  func(t *habitica.Task, _ *habitica.TagIndex) string { return formatDate(t.StartDate) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			d, _ := habitica.ParseDate(s.StartDate, time.Local)
+			ts := habitica.NewTimestamp(d)
+			req.StartDate = &ts
+		},
+	},
+	{
+		name:  "due",
+		kinds: []Kind{KindTodo},
+		want:  func(s *TaskSpec) (string, bool) { return s.Due, s.Due != "" },
+		have:  func(t *habitica.Task, _ *habitica.TagIndex) string { return formatDate(t.Date) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			d, _ := habitica.ParseDate(s.Due, time.Local)
+			ts := habitica.NewTimestamp(d)
+			req.Date = &ts
+		},
+	},
+	{
+		name:  "value",
+		kinds: []Kind{KindReward},
+		want: func(s *TaskSpec) (string, bool) {
+			if s.Value == nil {
+				return "", false
+			}
+			return strconv.FormatFloat(*s.Value, 'f', -1, 64), true
+		},
+		have: func(t *habitica.Task, _ *habitica.TagIndex) string { return strconv.FormatFloat(t.Value, 'f', -1, 64) },
+		set: func(req *habitica.TaskUpdateRequest, s *TaskSpec, _ *habitica.Task, _ []habitica.UUID) {
+			req.Value = s.Value
+		},
+	},
+}
+
+// diffFields compares the managed fields of spec with the live task.
+func diffFields(kind Kind, spec *TaskSpec, t *habitica.Task, tags *habitica.TagIndex) []FieldChange {
+	var out []FieldChange
+	for i := range fields {
+		f := &fields[i]
+		if !f.appliesTo(kind) {
+			continue
+		}
+		want, managed := f.want(spec)
+		if !managed {
+			continue
+		}
+		have := f.have(t, tags)
+		if want == have || (f.fold && strings.EqualFold(want, have)) {
+			continue
+		}
+		out = append(out, FieldChange{Field: f.name, Old: have, New: want})
+	}
+	return out
+}
+
+// mergeChecklist builds the desired checklist, keeping the ID and completion
+// state of live items with the same text.
+func mergeChecklist(texts []string, live []habitica.ChecklistItem) []habitica.ChecklistItem {
+	used := make([]bool, len(live))
+	out := make([]habitica.ChecklistItem, 0, len(texts))
+	for _, text := range texts {
+		item := habitica.ChecklistItem{Text: text}
+		for i, l := range live {
+			if !used[i] && l.Text == text {
+				used[i] = true
+				item = l
+				break
+			}
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,322 @@
+// Package mdsync syncs Markdown task lists with Habitica todos.
+//
+// Every heading followed by task list items becomes a todo, and the items
+// below it, at any nesting depth, become its checklist:
+//
+//	## Groceries <!-- habitica id=... updated=... hash=... items=... -->
+//	- [ ] Milk
+//	- [x] Bread
+//
+// A heading starting with "[x]" is a completed todo. The HTML comment links
+// the heading to its todo and records the state of the last sync; it is
+// added by Sync and hidden when the Markdown is rendered. Sync only edits
+// heading lines and task list items and adds items pulled from Habitica
+// below the last item of a section, so all other content is left as is.
+package mdsync
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// Document is a parsed Markdown file.
+type Document struct {
+	lines    []string
+	crlf     bool
+	Sections []*Section
+}
+
+// Section is a heading with its task list items.
+type Section struct {
+	Level int
+	Title string
+	Done  bool
+	// Marker links the section to a todo; nil before the first sync.
+	Marker *Marker
+	Items  []*Item
+
+	doc      *Document
+	line     int
+	checkbox bool // the heading had a "[ ]" or "[x]" prefix
+}
+
+// Item is a task list item.
+type Item struct {
+	Text string
+	Done bool
+
+	doc  *Document
+	line int
+	mark int // index of the checkbox mark in the line
+}
+
+// Marker is the sync state stored in a heading.
+type Marker struct {
+	ID habitica.UUID
+	// Updated is the UpdatedAt of the todo after the last sync.
+	Updated habitica.Timestamp
+	// Hash is the hash of the section after the last sync.
+	Hash string
+	// Items holds, for every item of the section after the last sync, the
+	// ItemKey of its checklist item, or "-" for none.
+	Items []string
+}
+
+// ItemKey returns the short hash of a checklist item ID stored in markers.
+func ItemKey(id habitica.UUID) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:3])
+}
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*$`)
+	markerPattern   = regexp.MustCompile(`\s*<!--\s*habitica\s+([^>]*?)\s*-->$`)
+	checkboxPattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*?)\s*$`)
+	titleBoxPattern = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	fencePattern    = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// Parse parses Markdown. Headings and checkboxes inside fenced code blocks
+// are ignored.
+func Parse(data []byte) *Document {
+	d := &Document{crlf: bytes.Contains(data, []byte("\r\n"))}
+	d.lines = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	var current *Section
+	inFence := false
+	for i, line := range d.lines {
+		if fencePattern.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			current = d.parseHeading(i, len(m[1]), m[2])
+			d.Sections = append(d.Sections, current)
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if m := checkboxPattern.FindStringSubmatchIndex(line); m != nil {
+			current.Items = append(current.Items, &Item{
+				Text: line[m[4]:m[5]],
+				Done: line[m[2]] != ' ',
+				doc:  d,
+				line: i,
+				mark: m[2],
+			})
+		}
+	}
+
+	// Headings without items only matter once they are linked to a todo.
+	sections := d.Sections[:0]
+	for _, s := range d.Sections {
+		if len(s.Items) > 0 || s.Marker != nil {
+			sections = append(sections, s)
+		}
+	}
+	d.Sections = sections
+	return d
+}
+
+func (d *Document) parseHeading(line, level int, text string) *Section {
+	s := &Section{Level: level, doc: d, line: line}
+	if m := markerPattern.FindStringSubmatchIndex(text); m != nil {
+		s.Marker = parseMarker(text[m[2]:m[3]])
+		text = text[:m[0]]
+	}
+	if m := titleBoxPattern.FindStringSubmatch(text); m != nil {
+		s.checkbox = true
+		s.Done = m[1] != " "
+		text = text[len(m[0]):]
+	}
+	s.Title = strings.TrimSpace(text)
+	return s
+}
+
+func parseMarker(attrs string) *Marker {
+	m := &Marker{}
+	for _, field := range strings.Fields(attrs) {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "id":
+			m.ID = habitica.UUID(value)
+		case "updated":
+			m.Updated = habitica.Timestamp(value)
+		case "hash":
+			m.Hash = value
+		case "items":
+			m.Items = strings.Split(value, ",")
+		}
+	}
+	if m.ID == "" {
+		return nil
+	}
+	return m
+}
+
+// Bytes renders the document, including all changes made by Sync.
+func (d *Document) Bytes() []byte {
+	sep := "\n"
+	if d.crlf {
+		sep = "\r\n"
+	}
+	return []byte(strings.Join(d.lines, sep))
+}
+
+// Hash returns a short hash of the title, completion and items of the section.
+func (s *Section) Hash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%t\n", s.Title, s.Done)
+	for _, it := range s.Items {
+		fmt.Fprintf(h, "%t %s\n", it.Done, it.Text)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// setDone marks the section as completed or open.
+func (s *Section) setDone(done bool) {
+	if s.Done == done {
+		return
+	}
+	s.Done = done
+	if done {
+		s.checkbox = true
+	}
+	s.render()
+}
+
+// setTitle renames the section.
+func (s *Section) setTitle(title string) {
+	s.Title = title
+	s.render()
+}
+
+// addItem adds a task list item after the last item of the section, using
+// the bullet and indentation of its first item.
+func (s *Section) addItem(text string, done bool) {
+	prefix, at := "- [", s.line+1
+	if n := len(s.Items); n > 0 {
+		first := s.Items[0]
+		prefix = s.doc.lines[first.line][:first.mark]
+		at = s.Items[n-1].line + 1
+	}
+	mark := " "
+	if done {
+		mark = "x"
+	}
+	s.doc.insertLine(at, prefix+mark+"] "+text)
+	s.Items = append(s.Items, &Item{Text: text, Done: done, doc: s.doc, line: at, mark: len(prefix)})
+}
+
+// setMarker stores the marker in the heading.
+func (s *Section) setMarker(m *Marker) {
+	s.Marker = m
+	s.render()
+}
+
+// render rewrites the heading line.
+func (s *Section) render() {
+	line := strings.Repeat("#", s.Level) + " "
+	if s.checkbox {
+		if s.Done {
+			line += "[x] "
+		} else {
+			line += "[ ] "
+		}
+	}
+	line += s.Title
+	if m := s.Marker; m != nil {
+		line += fmt.Sprintf(" <!-- habitica id=%s updated=%s hash=%s", m.ID, m.Updated, m.Hash)
+		if len(m.Items) > 0 {
+			line += " items=" + strings.Join(m.Items, ",")
+		}
+		line += " -->"
+	}
+	s.doc.lines[s.line] = line
+}
+
+// setDone checks or unchecks the item in place.
+func (it *Item) setDone(done bool) {
+	if it.Done == done {
+		return
+	}
+	it.Done = done
+	mark := " "
+	if done {
+		mark = "x"
+	}
+	line := it.doc.lines[it.line]
+	it.doc.lines[it.line] = line[:it.mark] + mark + line[it.mark+1:]
+}
+
+// setText replaces the text of the item in place.
+func (it *Item) setText(text string) {
+	it.Text = text
+	line := it.doc.lines[it.line]
+	it.doc.lines[it.line] = line[:it.mark+1] + "] " + text
+}
+
+// insertLine inserts a line before index at and moves the sections and
+// items below it.
+func (d *Document) insertLine(at int, line string) {
+	d.lines = append(d.lines[:at], append([]string{line}, d.lines[at:]...)...)
+	for _, s := range d.Sections {
+		if s.line >= at {
+			s.line++
+		}
+		for _, it := range s.Items {
+			if it.line >= at {
+				it.line++
+			}
+		}
+	}
+}
+
+// ReadFile reads and parses the Markdown file at path.
+func ReadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data), nil
+}
+
+// WriteFile writes the document to path via a temporary file, keeping the
+// permissions of an existing file.
+func (d *Document) WriteFile(path string) error {
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".mdsync-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(d.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,209 @@
+package mdsync_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/mdsync"
+	"github.com/danielrichardt/gohabitica/habitica/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const notes = `# Notes
+
+Some text with a [link](https://example.com).
+
+## Groceries
+- [ ] Milk
+- [x] Bread
+  - [ ] Rye, not wheat
+
+## Ideas
+
+No tasks here.
+
+` + "```md" + `
+## Not a heading
+- [ ] not an item
+` + "```" + `
+
+### [x] Done already
+* [x] All of it
+`
+
+func TestParse(t *testing.T) {
+	doc := mdsync.Parse([]byte(notes))
+	require.Len(t, doc.Sections, 2)
+
+	groceries := doc.Sections[0]
+	require.Equal(t, "Groceries", groceries.Title)
+	require.Equal(t, 2, groceries.Level)
+	require.False(t, groceries.Done)
+	require.Nil(t, groceries.Marker)
+	require.Len(t, groceries.Items, 3)
+	require.Equal(t, "Rye, not wheat", groceries.Items[2].Text)
+	require.True(t, groceries.Items[1].Done)
+
+	done := doc.Sections[1]
+	require.Equal(t, "Done already", done.Title)
+	require.True(t, done.Done)
+
+	require.Equal(t, notes, string(doc.Bytes()))
+
+	crlf := strings.ReplaceAll(notes, "\n", "\r\n")
+	require.Equal(t, crlf, string(mdsync.Parse([]byte(crlf)).Bytes()))
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	account := mock.NewAccount(nil, nil)
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	// The first sync creates the todos and links the headings.
+	doc := mdsync.Parse([]byte(notes))
+	changes, err := mdsync.Sync(ctx, srv.Client, doc, mdsync.Options{})
+	require.NoError(t, err)
+	require.Equal(t, []mdsync.Change{{Action: "create", Title: "Groceries"}, {Action: "create", Title: "Done already"}}, changes)
+
+	tasks := account.Tasks()
+	require.Len(t, tasks, 2)
+	require.Len(t, tasks[0].Checklist, 3)
+	require.True(t, tasks[0].Checklist[1].Completed)
+	require.True(t, tasks[1].Completed)
+
+	text := string(doc.Bytes())
+	require.Contains(t, text, "## Groceries <!-- habitica id="+string(tasks[0].ID)+" updated=")
+	require.Equal(t, strings.Count(notes, "\n"), strings.Count(text, "\n"))
+	require.Contains(t, text, "Some text with a [link](https://example.com).\n")
+	require.Contains(t, text, "## Not a heading\n- [ ] not an item\n")
+
+	// Nothing changed on either side.
+	doc = mdsync.Parse([]byte(text))
+	changes, err = mdsync.Sync(ctx, srv.Client, doc, mdsync.Options{})
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Check an item in the file: pushed.
+	text = strings.Replace(text, "- [ ] Milk", "- [x] Milk", 1)
+	doc = mdsync.Parse([]byte(text))
+	changes, err = mdsync.Sync(ctx, srv.Client, doc, mdsync.Options{})
+	require.NoError(t, err)
+	require.Equal(t, []mdsync.Change{{Action: "push", Title: "Groceries"}}, changes)
+	groceries := account.Task(string(tasks[0].ID))
+	require.True(t, groceries.Checklist[0].Completed)
+	require.Equal(t, tasks[0].Checklist[0].ID, groceries.Checklist[0].ID)
+	text = string(doc.Bytes())
+
+	// Complete the todo in Habitica: pulled.
+	time.Sleep(5 * time.Millisecond)
+	_, err = srv.Client.Tasks.ScoreTask(ctx, tasks[0].ID, "up")
+	require.NoError(t, err)
+	doc = mdsync.Parse([]byte(text))
+	changes, err = mdsync.Sync(ctx, srv.Client, doc, mdsync.Options{})
+	require.NoError(t, err)
+	require.Equal(t, []mdsync.Change{{Action: "pull", Title: "Groceries"}}, changes)
+	text = string(doc.Bytes())
+	require.Contains(t, text, "## [x] Groceries <!-- habitica")
+
+	// Change both sides: a conflict until a side is preferred.
+	time.Sleep(5 * time.Millisecond)
+	_, err = srv.Client.Tasks.ScoreTask(ctx, tasks[0].ID, "down")
+	require.NoError(t, err)
+	text = strings.Replace(text, "  - [ ] Rye", "  - [x] Rye", 1)
+
+	doc = mdsync.Parse([]byte(text))
+	changes, err = mdsync.Sync(ctx, srv.Client, doc, mdsync.Options{})
+	require.NoError(t, err)
+	require.Equal(t, []mdsync.Change{{Action: "conflict", Title: "Groceries"}}, changes)
+	require.Equal(t, text, string(doc.Bytes()))
+
+	changes, err = mdsync.Sync(ctx, srv.Client, doc, mdsync.Options{Prefer: mdsync.PreferHabitica})
+	require.NoError(t, err)
+	require.Equal(t, []mdsync.Change{{Action: "pull", Title: "Groceries"}}, changes)
+	require.Contains(t, string(doc.Bytes()), "## [ ] Groceries <!-- habitica")
+	// Habitica wins, so the item checked in the file is unchecked again.
+	require.Contains(t, string(doc.Bytes()), "  - [ ] Rye, not wheat")
+
+	// A deleted todo is reported as missing.
+	require.NoError(t, srv.Client.Tasks.DeleteTask(ctx, tasks[1].ID))
+	changes, err = mdsync.Sync(ctx, srv.Client, mdsync.Parse(doc.Bytes()), mdsync.Options{})
+	require.NoError(t, err)
+	require.Equal(t, []mdsync.Change{{Action: "missing", Title: "Done already"}}, changes)
+}
+
+func TestSync_DryRun(t *testing.T) {
+	account := mock.NewAccount(nil, nil)
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	doc := mdsync.Parse([]byte(notes))
+	changes, err := mdsync.Sync(context.Background(), srv.Client, doc, mdsync.Options{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Empty(t, account.Tasks())
+	require.Equal(t, notes, string(doc.Bytes()))
+}
+
+func TestSync_Checklist(t *testing.T) {
+	ctx := context.Background()
+	account := mock.NewAccount(nil, nil)
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	doc := mdsync.Parse([]byte("## Trip\n- [ ] Tickets\n- [ ] Hotel\n\nNotes after.\n"))
+	_, err = mdsync.Sync(ctx, srv.Client, doc, mdsync.Options{})
+	require.NoError(t, err)
+	id := doc.Sections[0].Marker.ID
+	hotel := account.Task(string(id)).Checklist[1].ID
+
+	// Rename the todo and an item and add an item in Habitica: pulled.
+	time.Sleep(5 * time.Millisecond)
+	title := "Trip to Rome"
+	_, err = srv.Client.Tasks.UpdateTask(ctx, id, &habitica.TaskUpdateRequest{Text: &title})
+	require.NoError(t, err)
+	_, err = srv.Client.Tasks.RenameChecklistItem(ctx, id, hotel, "Hotel in Trastevere")
+	require.NoError(t, err)
+	_, err = srv.Client.Tasks.AddChecklistItem(ctx, id, "Passport")
+	require.NoError(t, err)
+
+	doc = mdsync.Parse(doc.Bytes())
+	changes, err := mdsync.Sync(ctx, srv.Client, doc, mdsync.Options{})
+	require.NoError(t, err)
+	require.Equal(t, []mdsync.Change{{Action: "pull", Title: "Trip to Rome"}}, changes)
+	text := string(doc.Bytes())
+	require.Contains(t, text, "## Trip to Rome <!-- habitica")
+	require.Contains(t, text, "\n- [ ] Tickets\n- [ ] Hotel in Trastevere\n- [ ] Passport\n\nNotes after.\n")
+
+	// Add an item in Habitica and delete one from the file: with the file
+	// preferred, the new item is kept and the deleted one is deleted.
+	time.Sleep(5 * time.Millisecond)
+	_, err = srv.Client.Tasks.AddChecklistItem(ctx, id, "Adapter")
+	require.NoError(t, err)
+	text = strings.Replace(text, "- [ ] Passport\n", "", 1)
+	text = strings.Replace(text, "- [ ] Tickets", "- [x] Tickets", 1)
+
+	doc = mdsync.Parse([]byte(text))
+	changes, err = mdsync.Sync(ctx, srv.Client, doc, mdsync.Options{Prefer: mdsync.PreferFile})
+	require.NoError(t, err)
+	require.Equal(t, []mdsync.Change{{Action: "push", Title: "Trip to Rome"}}, changes)
+	require.Contains(t, string(doc.Bytes()), "\n- [x] Tickets\n- [ ] Hotel in Trastevere\n- [ ] Adapter\n\nNotes after.\n")
+
+	var items []string
+	for _, item := range account.Task(string(id)).Checklist {
+		items = append(items, item.Text)
+	}
+	require.Equal(t, []string{"Tickets", "Hotel in Trastevere", "Adapter"}, items)
+	require.Equal(t, hotel, account.Task(string(id)).Checklist[1].ID)
+
+	// Both sides agree now.
+	changes, err = mdsync.Sync(ctx, srv.Client, mdsync.Parse(doc.Bytes()), mdsync.Options{})
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
@@ -0,0 +1,304 @@
+package mdsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// Prefer selects the winning side of a conflict.
+type Prefer int
+
+const (
+	// PreferNone reports conflicts and leaves both sides alone.
+	PreferNone Prefer = iota
+	// PreferFile pushes the file over the todo.
+	PreferFile
+	// PreferHabitica pulls the todo into the file.
+	PreferHabitica
+)
+
+// Options controls Sync.
+type Options struct {
+	Prefer Prefer
+	// DryRun reports the changes without touching the account or the document.
+	DryRun bool
+}
+
+// Change is an action taken (or, for conflicts, not taken) by Sync.
+type Change struct {
+	// Action is create, push, pull, conflict or missing.
+	Action string
+	Title  string
+}
+
+// String formats the change for humans, e.g. `push: "Groceries"`.
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %q", c.Action, c.Title)
+}
+
+// Sync reconciles the sections of doc with their todos and updates doc in place.
+//
+// New sections create a todo with their items as checklist. For linked
+// sections, the hash in the marker tells whether the file changed and the
+// updated timestamp whether the todo changed (Task.UpdatedAt) since the last
+// sync:
+//   - only the file changed: title, checklist and completion are pushed.
+//     Checklist items added in Habitica since the last sync are kept and
+//     added to the file; items deleted from the file are deleted;
+//   - only the todo changed: its title, completion and checklist are
+//     pulled into the file. Renamed and checked items are updated in place
+//     and new items are added below the last item of the section;
+//   - both changed: a conflict, resolved by opts.Prefer.
+//
+// The marker records a short hash of the ID of every checklist item, so
+// items are told apart from the ones added or deleted on either side.
+// Items are matched by position while the section is unchanged and by text
+// otherwise. Items deleted in Habitica are left in the file.
+//
+// Sections whose todo no longer exists, for example because it was
+// completed and archived, are reported as missing and left alone.
+func Sync(ctx context.Context, client *habitica.Client, doc *Document, opts Options) ([]Change, error) {
+	open, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{Type: "todos"})
+	if err != nil {
+		return nil, err
+	}
+	completed, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{Type: "completedTodos"})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[habitica.UUID]*habitica.Task)
+	for _, t := range append(open, completed...) {
+		byID[t.ID] = t
+	}
+
+	var changes []Change
+	record := func(action string, s *Section) {
+		changes = append(changes, Change{Action: action, Title: s.Title})
+	}
+
+	for _, s := range doc.Sections {
+		if s.Marker == nil {
+			record("create", s)
+			if opts.DryRun {
+				continue
+			}
+			t, err := create(ctx, client, s)
+			if err != nil {
+				return changes, err
+			}
+			s.setMarker(newMarker(s, t))
+			continue
+		}
+
+		t := byID[s.Marker.ID]
+		if t == nil {
+			record("missing", s)
+			continue
+		}
+
+		fileChanged := s.Hash() != s.Marker.Hash
+		todoChanged := t.UpdatedAt != s.Marker.Updated
+		push := fileChanged && (!todoChanged || opts.Prefer == PreferFile)
+		pull := todoChanged && (!fileChanged || opts.Prefer == PreferHabitica)
+
+		switch {
+		case push:
+			record("push", s)
+			if opts.DryRun {
+				continue
+			}
+			if t, err = pushSection(ctx, client, s, t); err != nil {
+				return changes, err
+			}
+		case pull:
+			if pullSection(s, t, !fileChanged, opts.DryRun) {
+				record("pull", s)
+			}
+			if opts.DryRun {
+				continue
+			}
+		case fileChanged && todoChanged:
+			record("conflict", s)
+			continue
+		default:
+			continue
+		}
+		s.setMarker(newMarker(s, t))
+	}
+	return changes, nil
+}
+
+// newMarker returns the marker of a section that matches its todo.
+func newMarker(s *Section, t *habitica.Task) *Marker {
+	m := &Marker{ID: t.ID, Updated: t.UpdatedAt, Hash: s.Hash()}
+	match, _ := matchItems(s, t, false)
+	for _, i := range match {
+		key := "-"
+		if i >= 0 {
+			key = ItemKey(t.Checklist[i].ID)
+		}
+		m.Items = append(m.Items, key)
+	}
+	return m
+}
+
+// matchItems pairs the items of the section with the checklist of the todo.
+// It returns the index of the checklist item of every item, or -1, and
+// which checklist items were paired. With unchanged, the items are paired
+// by the keys in the marker, so items renamed in Habitica are found;
+// otherwise, or if the marker has no keys, they are paired by text.
+func matchItems(s *Section, t *habitica.Task, unchanged bool) (match []int, used []bool) {
+	match = make([]int, len(s.Items))
+	used = make([]bool, len(t.Checklist))
+	for i := range match {
+		match[i] = -1
+	}
+
+	if unchanged && s.Marker != nil && len(s.Marker.Items) == len(s.Items) {
+		for i, key := range s.Marker.Items {
+			for j, live := range t.Checklist {
+				if !used[j] && ItemKey(live.ID) == key {
+					used[j] = true
+					match[i] = j
+					break
+				}
+			}
+		}
+		return match, used
+	}
+
+	for i, it := range s.Items {
+		for j, live := range t.Checklist {
+			if !used[j] && live.Text == it.Text {
+				used[j] = true
+				match[i] = j
+				break
+			}
+		}
+	}
+	return match, used
+}
+
+// known returns the keys of the checklist items at the last sync.
+func known(s *Section) map[string]bool {
+	keys := make(map[string]bool)
+	if s.Marker != nil {
+		for _, key := range s.Marker.Items {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// create creates the todo of a new section.
+func create(ctx context.Context, client *habitica.Client, s *Section) (*habitica.Task, error) {
+	req := &habitica.TaskCreateRequest{Text: s.Title, Type: habitica.TaskTypeTodo}
+	for _, it := range s.Items {
+		req.Checklist = append(req.Checklist, habitica.ChecklistItem{Text: it.Text, Completed: it.Done})
+	}
+	t, err := client.Tasks.CreateTask(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("create todo %q: %w", s.Title, err)
+	}
+	if !s.Done {
+		return t, nil
+	}
+	if _, err := client.Tasks.ScoreTask(ctx, t.ID, "up"); err != nil {
+		return nil, fmt.Errorf("complete todo %q: %w", s.Title, err)
+	}
+	return client.Tasks.GetTask(ctx, t.ID)
+}
+
+// pushSection makes the todo match the section and returns the updated todo.
+// Checklist items added in Habitica since the last sync are kept and added
+// to the section.
+func pushSection(ctx context.Context, client *habitica.Client, s *Section, t *habitica.Task) (*habitica.Task, error) {
+	// Keep the IDs of items with the same text.
+	match, used := matchItems(s, t, false)
+	checklist := make([]habitica.ChecklistItem, 0, len(t.Checklist))
+	for i, it := range s.Items {
+		item := habitica.ChecklistItem{Text: it.Text, Completed: it.Done}
+		if match[i] >= 0 {
+			item.ID = t.Checklist[match[i]].ID
+		}
+		checklist = append(checklist, item)
+	}
+	keys := known(s)
+	var added []habitica.ChecklistItem
+	for j, live := range t.Checklist {
+		if !used[j] && !keys[ItemKey(live.ID)] {
+			added = append(added, live)
+		}
+	}
+	checklist = append(checklist, added...)
+
+	title := s.Title
+	if _, err := client.Tasks.UpdateTask(ctx, t.ID, &habitica.TaskUpdateRequest{Text: &title, Checklist: &checklist}); err != nil {
+		return nil, fmt.Errorf("update todo %q: %w", s.Title, err)
+	}
+	if s.Done != t.Completed {
+		direction := "up"
+		if !s.Done {
+			direction = "down"
+		}
+		if _, err := client.Tasks.ScoreTask(ctx, t.ID, direction); err != nil {
+			return nil, fmt.Errorf("score todo %q: %w", s.Title, err)
+		}
+	}
+	for _, live := range added {
+		s.addItem(live.Text, live.Completed)
+	}
+	return client.Tasks.GetTask(ctx, t.ID)
+}
+
+// pullSection copies the title, completion and checklist of the todo into the
+// section and reports whether anything changed. unchanged tells whether the
+// section is unchanged since the last sync. With dryRun the section is not
+// modified.
+func pullSection(s *Section, t *habitica.Task, unchanged, dryRun bool) bool {
+	changed := false
+	set := func(current, want bool, apply func(bool)) {
+		if current == want {
+			return
+		}
+		changed = true
+		if !dryRun {
+			apply(want)
+		}
+	}
+
+	if s.Title != t.Text {
+		changed = true
+		if !dryRun {
+			s.setTitle(t.Text)
+		}
+	}
+	set(s.Done, t.Completed, s.setDone)
+
+	match, used := matchItems(s, t, unchanged)
+	for i, it := range s.Items {
+		if match[i] < 0 {
+			continue
+		}
+		live := t.Checklist[match[i]]
+		if it.Text != live.Text {
+			changed = true
+			if !dryRun {
+				it.setText(live.Text)
+			}
+		}
+		set(it.Done, live.Completed, it.setDone)
+	}
+	for j, live := range t.Checklist {
+		if used[j] {
+			continue
+		}
+		changed = true
+		if !dryRun {
+			s.addItem(live.Text, live.Completed)
+		}
+	}
+	return changed
+}
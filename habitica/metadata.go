@@ -0,0 +1,104 @@
+package habitica
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// ResponseMeta holds metadata of a Habitica response that is not part of
+// the returned data, most notably the notifications and the user version
+// which every v3 response envelope carries.
+type ResponseMeta struct {
+	StatusCode    int
+	Header        http.Header
+	Notifications []UserNotification
+	UserV         int
+	RateLimit     RateLimit
+}
+
+type responseMetaKey struct{}
+
+// WithResponseMeta returns a context that makes the client fill meta with the
+// metadata of the response to the request it is used for. If a request is
+// retried, meta describes the last attempt.
+//
+//	var meta habitica.ResponseMeta
+//	tasks, err := client.Tasks.ListUserTasks(habitica.WithResponseMeta(ctx, &meta), filter)
+//	fmt.Println(meta.UserV, len(meta.Notifications))
+func WithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, responseMetaKey{}, meta)
+}
+
+// NotificationHandler is called with notifications that have not been seen
+// in any previous response of the same client.
+type NotificationHandler func(ctx context.Context, notifications []UserNotification)
+
+// WithNotificationHandler registers a callback that fires whenever a response
+// carries new notifications (level ups, drops, quest invites, ...). Habitica
+// repeats unread notifications on every response, so each notification is
+// reported only once per client. The callback runs synchronously on the
+// goroutine that issued the request.
+func WithNotificationHandler(fn NotificationHandler) Option {
+	return func(c *Client) {
+		c.notifications.handler = fn
+	}
+}
+
+// observeResponse records response metadata for the caller and dispatches new notifications.
+func (c *Client) observeResponse(ctx context.Context, resp *http.Response, env *APIResponse[json.RawMessage]) {
+	if meta, ok := ctx.Value(responseMetaKey{}).(*ResponseMeta); ok && meta != nil {
+		*meta = ResponseMeta{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			RateLimit:  c.rateLimiter.snapshot(),
+		}
+		if env != nil {
+			meta.Notifications = env.Notifications
+			meta.UserV = env.UserV
+		}
+	}
+
+	if env != nil {
+		if fresh := c.notifications.observe(env.Notifications); len(fresh) > 0 {
+			c.notifications.handler(ctx, fresh)
+		}
+	}
+}
+
+// notificationTracker remembers which notifications have already been reported.
+type notificationTracker struct {
+	handler NotificationHandler
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// observe returns the notifications not contained in the previous response.
+// A nil slice means the response did not carry notifications at all and
+// leaves the tracked state untouched.
+func (t *notificationTracker) observe(list []UserNotification) []UserNotification {
+	if t.handler == nil || list == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var fresh []UserNotification
+	current := make(map[string]bool, len(list))
+	for _, n := range list {
+		current[n.ID] = true
+		if !t.seen[n.ID] {
+			fresh = append(fresh, n)
+		}
+	}
+	// Habitica sends the full list of unread notifications, so replacing the
+	// set keeps it bounded while still suppressing repeats.
+	t.seen = current
+	return fresh
+}
@@ -0,0 +1,73 @@
+package habitica
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ResponseMeta(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Remaining", "12")
+		_ = json.NewEncoder(w).Encode(APIResponse[User]{
+			Success: true,
+			Data:    User{ID: "user-id"},
+			Notifications: []UserNotification{
+				{ID: "n-1", Type: "LEVELED_UP", Data: map[string]any{"newLvl": float64(5)}},
+			},
+			UserV: 421,
+		})
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	var meta ResponseMeta
+	u, err := client.User.GetCurrent(WithResponseMeta(context.Background(), &meta))
+	require.NoError(t, err)
+	require.Equal(t, UUID("user-id"), u.ID)
+
+	require.Equal(t, http.StatusOK, meta.StatusCode)
+	require.Equal(t, 421, meta.UserV)
+	require.Len(t, meta.Notifications, 1)
+	require.Equal(t, "LEVELED_UP", meta.Notifications[0].Type)
+	require.Equal(t, 12, meta.RateLimit.Remaining)
+	require.Equal(t, "application/json", meta.Header.Get("Content-Type"))
+}
+
+func TestClient_NotificationHandler_FiresOncePerNotification(t *testing.T) {
+	responses := [][]UserNotification{
+		{{ID: "n-1", Type: "LEVELED_UP"}},
+		{{ID: "n-1", Type: "LEVELED_UP"}, {ID: "n-2", Type: "DROP_CAP_REACHED"}},
+		nil,
+		{{ID: "n-2", Type: "DROP_CAP_REACHED"}},
+	}
+	call := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{
+			Success:       true,
+			Notifications: responses[call],
+		})
+		call++
+	}
+
+	var got []string
+	client, srv := newTestClient(t, handler, WithNotificationHandler(func(ctx context.Context, ns []UserNotification) {
+		for _, n := range ns {
+			got = append(got, n.ID)
+		}
+	}))
+	defer srv.Close()
+
+	for range responses {
+		require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil))
+	}
+	require.Equal(t, []string{"n-1", "n-2"}, got)
+}
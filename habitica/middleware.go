@@ -0,0 +1,134 @@
+package habitica
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Doer executes a single HTTP request. *http.Client satisfies this interface.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts an ordinary function to the Doer interface.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer to add cross-cutting behavior such as logging,
+// metrics, header injection or fault injection. Every attempt of a request
+// (including retries) passes through the middleware chain.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware appends middleware to the client's chain. Middleware is
+// applied in the given order: the first one sees the request first and the
+// response last.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		for _, m := range mw {
+			if m != nil {
+				c.middleware = append(c.middleware, m)
+			}
+		}
+	}
+}
+
+// chain wraps the terminal Doer with the given middleware, first one outermost.
+func chain(terminal Doer, mw []Middleware) Doer {
+	d := terminal
+	for i := len(mw) - 1; i >= 0; i-- {
+		d = mw[i](d)
+	}
+	return d
+}
+
+// ReadEnvelope decodes the Habitica response envelope of resp. The body is
+// buffered and restored, so the response can still be consumed afterwards.
+// It is meant to be used from middleware that wants to inspect the
+// success flag, error code or data of a response.
+func ReadEnvelope(resp *http.Response) (*APIResponse[json.RawMessage], error) {
+	if resp == nil || resp.Body == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	var env APIResponse[json.RawMessage]
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, err
+	}
+	return &env, nil
+}
+
+// redactedHeaders lists request headers whose values must never be logged.
+var redactedHeaders = map[string]bool{
+	"x-api-key":     true,
+	"authorization": true,
+}
+
+// LoggingMiddleware logs every request attempt with method, URL, status and
+// duration to logger. Successful requests are logged at debug level, failures
+// at warn level together with the Habitica error code. Credentials such as
+// the x-api-key header are redacted.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.String()),
+				slog.Duration("duration", time.Since(start)),
+				headerAttr(req.Header),
+			}
+			level := slog.LevelDebug
+
+			switch {
+			case err != nil:
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.String("error", err.Error()))
+			case resp.StatusCode >= 400:
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+				if env, envErr := ReadEnvelope(resp); envErr == nil && env.Error != "" {
+					attrs = append(attrs, slog.String("code", env.Error))
+				}
+			default:
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			}
+
+			logger.LogAttrs(req.Context(), level, "habitica request", attrs...)
+			return resp, err
+		})
+	}
+}
+
+// headerAttr renders request headers as a log group with credentials redacted.
+func headerAttr(h http.Header) slog.Attr {
+	attrs := make([]any, 0, len(h))
+	for name, values := range h {
+		v := strings.Join(values, ", ")
+		if redactedHeaders[strings.ToLower(name)] {
+			v = "REDACTED"
+		}
+		attrs = append(attrs, slog.String(name, v))
+	}
+	return slog.Group("headers", attrs...)
+}
@@ -0,0 +1,157 @@
+package habitica
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Middleware_Order(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "outer,inner", r.Header.Get("X-Trace"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{Success: true})
+	}
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				if prev := req.Header.Get("X-Trace"); prev != "" {
+					req.Header.Set("X-Trace", prev+","+name)
+				} else {
+					req.Header.Set("X-Trace", name)
+				}
+				order = append(order, "before "+name)
+				resp, err := next.Do(req)
+				order = append(order, "after "+name)
+				return resp, err
+			})
+		}
+	}
+
+	client, srv := newTestClient(t, handler, WithMiddleware(trace("outer"), trace("inner")))
+	defer srv.Close()
+
+	require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil))
+	require.Equal(t, []string{"before outer", "before inner", "after inner", "after outer"}, order)
+}
+
+func TestClient_Middleware_EnvelopeAccess(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(APIResponse[map[string]string]{
+			Success: true,
+			Data:    map[string]string{"foo": "bar"},
+		})
+	}
+
+	var seen string
+	inspect := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			env, err := ReadEnvelope(resp)
+			require.NoError(t, err)
+			require.True(t, env.Success)
+			seen = string(env.Data)
+			return resp, nil
+		})
+	}
+
+	client, srv := newTestClient(t, handler, WithMiddleware(inspect))
+	defer srv.Close()
+
+	var out map[string]string
+	require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, &out))
+	require.JSONEq(t, `{"foo":"bar"}`, seen)
+	require.Equal(t, "bar", out["foo"], "body must still be decodable after the middleware read it")
+}
+
+func TestClient_Middleware_FaultInjectionIsRetried(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{Success: true})
+	}
+
+	failures := 1
+	faulty := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if failures > 0 {
+				failures--
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader("")),
+					Request:    req,
+				}, nil
+			}
+			return next.Do(req)
+		})
+	}
+
+	client, srv := newTestClient(t, handler, WithMiddleware(faulty), WithRetryPolicy(DefaultRetryPolicy()))
+	defer srv.Close()
+	noSleep(client)
+
+	require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil))
+	require.Zero(t, failures)
+}
+
+func TestClient_Middleware_ErrorPropagates(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request must not reach the server")
+	}
+
+	boom := errors.New("boom")
+	failing := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, boom
+		})
+	}
+
+	client, srv := newTestClient(t, handler, WithMiddleware(failing))
+	defer srv.Close()
+
+	err := client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil)
+	require.ErrorIs(t, err, boom)
+}
+
+func TestLoggingMiddleware_RedactsAPIKey(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{
+			Success: false,
+			Error:   "NotFound",
+			Message: "Task not found.",
+		})
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client, srv := newTestClient(t, handler, WithMiddleware(LoggingMiddleware(logger)))
+	defer srv.Close()
+
+	err := client.doRequest(context.Background(), http.MethodGet, "/tasks/missing", nil, nil, nil)
+	require.True(t, IsNotFound(err))
+
+	out := buf.String()
+	require.NotContains(t, out, "api-token")
+	require.Contains(t, out, `"X-Api-Key":"REDACTED"`)
+	require.Contains(t, out, `"X-Api-User":"user-id"`)
+	require.Contains(t, out, `"status":404`)
+	require.Contains(t, out, `"code":"NotFound"`)
+	require.Contains(t, out, `"level":"WARN"`)
+}
@@ -0,0 +1,494 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// Account is an in-memory Habitica account serving the task and tag
+// endpoints as well as GET /user and POST /user/allocate. It is meant for
+// tests of code that reads and writes tasks, e.g. mock.NewServer(account).
+//
+// Tasks can be addressed by ID or alias like on Habitica. Every request is
+// recorded as "METHOD /path" in Requests.
+type Account struct {
+	mu       sync.Mutex
+	tasks    []*habitica.Task
+	tags     []*habitica.Tag
+	user     habitica.User
+	requests []string
+	nextID   int
+}
+
+// NewAccount returns an account holding copies of the given tasks and tags.
+func NewAccount(tasks []*habitica.Task, tags []*habitica.Tag) *Account {
+	a := &Account{}
+	for _, t := range tasks {
+		a.tasks = append(a.tasks, cloneTask(t))
+	}
+	for _, t := range tags {
+		tag := *t
+		a.tags = append(a.tags, &tag)
+	}
+	return a
+}
+
+// Tasks returns a copy of all tasks in their current order.
+func (a *Account) Tasks() []*habitica.Task {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	out := make([]*habitica.Task, len(a.tasks))
+	for i, t := range a.tasks {
+		out[i] = cloneTask(t)
+	}
+	return out
+}
+
+// Task returns a copy of the task with the given ID or alias, or nil.
+func (a *Account) Task(idOrAlias string) *habitica.Task {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, t := a.findTask(idOrAlias); t != nil {
+		return cloneTask(t)
+	}
+	return nil
+}
+
+// Tags returns a copy of all tags in their current order.
+func (a *Account) Tags() []*habitica.Tag {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	out := make([]*habitica.Tag, len(a.tags))
+	for i, t := range a.tags {
+		tag := *t
+		out[i] = &tag
+	}
+	return out
+}
+
+// SetUser replaces the user returned by GET /user.
+func (a *Account) SetUser(u *habitica.User) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.user = *u
+}
+
+// User returns a copy of the user.
+func (a *Account) User() *habitica.User {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	u := a.user
+	return &u
+}
+
+// Requests returns the requests served so far as "METHOD /path".
+func (a *Account) Requests() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.requests...)
+}
+
+// ServeHTTP implements http.Handler.
+func (a *Account) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.requests = append(a.requests, r.Method+" "+r.URL.Path)
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/tasks/user":
+		a.listTasks(w, r.URL.Query().Get("type"))
+	case r.Method == http.MethodPost && r.URL.Path == "/tasks/user":
+		a.createTask(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/user":
+		writeData(w, a.user)
+	case r.Method == http.MethodPost && r.URL.Path == "/user/allocate":
+		a.allocate(w, r.URL.Query().Get("stat"))
+	case r.Method == http.MethodGet && r.URL.Path == "/tags":
+		writeData(w, a.tags)
+	case r.Method == http.MethodPost && r.URL.Path == "/tags":
+		a.createTag(w, r)
+	case len(parts) == 2 && parts[0] == "tags":
+		a.tag(w, r, parts[1])
+	case len(parts) >= 2 && parts[0] == "tasks":
+		a.task(w, r, parts[1], parts[2:])
+	default:
+		writeError(w, http.StatusNotFound, "NotFound", "unknown route "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (a *Account) listTasks(w http.ResponseWriter, typ string) {
+	out := []*habitica.Task{}
+	for _, t := range a.tasks {
+		switch typ {
+		case "":
+			if t.Type == habitica.TaskTypeTodo && t.Completed {
+				continue
+			}
+		case "completedTodos":
+			if t.Type != habitica.TaskTypeTodo || !t.Completed {
+				continue
+			}
+		case "todos":
+			if t.Type != habitica.TaskTypeTodo || t.Completed {
+				continue
+			}
+		default:
+			if string(t.Type)+"s" != typ {
+				continue
+			}
+		}
+		out = append(out, t)
+	}
+	writeData(w, out)
+}
+
+func (a *Account) createTask(w http.ResponseWriter, r *http.Request) {
+	var in habitica.TaskCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+		return
+	}
+	if in.Alias != "" {
+		if _, t := a.findTask(in.Alias); t != nil {
+			writeError(w, http.StatusBadRequest, "BadRequest", "Task alias already used on another task.")
+			return
+		}
+	}
+
+	now := habitica.NewTimestamp(time.Now())
+	t := &habitica.Task{
+		ID:           a.newID("task"),
+		Alias:        in.Alias,
+		Text:         in.Text,
+		Notes:        in.Notes,
+		Type:         in.Type,
+		Priority:     in.Priority,
+		Value:        in.Value,
+		Tags:         append([]habitica.UUID{}, in.Tags...),
+		Attribute:    in.Attribute,
+		Reminders:    in.Reminders,
+		Date:         in.Date,
+		Frequency:    in.Frequency,
+		Repeat:       in.Repeat,
+		EveryX:       in.EveryX,
+		StartDate:    in.StartDate,
+		DaysOfMonth:  in.DaysOfMonth,
+		WeeksOfMonth: in.WeeksOfMonth,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if t.Priority == 0 {
+		t.Priority = 1
+	}
+	if t.Type == habitica.TaskTypeHabit {
+		t.Up, t.Down = true, true
+		if in.Up != nil {
+			t.Up = *in.Up
+		}
+		if in.Down != nil {
+			t.Down = *in.Down
+		}
+	}
+	for _, item := range in.Checklist {
+		item.ID = a.newID("item")
+		t.Checklist = append(t.Checklist, item)
+	}
+
+	a.tasks = append(a.tasks, t)
+	writeData(w, t)
+}
+
+func (a *Account) task(w http.ResponseWriter, r *http.Request, idOrAlias string, rest []string) {
+	i, t := a.findTask(idOrAlias)
+	if t == nil {
+		writeError(w, http.StatusNotFound, "NotFound", "Task not found.")
+		return
+	}
+
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeData(w, t)
+	case len(rest) == 0 && r.Method == http.MethodPut:
+		var in habitica.TaskUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+			return
+		}
+		a.applyUpdate(t, &in)
+		writeData(w, t)
+	case len(rest) == 0 && r.Method == http.MethodDelete:
+		a.tasks = append(a.tasks[:i], a.tasks[i+1:]...)
+		writeData(w, struct{}{})
+	case len(rest) == 2 && rest[0] == "score" && r.Method == http.MethodPost:
+		a.score(w, t, rest[1])
+	case len(rest) == 1 && rest[0] == "checklist" && r.Method == http.MethodPost:
+		var item habitica.ChecklistItem
+		if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+			writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+			return
+		}
+		item.ID = a.newID("item")
+		t.Checklist = append(t.Checklist, item)
+		writeData(w, t)
+	case len(rest) >= 2 && rest[0] == "checklist":
+		a.checklistItem(w, r, t, habitica.UUID(rest[1]), rest[2:])
+	case len(rest) == 2 && rest[0] == "tags":
+		tagID := habitica.UUID(rest[1])
+		tags := t.Tags[:0:0]
+		for _, id := range t.Tags {
+			if id != tagID {
+				tags = append(tags, id)
+			}
+		}
+		if r.Method == http.MethodPost {
+			tags = append(tags, tagID)
+		}
+		t.Tags = tags
+		writeData(w, t)
+	default:
+		writeError(w, http.StatusNotFound, "NotFound", "unknown route "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (a *Account) applyUpdate(t *habitica.Task, in *habitica.TaskUpdateRequest) {
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setString(&t.Text, in.Text)
+	setString(&t.Notes, in.Notes)
+	setString(&t.Alias, in.Alias)
+	setString(&t.Attribute, in.Attribute)
+	if in.Priority != nil {
+		t.Priority = *in.Priority
+	}
+	if in.Value != nil {
+		t.Value = *in.Value
+	}
+	if in.Tags != nil {
+		t.Tags = append([]habitica.UUID{}, (*in.Tags)...)
+	}
+	if in.Checklist != nil {
+		t.Checklist = nil
+		for _, item := range *in.Checklist {
+			if item.ID == "" {
+				item.ID = a.newID("item")
+			}
+			t.Checklist = append(t.Checklist, item)
+		}
+	}
+	if in.Reminders != nil {
+		t.Reminders = *in.Reminders
+	}
+	if in.Date != nil {
+		t.Date = *in.Date
+	}
+	if in.Up != nil {
+		t.Up = *in.Up
+	}
+	if in.Down != nil {
+		t.Down = *in.Down
+	}
+	if in.CounterUp != nil {
+		t.CounterUp = *in.CounterUp
+	}
+	if in.CounterDown != nil {
+		t.CounterDown = *in.CounterDown
+	}
+	if in.Frequency != nil {
+		t.Frequency = *in.Frequency
+	}
+	if in.Repeat != nil {
+		t.Repeat = in.Repeat
+	}
+	if in.EveryX != nil {
+		t.EveryX = *in.EveryX
+	}
+	if in.StartDate != nil {
+		t.StartDate = *in.StartDate
+	}
+	if in.DaysOfMonth != nil {
+		t.DaysOfMonth = *in.DaysOfMonth
+	}
+	if in.WeeksOfMonth != nil {
+		t.WeeksOfMonth = *in.WeeksOfMonth
+	}
+	if in.Streak != nil {
+		t.Streak = *in.Streak
+	}
+	t.UpdatedAt = habitica.NewTimestamp(time.Now())
+}
+
+func (a *Account) score(w http.ResponseWriter, t *habitica.Task, direction string) {
+	delta := 1.0
+	if direction == "down" {
+		delta = -1
+	}
+	switch t.Type {
+	case habitica.TaskTypeTodo, habitica.TaskTypeDaily:
+		t.Completed = direction == "up"
+		if t.Type == habitica.TaskTypeTodo && t.Completed {
+			t.DateCompleted = habitica.NewTimestamp(time.Now())
+		}
+	case habitica.TaskTypeHabit:
+		if direction == "up" {
+			t.CounterUp++
+		} else {
+			t.CounterDown++
+		}
+	}
+	t.Value += delta
+	t.UpdatedAt = habitica.NewTimestamp(time.Now())
+	writeData(w, habitica.ScoreResult{Delta: delta})
+}
+
+func (a *Account) checklistItem(w http.ResponseWriter, r *http.Request, t *habitica.Task, itemID habitica.UUID, rest []string) {
+	for i := range t.Checklist {
+		item := &t.Checklist[i]
+		if item.ID != itemID {
+			continue
+		}
+		switch {
+		case len(rest) == 0 && r.Method == http.MethodPut:
+			var in struct {
+				Text      *string `json:"text"`
+				Completed *bool   `json:"completed"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+				return
+			}
+			if in.Text != nil {
+				item.Text = *in.Text
+			}
+			if in.Completed != nil {
+				item.Completed = *in.Completed
+			}
+		case len(rest) == 0 && r.Method == http.MethodDelete:
+			t.Checklist = append(t.Checklist[:i], t.Checklist[i+1:]...)
+		case len(rest) == 1 && rest[0] == "score" && r.Method == http.MethodPost:
+			item.Completed = !item.Completed
+		default:
+			writeError(w, http.StatusNotFound, "NotFound", "unknown route "+r.Method+" "+r.URL.Path)
+			return
+		}
+		t.UpdatedAt = habitica.NewTimestamp(time.Now())
+		writeData(w, t)
+		return
+	}
+	writeError(w, http.StatusNotFound, "NotFound", "Checklist item not found.")
+}
+
+func (a *Account) allocate(w http.ResponseWriter, stat string) {
+	stats := &a.user.Stats
+	if stats.Points < 1 {
+		writeError(w, http.StatusUnauthorized, "NotAuthorized", "You don't have enough attribute points.")
+		return
+	}
+	switch stat {
+	case "str":
+		stats.Str++
+	case "con":
+		stats.Con++
+	case "int":
+		stats.Int++
+	case "per":
+		stats.Per++
+	default:
+		writeError(w, http.StatusBadRequest, "BadRequest", "Invalid stat "+stat)
+		return
+	}
+	stats.Points--
+	writeData(w, stats)
+}
+
+func (a *Account) createTag(w http.ResponseWriter, r *http.Request) {
+	var in habitica.Tag
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+		return
+	}
+	tag := &habitica.Tag{ID: a.newID("tag"), Name: in.Name}
+	a.tags = append(a.tags, tag)
+	writeData(w, tag)
+}
+
+func (a *Account) tag(w http.ResponseWriter, r *http.Request, id string) {
+	for i, tag := range a.tags {
+		if string(tag.ID) != id {
+			continue
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeData(w, tag)
+		case http.MethodPut:
+			var in habitica.Tag
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+				return
+			}
+			tag.Name = in.Name
+			writeData(w, tag)
+		case http.MethodDelete:
+			a.tags = append(a.tags[:i], a.tags[i+1:]...)
+			for _, t := range a.tasks {
+				kept := t.Tags[:0]
+				for _, tid := range t.Tags {
+					if tid != tag.ID {
+						kept = append(kept, tid)
+					}
+				}
+				t.Tags = kept
+			}
+			writeData(w, struct{}{})
+		default:
+			writeError(w, http.StatusNotFound, "NotFound", "unknown route "+r.Method+" "+r.URL.Path)
+		}
+		return
+	}
+	writeError(w, http.StatusNotFound, "NotFound", "Tag not found.")
+}
+
+func (a *Account) findTask(idOrAlias string) (int, *habitica.Task) {
+	for i, t := range a.tasks {
+		if string(t.ID) == idOrAlias || (t.Alias != "" && t.Alias == idOrAlias) {
+			return i, t
+		}
+	}
+	return -1, nil
+}
+
+func (a *Account) newID(prefix string) habitica.UUID {
+	a.nextID++
+	return habitica.UUID(fmt.Sprintf("%s-%d", prefix, a.nextID))
+}
+
+func cloneTask(t *habitica.Task) *habitica.Task {
+	raw, _ := json.Marshal(t)
+	var c habitica.Task
+	_ = json.Unmarshal(raw, &c)
+	return &c
+}
+
+func writeData(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"success": true, "data": data})
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"success": false, "error": code, "message": message})
+}
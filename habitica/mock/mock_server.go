@@ -16,7 +16,8 @@ type Server struct {
 }
 
 // NewServer creates a new mock server with the given handler.
-func NewServer(handler http.Handler) (*Server, error) {
+// Additional client options are applied after the defaults.
+func NewServer(handler http.Handler, opts ...habitica.Option) (*Server, error) {
 	srv := httptest.NewServer(handler)
 
 	cfg := &config.Config{
@@ -25,9 +26,11 @@ func NewServer(handler http.Handler) (*Server, error) {
 		APIToken: "test-token",
 	}
 
-	client, err := habitica.NewClient(cfg, habitica.WithHTTPClient(&http.Client{
+	clientOpts := append([]habitica.Option{habitica.WithHTTPClient(&http.Client{
 		Timeout: 5 * time.Second,
-	}))
+	})}, opts...)
+
+	client, err := habitica.NewClient(cfg, clientOpts...)
 	if err != nil {
 		srv.Close()
 		return nil, err
@@ -0,0 +1,275 @@
+package habitica
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrQueuedOffline is returned by mutating TasksService calls when the API
+// could not be reached and the request was recorded in the offline journal
+// instead. Use Client.ReplayOfflineJournal to send it later.
+var ErrQueuedOffline = errors.New("habitica: API unreachable, request queued for offline replay")
+
+// JournalEntry is a mutating request recorded while the API was unreachable.
+type JournalEntry struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  string          `json:"query,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	// TaskID is the task targeted by the request; empty for task creation.
+	TaskID   UUID      `json:"taskId,omitempty"`
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+// OfflineJournal is an append-only file of JournalEntry values, one JSON
+// document per line. It is safe for concurrent use within a process.
+type OfflineJournal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// OpenOfflineJournal returns a journal backed by the file at path. The file
+// and its parent directories are created on the first append.
+func OpenOfflineJournal(path string) *OfflineJournal {
+	return &OfflineJournal{path: path}
+}
+
+// Path returns the location of the journal file.
+func (j *OfflineJournal) Path() string {
+	return j.path
+}
+
+// Append adds an entry to the end of the journal.
+func (j *OfflineJournal) Append(e JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Entries returns all entries in the order they were appended.
+// A missing journal file yields no entries.
+func (j *OfflineJournal) Entries() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.entries()
+}
+
+func (j *OfflineJournal) entries() ([]JournalEntry, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("offline journal %s, line %d: %w", j.path, n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// replace atomically rewrites the journal with the given entries.
+func (j *OfflineJournal) replace(entries []JournalEntry) error {
+	if len(entries) == 0 {
+		if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// WithOfflineJournal enables offline mode: mutating TasksService requests
+// that fail because the API is unreachable are appended to j and reported
+// as ErrQueuedOffline instead of failing outright.
+func WithOfflineJournal(j *OfflineJournal) Option {
+	return func(c *Client) {
+		c.journal = j
+	}
+}
+
+// Unreachable reports whether err means the API could not be reached while
+// offline mode is on. Commands use it to queue a mutation even though a
+// lookup it depends on, such as resolving tag names, failed.
+func (c *Client) Unreachable(ctx context.Context, err error) bool {
+	return c.journal != nil && isNetworkError(ctx, err)
+}
+
+// journalable reports whether a failed request should be recorded in the offline journal.
+func (c *Client) journalable(ctx context.Context, method, p string, err error) bool {
+	if c.journal == nil || ctx.Value(replayKey{}) != nil {
+		return false
+	}
+	return isWrite(method) && strings.HasPrefix(strings.TrimPrefix(p, "/"), "tasks/") && isNetworkError(ctx, err)
+}
+
+// isNetworkError reports whether err means the API could not be reached,
+// as opposed to a response, a cancellation or a local error.
+func isNetworkError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// replayKey marks requests issued by ReplayOfflineJournal, which must not be journaled again.
+type replayKey struct{}
+
+// journalRequest records a request that could not be delivered.
+func (c *Client) journalRequest(method, p string, query url.Values, body any) error {
+	e := JournalEntry{
+		Method:   method,
+		Path:     p,
+		QueuedAt: time.Now().UTC(),
+	}
+	if len(query) > 0 {
+		e.Query = query.Encode()
+	}
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		e.Body = raw
+	}
+	// Paths look like /tasks/:id[/...]; /tasks/user is the collection used for creation.
+	if parts := strings.Split(strings.Trim(p, "/"), "/"); len(parts) >= 2 && parts[1] != "user" {
+		e.TaskID = UUID(parts[1])
+	}
+
+	if err := c.journal.Append(e); err != nil {
+		return fmt.Errorf("request could not be journaled: %w", err)
+	}
+	return ErrQueuedOffline
+}
+
+// ReplayConflict describes a journaled request that can no longer be applied.
+type ReplayConflict struct {
+	Entry  JournalEntry
+	Reason string
+	Err    error
+}
+
+// ReplayReport summarizes a replay of the offline journal.
+type ReplayReport struct {
+	// Applied lists the entries that were sent successfully.
+	Applied []JournalEntry
+	// Conflicts lists entries rejected by Habitica, e.g. because the target
+	// task was deleted in the meantime. They are removed from the journal.
+	Conflicts []ReplayConflict
+	// Pending lists entries left in the journal because the API is still unreachable.
+	Pending []JournalEntry
+}
+
+// ReplayOfflineJournal sends the journaled requests in order. Replay stops
+// at the first entry that still cannot reach the API; that entry and all
+// later ones stay in the journal. Entries rejected by the API are reported
+// as conflicts and dropped so they do not block the queue forever.
+func (c *Client) ReplayOfflineJournal(ctx context.Context, j *OfflineJournal) (*ReplayReport, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReplayReport{}
+	for i, e := range entries {
+		var query url.Values
+		if e.Query != "" {
+			if query, err = url.ParseQuery(e.Query); err != nil {
+				return nil, fmt.Errorf("invalid query in offline journal: %w", err)
+			}
+		}
+		var body any
+		if len(e.Body) > 0 {
+			body = e.Body
+		}
+
+		err := c.doRequest(context.WithValue(ctx, replayKey{}, true), e.Method, e.Path, query, body, nil)
+		switch {
+		case err == nil:
+			report.Applied = append(report.Applied, e)
+		case isNetworkError(ctx, err) || ctx.Err() != nil:
+			report.Pending = entries[i:]
+			if rerr := j.replace(report.Pending); rerr != nil {
+				return report, rerr
+			}
+			return report, err
+		case IsNotFound(err) && e.TaskID != "":
+			report.Conflicts = append(report.Conflicts, ReplayConflict{
+				Entry:  e,
+				Reason: fmt.Sprintf("task %s was deleted in the meantime", e.TaskID),
+				Err:    err,
+			})
+		default:
+			report.Conflicts = append(report.Conflicts, ReplayConflict{
+				Entry:  e,
+				Reason: "rejected by Habitica",
+				Err:    err,
+			})
+		}
+	}
+
+	return report, j.replace(nil)
+}
@@ -0,0 +1,117 @@
+package habitica
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_OfflineJournal_QueuesTaskMutations(t *testing.T) {
+	journal := OpenOfflineJournal(filepath.Join(t.TempDir(), "gohabitica", "offline-journal.jsonl"))
+
+	client, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {}, WithOfflineJournal(journal))
+	srv.Close() // the API is unreachable from now on
+
+	task, err := client.Tasks.CreateTask(context.Background(), &TaskCreateRequest{Text: "Offline todo", Type: TaskTypeTodo})
+	require.ErrorIs(t, err, ErrQueuedOffline)
+	require.Nil(t, task)
+
+	err = client.Tasks.DeleteTask(context.Background(), "task-1")
+	require.ErrorIs(t, err, ErrQueuedOffline)
+
+	// Reads are never journaled.
+	_, err = client.Tasks.GetTask(context.Background(), "task-1")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrQueuedOffline)
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, http.MethodPost, entries[0].Method)
+	require.Equal(t, "/tasks/user", entries[0].Path)
+	require.Empty(t, entries[0].TaskID)
+	require.JSONEq(t, `{"text":"Offline todo","type":"todo"}`, string(entries[0].Body))
+	require.Equal(t, http.MethodDelete, entries[1].Method)
+	require.Equal(t, UUID("task-1"), entries[1].TaskID)
+}
+
+func TestClient_ReplayOfflineJournal(t *testing.T) {
+	journal := OpenOfflineJournal(filepath.Join(t.TempDir(), "offline-journal.jsonl"))
+	for _, e := range []JournalEntry{
+		{Method: http.MethodPost, Path: "/tasks/user", Body: json.RawMessage(`{"text":"A","type":"todo"}`)},
+		{Method: http.MethodPost, Path: "/tasks/gone/score/up", TaskID: "gone"},
+		{Method: http.MethodPut, Path: "/tasks/task-2", TaskID: "task-2", Body: json.RawMessage(`{"text":"B"}`)},
+	} {
+		require.NoError(t, journal.Append(e))
+	}
+
+	var seen []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/tasks/gone/score/up" {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{Error: "NotFound", Message: "Task not found."})
+			return
+		}
+		if r.Method == http.MethodPut {
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "B", body["text"])
+		}
+		_ = json.NewEncoder(w).Encode(APIResponse[Task]{Success: true})
+	}
+
+	client, srv := newTestClient(t, handler, WithOfflineJournal(journal))
+	defer srv.Close()
+
+	report, err := client.ReplayOfflineJournal(context.Background(), journal)
+	require.NoError(t, err)
+	require.Equal(t, []string{"POST /tasks/user", "POST /tasks/gone/score/up", "PUT /tasks/task-2"}, seen)
+	require.Len(t, report.Applied, 2)
+	require.Len(t, report.Conflicts, 1)
+	require.Equal(t, UUID("gone"), report.Conflicts[0].Entry.TaskID)
+	require.Contains(t, report.Conflicts[0].Reason, "deleted")
+	require.Empty(t, report.Pending)
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestClient_ReplayOfflineJournal_StillOffline(t *testing.T) {
+	journal := OpenOfflineJournal(filepath.Join(t.TempDir(), "offline-journal.jsonl"))
+	require.NoError(t, journal.Append(JournalEntry{Method: http.MethodDelete, Path: "/tasks/task-1", TaskID: "task-1"}))
+	require.NoError(t, journal.Append(JournalEntry{Method: http.MethodDelete, Path: "/tasks/task-2", TaskID: "task-2"}))
+
+	client, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {}, WithOfflineJournal(journal))
+	srv.Close()
+
+	report, err := client.ReplayOfflineJournal(context.Background(), journal)
+	require.Error(t, err)
+	require.Len(t, report.Pending, 2)
+
+	entries, err := journal.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "entries must stay queued and must not be journaled twice")
+}
+
+func TestClient_Unreachable(t *testing.T) {
+	journal := OpenOfflineJournal(filepath.Join(t.TempDir(), "offline-journal.jsonl"))
+	offline, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {}, WithOfflineJournal(journal))
+	srv.Close()
+	online, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	srv.Close()
+
+	_, err := offline.Tags.ListTags(context.Background())
+	require.Error(t, err)
+	require.True(t, offline.Unreachable(context.Background(), err))
+	require.False(t, offline.Unreachable(context.Background(), nil))
+
+	_, err = online.Tags.ListTags(context.Background())
+	require.False(t, online.Unreachable(context.Background(), err))
+}
@@ -0,0 +1,191 @@
+package habitica
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Header names used by Habitica to report the request budget.
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+)
+
+// RateLimit describes the request budget as reported by Habitica via the
+// X-RateLimit-* response headers.
+type RateLimit struct {
+	// Limit is the number of requests allowed per window.
+	Limit int
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// Reset is the point in time when the current window ends.
+	Reset time.Time
+}
+
+// rateLimiter tracks the rate limit state of a client and pauses requests
+// once the budget of the current window is exhausted.
+type rateLimiter struct {
+	mu      sync.Mutex
+	state   RateLimit
+	known   bool
+	enabled bool
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		enabled: true,
+		now:     time.Now,
+		sleep:   sleepContext,
+	}
+}
+
+// snapshot returns a copy of the last known rate limit state.
+func (l *rateLimiter) snapshot() RateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// wait blocks until a request may be sent without exceeding the limit.
+// It reserves one request of the remaining budget so that concurrent callers
+// do not all pass on the last remaining slot.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	if !l.enabled || !l.known {
+		l.mu.Unlock()
+		return nil
+	}
+
+	now := l.now()
+	var d time.Duration
+	switch {
+	case !l.state.Reset.IsZero() && !now.Before(l.state.Reset):
+		// The window has passed; assume a fresh budget until the next response says otherwise.
+		l.state.Remaining = l.state.Limit
+	case l.state.Remaining <= 0 && !l.state.Reset.IsZero():
+		d = l.state.Reset.Sub(now)
+	}
+	if d <= 0 && l.state.Remaining > 0 {
+		l.state.Remaining--
+	}
+	l.mu.Unlock()
+
+	if d <= 0 {
+		return nil
+	}
+	return l.sleep(ctx, d)
+}
+
+// update records the rate limit state reported by a response.
+func (l *rateLimiter) update(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h := resp.Header
+	if v, err := strconv.Atoi(strings.TrimSpace(h.Get(headerRateLimitLimit))); err == nil {
+		l.state.Limit = v
+		l.known = true
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(h.Get(headerRateLimitRemaining))); err == nil {
+		l.state.Remaining = v
+		l.known = true
+	}
+	if t, ok := parseRateLimitReset(h.Get(headerRateLimitReset)); ok {
+		l.state.Reset = t
+		l.known = true
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		l.known = true
+		l.state.Remaining = 0
+		if d, ok := parseRetryAfter(h.Get(headerRetryAfter), l.now()); ok {
+			if reset := l.now().Add(d); reset.After(l.state.Reset) {
+				l.state.Reset = reset
+			}
+		}
+	}
+}
+
+// parseRateLimitReset parses the X-RateLimit-Reset header. Habitica sends a
+// JavaScript Date string (e.g. "Wed Apr 19 2023 12:00:00 GMT+0000 (Coordinated
+// Universal Time)"); HTTP dates, RFC 3339 and epoch seconds/millis are accepted as well.
+func parseRateLimitReset(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+	if i := strings.Index(v, " ("); i > 0 {
+		v = v[:i]
+	}
+
+	layouts := []string{
+		"Mon Jan 02 2006 15:04:05 GMT-0700",
+		time.RFC1123,
+		time.RFC1123Z,
+		time.RFC3339Nano,
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		// Heuristic: values that large cannot be seconds in any sensible range.
+		if n > 1e11 {
+			return time.UnixMilli(n), true
+		}
+		return time.Unix(n, 0), true
+	}
+	return time.Time{}, false
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done, whichever happens first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+package habitica
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RateLimit_TracksHeaders(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Remaining", "29")
+		w.Header().Set("X-RateLimit-Reset", "Wed Apr 19 2023 12:00:00 GMT+0000 (Coordinated Universal Time)")
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{Success: true})
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	require.Equal(t, RateLimit{}, client.RateLimit())
+
+	err := client.doRequest(context.Background(), http.MethodGet, "/test", nil, nil, nil)
+	require.NoError(t, err)
+
+	rl := client.RateLimit()
+	require.Equal(t, 30, rl.Limit)
+	require.Equal(t, 29, rl.Remaining)
+	require.True(t, rl.Reset.Equal(time.Date(2023, time.April, 19, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestClient_RateLimit_PausesWhenExhausted(t *testing.T) {
+	reset := time.Now().Add(time.Minute).UTC()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", reset.Format(time.RFC1123))
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{Success: true})
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	var waited []time.Duration
+	client.rateLimiter.sleep = func(ctx context.Context, d time.Duration) error {
+		waited = append(waited, d)
+		return nil
+	}
+
+	require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/test", nil, nil, nil))
+	require.Empty(t, waited, "first request must not wait without known state")
+
+	require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/test", nil, nil, nil))
+	require.Len(t, waited, 1)
+	require.Greater(t, waited[0], 30*time.Second)
+	require.LessOrEqual(t, waited[0], time.Minute)
+}
+
+func TestClient_RateLimit_RetryAfterOn429(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "20")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{
+			Success: false,
+			Error:   "TooManyRequests",
+			Message: "Too many requests.",
+		})
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	err := client.doRequest(context.Background(), http.MethodGet, "/test", nil, nil, nil)
+	require.Error(t, err)
+
+	rl := client.RateLimit()
+	require.Equal(t, 0, rl.Remaining)
+	require.WithinDuration(t, time.Now().Add(20*time.Second), rl.Reset, 5*time.Second)
+}
+
+func TestClient_RateLimit_CanBeDisabled(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", time.Now().Add(time.Minute).UTC().Format(time.RFC1123))
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{Success: true})
+	}
+
+	client, srv := newTestClient(t, handler, WithRateLimiting(false))
+	defer srv.Close()
+
+	client.rateLimiter.sleep = func(ctx context.Context, d time.Duration) error {
+		t.Fatalf("unexpected pause of %s", d)
+		return nil
+	}
+
+	require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/test", nil, nil, nil))
+	require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/test", nil, nil, nil))
+	require.Equal(t, 0, client.RateLimit().Remaining)
+}
+
+func TestParseRateLimitReset(t *testing.T) {
+	want := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, in := range []string{
+		"Tue Jan 02 2024 03:04:05 GMT+0000 (Coordinated Universal Time)",
+		"Tue, 02 Jan 2024 03:04:05 GMT",
+		"2024-01-02T03:04:05Z",
+		"1704164645",
+		"1704164645000",
+	} {
+		got, ok := parseRateLimitReset(in)
+		require.True(t, ok, in)
+		require.True(t, want.Equal(got), "%s: got %s", in, got)
+	}
+
+	_, ok := parseRateLimitReset("soon")
+	require.False(t, ok)
+}
@@ -0,0 +1,108 @@
+package habitica
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// RetryPolicy controls how transient failures (network errors, 429 and 5xx
+// responses) are retried by the client.
+//
+// Only idempotent methods (GET, HEAD, OPTIONS, PUT, DELETE) are retried by
+// default. Scoring a task is a POST that is not safe to repeat blindly, so
+// it has to be opted in explicitly via RetryScoring.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values <= 1 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles on every further attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay. Zero means no cap.
+	MaxDelay time.Duration
+	// RetryScoring opts the POST scoring endpoints (tasks and checklist items) into retries.
+	RetryScoring bool
+}
+
+// DefaultRetryPolicy returns a sensible policy for interactive tools and batch scripts.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// WithRetryPolicy configures automatic retries. Without this option the
+// client does not retry failed requests.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retrier.policy = p
+	}
+}
+
+var scoringPath = regexp.MustCompile(`^/?tasks/[^/]+/(score/(up|down)|checklist/[^/]+/score)$`)
+
+// retrier decides whether and when a failed attempt is repeated.
+type retrier struct {
+	policy RetryPolicy
+
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func() float64
+}
+
+func newRetrier() *retrier {
+	return &retrier{
+		sleep:  sleepContext,
+		jitter: rand.Float64,
+	}
+}
+
+// retryable reports whether a request may be repeated after the given attempt.
+func (r *retrier) retryable(ctx context.Context, method, p string, attempt int, resp *http.Response, err error) bool {
+	if attempt >= r.policy.MaxAttempts {
+		return false
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	case http.MethodPost:
+		if !r.policy.RetryScoring || !scoringPath.MatchString(p) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// delay computes how long to wait before the next attempt. A Retry-After
+// header takes precedence over the exponential backoff.
+func (r *retrier) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get(headerRetryAfter), time.Now()); ok {
+			return d
+		}
+	}
+
+	d := r.policy.BaseDelay << (attempt - 1)
+	if d <= 0 || (r.policy.MaxDelay > 0 && d > r.policy.MaxDelay) {
+		d = r.policy.MaxDelay
+	}
+	// Equal jitter: keep at least half of the delay to preserve the backoff.
+	return d/2 + time.Duration(r.jitter()*float64(d/2))
+}
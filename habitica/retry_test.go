@@ -0,0 +1,154 @@
+package habitica
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// noSleep replaces the backoff sleep of a client and records the requested delays.
+func noSleep(c *Client) *[]time.Duration {
+	var delays []time.Duration
+	c.retrier.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return &delays
+}
+
+// flakyHandler fails the first n requests with the given status and succeeds afterwards.
+func flakyHandler(n int32, status int, calls *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(calls, 1) <= n {
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{
+				Success: false,
+				Error:   "InternalServerError",
+				Message: "An unexpected error occurred.",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{Success: true})
+	}
+}
+
+func TestClient_Retry_IdempotentRequest(t *testing.T) {
+	var calls int32
+	client, srv := newTestClient(t, flakyHandler(2, http.StatusBadGateway, &calls), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    time.Second,
+	}))
+	defer srv.Close()
+	client.retrier.jitter = func() float64 { return 1 }
+	delays := noSleep(client)
+
+	err := client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, atomic.LoadInt32(&calls))
+	require.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *delays)
+}
+
+func TestClient_Retry_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	client, srv := newTestClient(t, flakyHandler(10, http.StatusServiceUnavailable, &calls), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}))
+	defer srv.Close()
+	noSleep(client)
+
+	err := client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil)
+	require.Error(t, err)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	require.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestClient_Retry_NoRetryForClientErrors(t *testing.T) {
+	var calls int32
+	client, srv := newTestClient(t, flakyHandler(1, http.StatusBadRequest, &calls), WithRetryPolicy(DefaultRetryPolicy()))
+	defer srv.Close()
+	noSleep(client)
+
+	err := client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil)
+	require.Error(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestClient_Retry_PostOnlyWhenScoringOptedIn(t *testing.T) {
+	var calls int32
+	client, srv := newTestClient(t, flakyHandler(1, http.StatusInternalServerError, &calls), WithRetryPolicy(DefaultRetryPolicy()))
+	defer srv.Close()
+	noSleep(client)
+
+	err := client.doRequest(context.Background(), http.MethodPost, "/tasks/task-1/score/up", nil, nil, nil)
+	require.Error(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	policy := DefaultRetryPolicy()
+	policy.RetryScoring = true
+	calls = 0
+	client, srv2 := newTestClient(t, flakyHandler(1, http.StatusInternalServerError, &calls), WithRetryPolicy(policy))
+	defer srv2.Close()
+	noSleep(client)
+
+	err = client.doRequest(context.Background(), http.MethodPost, "/tasks/task-1/score/up", nil, nil, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	// Other POST endpoints stay excluded even with scoring retries enabled.
+	calls = 0
+	err = client.doRequest(context.Background(), http.MethodPost, "/tasks/user", nil, nil, nil)
+	require.Error(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestClient_Retry_HonorsRetryAfter(t *testing.T) {
+	var calls int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{Success: true})
+	}
+
+	client, srv := newTestClient(t, handler, WithRetryPolicy(DefaultRetryPolicy()))
+	defer srv.Close()
+	delays := noSleep(client)
+	client.rateLimiter.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil))
+	require.Equal(t, []time.Duration{7 * time.Second}, *delays)
+}
+
+func TestClient_Retry_RespectsContextDeadline(t *testing.T) {
+	var calls int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	client, srv := newTestClient(t, handler, WithRetryPolicy(DefaultRetryPolicy()))
+	defer srv.Close()
+	delays := noSleep(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := client.doRequest(ctx, http.MethodGet, "/user", nil, nil, nil)
+	require.Error(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+	require.Empty(t, *delays)
+}
@@ -3,26 +3,135 @@ package habitica
 import (
 	"context"
 	"encoding/json"
+	"flag"
+	"io"
 	"net/http"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
+var updateContent = flag.Bool("update-content", false, "record testdata/content.json from the live /content endpoint")
+
+// contentFixtureKeys lists the entries of the /content response kept in
+// testdata/content.json, by the dotted path of their section.
+var contentFixtureKeys = map[string][]string{
+	"gear.flat": {
+		"weapon_warrior_0", "weapon_warrior_1", "weapon_rogue_1", "weapon_rogue_2", "weapon_rogue_6",
+		"armor_rogue_1", "weapon_wizard_1", "weapon_special_winter2024Rogue", "head_armoire_lunarCrown",
+	},
+	"eggs":                          {"Wolf", "Gryphon"},
+	"hatchingPotions":               {"Base", "Golden", "Aurora"},
+	"food":                          {"Meat", "Saddle"},
+	"quests":                        {"dilatory", "wolf", "atom1"},
+	"spells.wizard":                 {"fireball"},
+	"spells.warrior":                {"smash"},
+	"spells.rogue":                  {"stealth"},
+	"spells.healer":                 {"heal"},
+	"spells.special":                {"snowball"},
+	"backgrounds.backgrounds062014": {"beach", "fairy_ring"},
+	"backgroundsFlat":               {"beach", "fairy_ring"},
+	"pets":                          {"Wolf-Base", "Wolf-Golden", "Gryphon-Base"},
+	"mounts":                        {"Wolf-Base", "Wolf-Golden", "Gryphon-Base"},
+	"questPets":                     {"MantisShrimp-Base"},
+	"premiumPets":                   {"Wolf-Aurora"},
+	"specialPets":                   {"Wolf-Veteran", "Wolf-Cerberus"},
+	"specialMounts":                 {"BearCub-Polar"},
+}
+
+// trimContent returns the entries of contentFixtureKeys from decoded
+// /content data.
+func trimContent(data map[string]any) map[string]any {
+	out := make(map[string]any)
+	for path, keys := range contentFixtureKeys {
+		src, dst := data, out
+		for _, name := range strings.Split(path, ".") {
+			next, _ := src[name].(map[string]any)
+			src = next
+			if dst[name] == nil {
+				dst[name] = make(map[string]any)
+			}
+			dst = dst[name].(map[string]any)
+		}
+		for _, key := range keys {
+			if v, ok := src[key]; ok {
+				dst[key] = v
+			}
+		}
+	}
+	return out
+}
+
+// TestUpdateContentFixture decodes the live /content response and stores a
+// trimmed copy in testdata/content.json, which the other tests read:
+//
+//	go test ./habitica -run TestUpdateContentFixture -update-content
+func TestUpdateContentFixture(t *testing.T) {
+	if !*updateContent {
+		t.Skip("run with -update-content to record testdata/content.json")
+	}
+
+	httpClient := &http.Client{Timeout: time.Minute}
+	resp, err := httpClient.Get("https://habitica.com/api/v3/content")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	// The complete response must decode, not just the kept entries.
+	var typed APIResponse[Content]
+	require.NoError(t, json.Unmarshal(raw, &typed))
+	var env APIResponse[map[string]any]
+	require.NoError(t, json.Unmarshal(raw, &env))
+
+	out, err := json.MarshalIndent(map[string]any{"success": true, "data": trimContent(env.Data)}, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile("testdata/content.json", append(out, '\n'), 0o644))
+}
+
+func TestTrimContent(t *testing.T) {
+	var env APIResponse[map[string]any]
+	require.NoError(t, json.Unmarshal(loadContentFixture(t), &env))
+	env.Data["achievements"] = map[string]any{"streak": map[string]any{"icon": "achievement-thermometer"}}
+	env.Data["eggs"].(map[string]any)["Cactus"] = map[string]any{"key": "Cactus"}
+
+	trimmed := trimContent(env.Data)
+	require.NotContains(t, trimmed, "achievements")
+	require.NotContains(t, trimmed["eggs"], "Cactus")
+	require.Contains(t, trimmed["eggs"], "Wolf")
+
+	raw, err := json.Marshal(trimmed)
+	require.NoError(t, err)
+	var c Content
+	require.NoError(t, json.Unmarshal(raw, &c))
+	require.Equal(t, "Sword", c.Gear("weapon_warrior_1").Text)
+	require.Equal(t, "Healing Light", c.Spell("heal").Text)
+	require.Equal(t, "Beach", c.Background("beach").Text)
+	require.True(t, c.HasPet("Wolf-Veteran"))
+}
+
+// loadContentFixture returns the trimmed /content response stored in
+// testdata. The entries were written by hand in the format
+// TestUpdateContentFixture produces and have not been recorded from the
+// live API yet; run it with -update-content to replace them.
+func loadContentFixture(t *testing.T) []byte {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/content.json")
+	require.NoError(t, err)
+	return raw
+}
+
 func TestContentService_GetContent(t *testing.T) {
+	fixture := loadContentFixture(t)
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		require.Equal(t, "/content", r.URL.Path)
 
-		resp := APIResponse[Content]{
-			Success: true,
-			Data: Content{
-				Items: map[string]any{
-					"weapon_warrior_0": map[string]any{"text": "Training Sword"},
-				},
-			},
-		}
 		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
+		_, _ = w.Write(fixture)
 	}
 
 	client, srv := newTestClient(t, handler)
@@ -31,6 +140,67 @@ func TestContentService_GetContent(t *testing.T) {
 	c, err := client.Content.GetContent(context.Background())
 	require.NoError(t, err)
 	require.NotNil(t, c)
-	require.Contains(t, c.Items, "weapon_warrior_0")
+
+	sword := c.Gear("weapon_warrior_1")
+	require.NotNil(t, sword)
+	require.Equal(t, "Sword", sword.Text)
+	require.Equal(t, "weapon", sword.Type)
+	require.Equal(t, "warrior", sword.Class())
+	require.Equal(t, "warrior-1", sword.Set)
+	require.Equal(t, 3.0, sword.Str)
+	require.Equal(t, 20.0, sword.Value)
+	require.Nil(t, c.Gear("weapon_unknown"))
+
+	seasonal := c.Gear("weapon_special_winter2024Rogue")
+	require.Equal(t, "rogue", seasonal.Class())
+	require.NotNil(t, seasonal.Event)
+	// Event bounds are dates or full timestamps, e.g. 2024-01-31T23:59-05:00.
+	require.True(t, strings.HasPrefix(string(seasonal.Event.End), "2024-01-31"), seasonal.Event.End)
+
+	require.True(t, c.Gear("weapon_wizard_1").TwoHanded)
+
+	require.Equal(t, "a loyal", c.Egg("Wolf").Adjective)
+	require.Equal(t, 3.0, c.Egg("Wolf").Value)
+	require.True(t, c.HatchingPotion("Aurora").Premium)
+	require.Equal(t, "Base", c.FoodItem("Meat").Target)
+	require.False(t, c.FoodItem("Saddle").CanDrop)
+
+	wolf := c.Quest("wolf")
+	require.NotNil(t, wolf.Boss)
+	require.Equal(t, 300.0, wolf.Boss.HP)
+	require.Len(t, wolf.Drop.Items, 3)
+	require.Equal(t, "eggs", wolf.Drop.Items[0].Type)
+	require.Equal(t, 200.0, wolf.Drop.Exp)
+
+	atom := c.Quest("atom1")
+	require.Nil(t, atom.Boss)
+	require.Equal(t, 20, atom.Collect["soapBars"].Count)
+	require.Equal(t, 15, atom.Lvl)
+	require.Equal(t, 100.0, atom.GoldValue)
+
+	require.Equal(t, 4000000.0, c.Quest("dilatory").Boss.Rage.Value)
+
+	require.Equal(t, 10.0, c.Spell("fireball").Mana)
+	require.Equal(t, "self", c.ClassSpells("rogue")["stealth"].Target)
+	require.Equal(t, 15.0, c.Spell("snowball").Value)
+	require.Nil(t, c.Spell("unknown"))
+
+	require.Equal(t, "Beach", c.Background("beach").Text)
+	require.Equal(t, "backgrounds062014", c.Background("fairy_ring").Set)
+
+	require.True(t, c.HasPet("Wolf-Base"))
+	require.True(t, c.HasPet("MantisShrimp-Base"))
+	require.True(t, c.HasPet("Wolf-Veteran"))
+	require.False(t, c.HasPet("Wolf-Nope"))
+	require.True(t, c.HasMount("BearCub-Polar"))
 }
 
+func TestContent_BackgroundWithoutFlatIndex(t *testing.T) {
+	c := &Content{
+		Backgrounds: map[string]map[string]*Background{
+			"backgrounds072014": {"sunken_ship": {Key: "sunken_ship", Text: "Sunken Ship"}},
+		},
+	}
+	require.Equal(t, "Sunken Ship", c.Background("sunken_ship").Text)
+	require.Nil(t, c.Background("beach"))
+}
@@ -2,6 +2,8 @@ package habitica
 
 import (
 	"context"
+	"fmt"
+	"strings"
 )
 
 // TagsService wraps tag-related endpoints.
@@ -18,3 +20,94 @@ func (s *TagsService) ListTags(ctx context.Context) ([]*Tag, error) {
 	return tags, nil
 }
 
+// CreateTag creates a new tag (POST /tags).
+func (s *TagsService) CreateTag(ctx context.Context, name string) (*Tag, error) {
+	payload := Tag{Name: name}
+	var tag Tag
+	if err := s.client.doRequest(ctx, "POST", "/tags", nil, &payload, &tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// RenameTag changes the name of a tag (PUT /tags/:tagId).
+func (s *TagsService) RenameTag(ctx context.Context, id UUID, name string) (*Tag, error) {
+	payload := Tag{Name: name}
+	var tag Tag
+	if err := s.client.doRequest(ctx, "PUT", fmt.Sprintf("/tags/%s", id), nil, &payload, &tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// DeleteTag deletes a tag and removes it from all tasks (DELETE /tags/:tagId).
+func (s *TagsService) DeleteTag(ctx context.Context, id UUID) error {
+	return s.client.doRequest(ctx, "DELETE", fmt.Sprintf("/tags/%s", id), nil, nil, nil)
+}
+
+// ReorderTag moves a tag to the given 0-based position (POST /reorder-tags).
+func (s *TagsService) ReorderTag(ctx context.Context, id UUID, to int) error {
+	payload := struct {
+		TagID UUID `json:"tagId"`
+		To    int  `json:"to"`
+	}{
+		TagID: id,
+		To:    to,
+	}
+	return s.client.doRequest(ctx, "POST", "/reorder-tags", nil, &payload, nil)
+}
+
+// AddTagToTask assigns a tag to a task (POST /tasks/:taskId/tags/:tagId).
+func (s *TagsService) AddTagToTask(ctx context.Context, taskID, tagID UUID) (*Task, error) {
+	var t Task
+	if err := s.client.doRequest(ctx, "POST", fmt.Sprintf("/tasks/%s/tags/%s", taskID, tagID), nil, nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RemoveTagFromTask removes a tag from a task (DELETE /tasks/:taskId/tags/:tagId).
+func (s *TagsService) RemoveTagFromTask(ctx context.Context, taskID, tagID UUID) (*Task, error) {
+	var t Task
+	if err := s.client.doRequest(ctx, "DELETE", fmt.Sprintf("/tasks/%s/tags/%s", taskID, tagID), nil, nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ResolveTags maps tag names (case-insensitive) or tag IDs to tag IDs,
+// preserving the order of names. Unknown names are created when create is
+// true and reported as an error otherwise.
+func (s *TagsService) ResolveTags(ctx context.Context, names []string, create bool) ([]UUID, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	tags, err := s.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := NewTagIndex(tags)
+
+	ids := make([]UUID, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if tag := index.Lookup(name); tag != nil {
+			ids = append(ids, tag.ID)
+			continue
+		}
+		if !create {
+			return nil, fmt.Errorf("unknown tag %q", name)
+		}
+		tag, err := s.CreateTag(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("creating tag %q: %w", name, err)
+		}
+		index.add(tag)
+		ids = append(ids, tag.ID)
+	}
+	return ids, nil
+}
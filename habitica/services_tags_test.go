@@ -32,3 +32,93 @@ func TestTagsService_ListTags(t *testing.T) {
 	require.Equal(t, UUID("tag-1"), tags[0].ID)
 }
 
+func TestTagsService_CRUD(t *testing.T) {
+	type call struct {
+		method, path string
+		body         map[string]any
+	}
+	var calls []call
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if r.ContentLength > 0 {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		}
+		calls = append(calls, call{r.Method, r.URL.Path, body})
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/tags" || r.URL.Path == "/tags/tag-1":
+			_ = json.NewEncoder(w).Encode(APIResponse[Tag]{Success: true, Data: Tag{ID: "tag-1", Name: "Work"}})
+		case r.URL.Path == "/tasks/task-1/tags/tag-1":
+			_ = json.NewEncoder(w).Encode(APIResponse[Task]{Success: true, Data: Task{ID: "task-1", Tags: []UUID{"tag-1"}}})
+		default:
+			_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{Success: true})
+		}
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+	ctx := context.Background()
+
+	tag, err := client.Tags.CreateTag(ctx, "Work")
+	require.NoError(t, err)
+	require.Equal(t, UUID("tag-1"), tag.ID)
+
+	_, err = client.Tags.RenameTag(ctx, "tag-1", "Job")
+	require.NoError(t, err)
+	require.NoError(t, client.Tags.ReorderTag(ctx, "tag-1", 0))
+
+	task, err := client.Tags.AddTagToTask(ctx, "task-1", "tag-1")
+	require.NoError(t, err)
+	require.Equal(t, []UUID{"tag-1"}, task.Tags)
+	_, err = client.Tags.RemoveTagFromTask(ctx, "task-1", "tag-1")
+	require.NoError(t, err)
+	require.NoError(t, client.Tags.DeleteTag(ctx, "tag-1"))
+
+	require.Equal(t, []call{
+		{http.MethodPost, "/tags", map[string]any{"name": "Work"}},
+		{http.MethodPut, "/tags/tag-1", map[string]any{"name": "Job"}},
+		{http.MethodPost, "/reorder-tags", map[string]any{"tagId": "tag-1", "to": float64(0)}},
+		{http.MethodPost, "/tasks/task-1/tags/tag-1", nil},
+		{http.MethodDelete, "/tasks/task-1/tags/tag-1", nil},
+		{http.MethodDelete, "/tags/tag-1", nil},
+	}, calls)
+}
+
+func TestTagsService_ResolveTags(t *testing.T) {
+	var created []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/tags", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodPost {
+			var body Tag
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			created = append(created, body.Name)
+			_ = json.NewEncoder(w).Encode(APIResponse[Tag]{Success: true, Data: Tag{ID: "tag-new", Name: body.Name}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(APIResponse[[]*Tag]{
+			Success: true,
+			Data:    []*Tag{{ID: "tag-1", Name: "Work"}, {ID: "tag-2", Name: "Home"}},
+		})
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+	ctx := context.Background()
+
+	ids, err := client.Tags.ResolveTags(ctx, []string{"home", "tag-1"}, false)
+	require.NoError(t, err)
+	require.Equal(t, []UUID{"tag-2", "tag-1"}, ids)
+
+	_, err = client.Tags.ResolveTags(ctx, []string{"errands"}, false)
+	require.ErrorContains(t, err, `unknown tag "errands"`)
+	require.Empty(t, created)
+
+	ids, err = client.Tags.ResolveTags(ctx, []string{"Work", "Errands", "errands"}, true)
+	require.NoError(t, err)
+	require.Equal(t, []UUID{"tag-1", "tag-new", "tag-new"}, ids)
+	require.Equal(t, []string{"Errands"}, created)
+}
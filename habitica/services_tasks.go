@@ -72,12 +72,17 @@ func (s *TasksService) CreateTodoWithChecklist(ctx context.Context, text string,
 }
 
 // ScoreTask scores a task in the given direction ("up" or "down").
-// For todos, "up" marks the task as completed.
-func (s *TasksService) ScoreTask(ctx context.Context, id UUID, direction string) error {
+// For todos, "up" marks the task as completed. The result holds the user's
+// stats after scoring together with drops, quest progress and crits.
+func (s *TasksService) ScoreTask(ctx context.Context, id UUID, direction string) (*ScoreResult, error) {
 	if direction != "up" && direction != "down" {
-		return fmt.Errorf("direction must be \"up\" or \"down\"")
+		return nil, fmt.Errorf("direction must be \"up\" or \"down\"")
 	}
-	return s.client.doRequest(ctx, "POST", fmt.Sprintf("/tasks/%s/score/%s", id, direction), nil, nil, nil)
+	var res ScoreResult
+	if err := s.client.doRequest(ctx, "POST", fmt.Sprintf("/tasks/%s/score/%s", id, direction), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
 }
 
 // UpdateChecklistItemCompleted sets the completion state of a checklist item belonging to a task.
@@ -90,6 +95,83 @@ func (s *TasksService) UpdateChecklistItemCompleted(ctx context.Context, taskID,
 	return s.client.doRequest(ctx, "PUT", fmt.Sprintf("/tasks/%s/checklist/%s", taskID, itemID), nil, &payload, nil)
 }
 
+// AddChecklistItem appends a checklist item to a todo or daily (POST /tasks/:id/checklist).
+func (s *TasksService) AddChecklistItem(ctx context.Context, taskID UUID, text string) (*Task, error) {
+	payload := ChecklistItem{Text: text}
+	var t Task
+	if err := s.client.doRequest(ctx, "POST", fmt.Sprintf("/tasks/%s/checklist", taskID), nil, &payload, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RenameChecklistItem changes the text of a checklist item (PUT /tasks/:id/checklist/:itemId).
+func (s *TasksService) RenameChecklistItem(ctx context.Context, taskID, itemID UUID, text string) (*Task, error) {
+	payload := struct {
+		Text string `json:"text"`
+	}{
+		Text: text,
+	}
+	var t Task
+	if err := s.client.doRequest(ctx, "PUT", fmt.Sprintf("/tasks/%s/checklist/%s", taskID, itemID), nil, &payload, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteChecklistItem removes a checklist item (DELETE /tasks/:id/checklist/:itemId).
+func (s *TasksService) DeleteChecklistItem(ctx context.Context, taskID, itemID UUID) (*Task, error) {
+	var t Task
+	if err := s.client.doRequest(ctx, "DELETE", fmt.Sprintf("/tasks/%s/checklist/%s", taskID, itemID), nil, nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ScoreChecklistItem toggles the completion of a checklist item
+// (POST /tasks/:id/checklist/:itemId/score).
+func (s *TasksService) ScoreChecklistItem(ctx context.Context, taskID, itemID UUID) (*Task, error) {
+	var t Task
+	if err := s.client.doRequest(ctx, "POST", fmt.Sprintf("/tasks/%s/checklist/%s/score", taskID, itemID), nil, nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MoveChecklistItem moves a checklist item to the given 0-based position.
+// Habitica has no endpoint for this, so the task is fetched and its
+// reordered checklist is written back with UpdateTask.
+func (s *TasksService) MoveChecklistItem(ctx context.Context, taskID, itemID UUID, position int) (*Task, error) {
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := -1
+	for i, item := range task.Checklist {
+		if item.ID == itemID {
+			from = i
+			break
+		}
+	}
+	if from < 0 {
+		return nil, fmt.Errorf("checklist item %s not found on task %s", itemID, taskID)
+	}
+	if position < 0 || position >= len(task.Checklist) {
+		return nil, fmt.Errorf("position %d is out of range (0-%d)", position, len(task.Checklist)-1)
+	}
+	if position == from {
+		return task, nil
+	}
+
+	checklist := make([]ChecklistItem, 0, len(task.Checklist))
+	checklist = append(checklist, task.Checklist[:from]...)
+	checklist = append(checklist, task.Checklist[from+1:]...)
+	checklist = append(checklist[:position], append([]ChecklistItem{task.Checklist[from]}, checklist[position:]...)...)
+
+	return s.UpdateTask(ctx, taskID, &TaskUpdateRequest{Checklist: &checklist})
+}
+
 // UpdateTask updates an existing task (PUT /tasks/:id).
 func (s *TasksService) UpdateTask(ctx context.Context, id UUID, in *TaskUpdateRequest) (*Task, error) {
 	var t Task
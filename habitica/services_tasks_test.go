@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -106,23 +107,62 @@ func TestTasksService_CreateTodoWithChecklist(t *testing.T) {
 }
 
 func TestTasksService_ScoreTask(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/score_up.json")
+	require.NoError(t, err)
+
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		require.Equal(t, "/tasks/task-id-4/score/up", r.URL.Path)
 		require.Equal(t, http.MethodPost, r.Method)
 
-		resp := APIResponse[struct{}]{
-			Success: true,
-			Data:    struct{}{},
-		}
 		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
+		_, _ = w.Write(fixture)
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	res, err := client.Tasks.ScoreTask(context.Background(), UUID("task-id-4"), "up")
+	require.NoError(t, err)
+	require.Equal(t, 1.0, res.Delta)
+	require.Equal(t, 12, res.Lvl)
+	require.Equal(t, 1.52, res.Tmp.Crit)
+	require.Equal(t, 8.25, res.Tmp.Quest.ProgressDelta)
+	require.Equal(t, "Wolf Egg", res.Tmp.Drop.Name())
+	require.Equal(t, &ScoreLevelUp{InitialLvl: 11, NewLvl: 12}, res.Tmp.LeveledUp)
+
+	d := res.Diff(&UserStats{HP: 50, MP: 30, Exp: 260, GP: 125, Lvl: 11, ToNextLevel: 270})
+	require.InDelta(t, 14, d.Exp, 1e-9)
+	require.InDelta(t, 3.4, d.GP, 1e-9)
+	require.InDelta(t, 1.4, d.MP, 1e-9)
+	require.Zero(t, d.HP)
+	require.Equal(t, 1, d.Levels)
+}
+
+func TestTasksService_ScoreTask_Down(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/tasks/task-id-4/score/down", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"delta":-1.2,"_tmp":{},"hp":46.5,"mp":10,"exp":20,"gp":5,"lvl":3}}`))
 	}
 
 	client, srv := newTestClient(t, handler)
 	defer srv.Close()
 
-	err := client.Tasks.ScoreTask(context.Background(), UUID("task-id-4"), "up")
+	res, err := client.Tasks.ScoreTask(context.Background(), UUID("task-id-4"), "down")
 	require.NoError(t, err)
+	require.Nil(t, res.Tmp.Drop)
+	require.Nil(t, res.Tmp.LeveledUp)
+	require.InDelta(t, -3.5, res.Diff(&UserStats{HP: 50, MP: 10, Exp: 20, GP: 5, Lvl: 3}).HP, 1e-9)
+
+	_, err = client.Tasks.ScoreTask(context.Background(), UUID("task-id-4"), "sideways")
+	require.Error(t, err)
+}
+
+func TestScoreLevelUp_List(t *testing.T) {
+	var l ScoreLevelUp
+	require.NoError(t, json.Unmarshal([]byte(`[{"initialLvl":4,"newLvl":5},{"initialLvl":5,"newLvl":6}]`), &l))
+	require.Equal(t, ScoreLevelUp{InitialLvl: 4, NewLvl: 6}, l)
 }
 
 func TestTasksService_ScoreChecklistItem(t *testing.T) {
@@ -155,3 +195,98 @@ func TestTasksService_ScoreChecklistItem(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestTasksService_ChecklistCRUD(t *testing.T) {
+	type call struct {
+		method, path string
+		body         map[string]any
+	}
+	var calls []call
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if r.ContentLength > 0 {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		}
+		calls = append(calls, call{r.Method, r.URL.Path, body})
+
+		resp := APIResponse[Task]{
+			Success: true,
+			Data: Task{
+				ID:        "task-id-6",
+				Type:      TaskTypeTodo,
+				Checklist: []ChecklistItem{{ID: "item-id-1", Text: "Milk"}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+	ctx := context.Background()
+
+	task, err := client.Tasks.AddChecklistItem(ctx, "task-id-6", "Milk")
+	require.NoError(t, err)
+	require.Len(t, task.Checklist, 1)
+
+	_, err = client.Tasks.RenameChecklistItem(ctx, "task-id-6", "item-id-1", "Oat milk")
+	require.NoError(t, err)
+	_, err = client.Tasks.ScoreChecklistItem(ctx, "task-id-6", "item-id-1")
+	require.NoError(t, err)
+	_, err = client.Tasks.DeleteChecklistItem(ctx, "task-id-6", "item-id-1")
+	require.NoError(t, err)
+
+	require.Equal(t, []call{
+		{http.MethodPost, "/tasks/task-id-6/checklist", map[string]any{"text": "Milk"}},
+		{http.MethodPut, "/tasks/task-id-6/checklist/item-id-1", map[string]any{"text": "Oat milk"}},
+		{http.MethodPost, "/tasks/task-id-6/checklist/item-id-1/score", nil},
+		{http.MethodDelete, "/tasks/task-id-6/checklist/item-id-1", nil},
+	}, calls)
+}
+
+func TestTasksService_MoveChecklistItem(t *testing.T) {
+	checklist := []ChecklistItem{
+		{ID: "a", Text: "First"},
+		{ID: "b", Text: "Second"},
+		{ID: "c", Text: "Third", Completed: true},
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/tasks/task-id-7", r.URL.Path)
+
+		if r.Method == http.MethodPut {
+			var body TaskUpdateRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.NotNil(t, body.Checklist)
+			checklist = *body.Checklist
+		}
+
+		resp := APIResponse[Task]{
+			Success: true,
+			Data:    Task{ID: "task-id-7", Checklist: checklist},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+	ctx := context.Background()
+
+	task, err := client.Tasks.MoveChecklistItem(ctx, "task-id-7", "c", 0)
+	require.NoError(t, err)
+	require.Equal(t, []ChecklistItem{
+		{ID: "c", Text: "Third", Completed: true},
+		{ID: "a", Text: "First"},
+		{ID: "b", Text: "Second"},
+	}, task.Checklist)
+
+	task, err = client.Tasks.MoveChecklistItem(ctx, "task-id-7", "c", 2)
+	require.NoError(t, err)
+	require.Equal(t, UUID("c"), task.Checklist[2].ID)
+
+	_, err = client.Tasks.MoveChecklistItem(ctx, "task-id-7", "c", 3)
+	require.Error(t, err)
+	_, err = client.Tasks.MoveChecklistItem(ctx, "task-id-7", "missing", 0)
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package habitica
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 )
@@ -20,6 +21,34 @@ func (s *UserService) GetCurrent(ctx context.Context) (*User, error) {
 	return &u, nil
 }
 
+// GetStats fetches only the stats of the current user (GET /user?userFields=stats).
+func (s *UserService) GetStats(ctx context.Context) (*UserStats, error) {
+	q := url.Values{}
+	q.Set("userFields", "stats")
+	var u User
+	if err := s.client.doRequest(ctx, "GET", "/user", q, nil, &u); err != nil {
+		return nil, err
+	}
+	return &u.Stats, nil
+}
+
+// Allocate spends one attribute point on a stat (POST /user/allocate).
+// stat is one of str, con, int or per. It returns the updated stats.
+func (s *UserService) Allocate(ctx context.Context, stat string) (*UserStats, error) {
+	switch stat {
+	case "str", "con", "int", "per":
+	default:
+		return nil, fmt.Errorf("invalid stat %q; expected str, con, int or per", stat)
+	}
+	q := url.Values{}
+	q.Set("stat", stat)
+	var stats UserStats
+	if err := s.client.doRequest(ctx, "POST", "/user/allocate", q, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 // GetInbox fetches the user's inbox messages (GET /inbox/messages).
 func (s *UserService) GetInbox(ctx context.Context, page int) (map[string]any, error) {
 	q := url.Values{}
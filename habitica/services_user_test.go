@@ -39,3 +39,50 @@ func TestUserService_GetCurrent(t *testing.T) {
 	require.Equal(t, "Test User", u.Profile.Name)
 }
 
+func TestUserService_GetStats(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/user", r.URL.Path)
+		require.Equal(t, "stats", r.URL.Query().Get("userFields"))
+
+		resp := APIResponse[User]{
+			Success: true,
+			Data: User{
+				ID:    "user-id",
+				Stats: UserStats{Lvl: 12, Exp: 40, GP: 128.4, ToNextLevel: 290},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	stats, err := client.User.GetStats(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 12, stats.Lvl)
+	require.Equal(t, 290.0, stats.ToNextLevel)
+}
+
+
+func TestUserService_Allocate(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/user/allocate", r.URL.Path)
+		require.Equal(t, "int", r.URL.Query().Get("stat"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"lvl":12,"points":0,"str":2,"con":1,"int":5,"per":3}}`))
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	stats, err := client.User.Allocate(context.Background(), "int")
+	require.NoError(t, err)
+	require.Equal(t, 5, stats.Int)
+	require.Equal(t, 0, stats.Points)
+
+	_, err = client.User.Allocate(context.Background(), "luck")
+	require.ErrorContains(t, err, "invalid stat")
+}
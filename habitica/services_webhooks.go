@@ -2,6 +2,7 @@ package habitica
 
 import (
 	"context"
+	"fmt"
 )
 
 // WebhooksService wraps webhook-related endpoints.
@@ -18,3 +19,63 @@ func (s *WebhooksService) ListWebhooks(ctx context.Context) ([]*Webhook, error)
 	return hooks, nil
 }
 
+// CreateWebhook registers a new webhook (POST /user/webhook).
+func (s *WebhooksService) CreateWebhook(ctx context.Context, req *WebhookCreateRequest) (*Webhook, error) {
+	if req == nil || req.URL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	payload := *req
+	if payload.Type == "" && payload.Options != nil {
+		payload.Type = payload.Options.WebhookType()
+	}
+	if err := checkWebhookOptions(payload.Type, payload.Options); err != nil {
+		return nil, err
+	}
+
+	var hook Webhook
+	if err := s.client.doRequest(ctx, "POST", "/user/webhook", nil, &payload, &hook); err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// UpdateWebhook changes a webhook (PUT /user/webhook/:id).
+func (s *WebhooksService) UpdateWebhook(ctx context.Context, id UUID, req *WebhookUpdateRequest) (*Webhook, error) {
+	if req == nil {
+		return nil, fmt.Errorf("update request is required")
+	}
+	payload := *req
+	if payload.Type == "" && payload.Options != nil {
+		payload.Type = payload.Options.WebhookType()
+	}
+	if err := checkWebhookOptions(payload.Type, payload.Options); err != nil {
+		return nil, err
+	}
+
+	var hook Webhook
+	if err := s.client.doRequest(ctx, "PUT", fmt.Sprintf("/user/webhook/%s", id), nil, &payload, &hook); err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// SetWebhookEnabled enables or disables a webhook.
+func (s *WebhooksService) SetWebhookEnabled(ctx context.Context, id UUID, enabled bool) (*Webhook, error) {
+	return s.UpdateWebhook(ctx, id, &WebhookUpdateRequest{Enabled: &enabled})
+}
+
+// DeleteWebhook removes a webhook (DELETE /user/webhook/:id).
+func (s *WebhooksService) DeleteWebhook(ctx context.Context, id UUID) error {
+	return s.client.doRequest(ctx, "DELETE", fmt.Sprintf("/user/webhook/%s", id), nil, nil, nil)
+}
+
+// checkWebhookOptions rejects options of another webhook type.
+func checkWebhookOptions(typ WebhookType, opts WebhookOptions) error {
+	if opts == nil || typ == "" {
+		return nil
+	}
+	if opts.WebhookType() != typ {
+		return fmt.Errorf("%s options cannot be used for a %s webhook", opts.WebhookType(), typ)
+	}
+	return nil
+}
@@ -32,3 +32,101 @@ func TestWebhooksService_ListWebhooks(t *testing.T) {
 	require.Equal(t, UUID("hook-1"), hooks[0].ID)
 }
 
+
+func TestWebhooksService_ListWebhooks_TypedOptions(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":[
+			{"id":"h1","url":"https://example.com/a","enabled":true,"type":"taskActivity","options":{"created":false,"updated":false,"deleted":false,"scored":true,"checklistScored":true}},
+			{"id":"h2","url":"https://example.com/b","enabled":false,"type":"groupChatReceived","options":{"groupId":"party-id"},"failures":3},
+			{"id":"h3","url":"https://example.com/c","enabled":true,"type":"globalActivity","options":{}},
+			{"id":"h4","url":"https://example.com/d","enabled":true,"type":"futureActivity","options":{"foo":1}}
+		]}`))
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	hooks, err := client.Webhooks.ListWebhooks(context.Background())
+	require.NoError(t, err)
+	require.Len(t, hooks, 4)
+	require.Equal(t, &TaskActivityOptions{Scored: true, ChecklistScored: true}, hooks[0].Options)
+	require.Equal(t, &GroupChatReceivedOptions{GroupID: "party-id"}, hooks[1].Options)
+	require.Equal(t, 3, hooks[1].Failures)
+	require.Nil(t, hooks[2].Options)
+
+	raw, ok := hooks[3].Options.(*RawWebhookOptions)
+	require.True(t, ok)
+	require.Equal(t, WebhookType("futureActivity"), raw.WebhookType())
+	b, err := json.Marshal(hooks[3])
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"options":{"foo":1}`)
+}
+
+func TestWebhooksService_CreateWebhook(t *testing.T) {
+	var got map[string]any
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/user/webhook", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"h1","url":"https://example.com/hook","enabled":true,"type":"userActivity","options":{"petHatched":false,"mountRaised":false,"leveledUp":true}}}`))
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+
+	hook, err := client.Webhooks.CreateWebhook(context.Background(), &WebhookCreateRequest{
+		URL:     "https://example.com/hook",
+		Label:   "Level-ups",
+		Options: &UserActivityOptions{LeveledUp: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "userActivity", got["type"])
+	require.Equal(t, map[string]any{"petHatched": false, "mountRaised": false, "leveledUp": true}, got["options"])
+	require.NotContains(t, got, "enabled")
+	require.Equal(t, &UserActivityOptions{LeveledUp: true}, hook.Options)
+
+	_, err = client.Webhooks.CreateWebhook(context.Background(), &WebhookCreateRequest{
+		URL:     "https://example.com/hook",
+		Type:    WebhookTaskActivity,
+		Options: &QuestActivityOptions{QuestStarted: true},
+	})
+	require.ErrorContains(t, err, "questActivity options cannot be used for a taskActivity webhook")
+}
+
+func TestWebhooksService_UpdateAndDeleteWebhook(t *testing.T) {
+	var methods []string
+	var got map[string]any
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/user/webhook/h1", r.URL.Path)
+		methods = append(methods, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "DELETE" {
+			_, _ = w.Write([]byte(`{"success":true,"data":[]}`))
+			return
+		}
+		got = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"h1","url":"https://example.com/hook","enabled":false,"type":"taskActivity"}}`))
+	}
+
+	client, srv := newTestClient(t, handler)
+	defer srv.Close()
+	ctx := context.Background()
+
+	hook, err := client.Webhooks.SetWebhookEnabled(ctx, "h1", false)
+	require.NoError(t, err)
+	require.False(t, hook.Enabled)
+	require.Equal(t, map[string]any{"enabled": false}, got)
+
+	label := "Tasks"
+	_, err = client.Webhooks.UpdateWebhook(ctx, "h1", &WebhookUpdateRequest{Label: &label, Options: &TaskActivityOptions{Scored: true, Created: true}})
+	require.NoError(t, err)
+	require.Equal(t, "Tasks", got["label"])
+	require.Equal(t, "taskActivity", got["type"])
+
+	require.NoError(t, client.Webhooks.DeleteWebhook(ctx, "h1"))
+	require.Equal(t, []string{"PUT", "PUT", "DELETE"}, methods)
+}
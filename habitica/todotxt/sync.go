@@ -0,0 +1,656 @@
+package todotxt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// AliasPrefix starts the aliases of tasks linked by this package. With
+// Options.File set, the alias continues with the file ID and a dash, e.g.
+// "todotxt-3f9a1c-1a2b3c4d", which records the file a todo belongs to.
+const AliasPrefix = "todotxt-"
+
+// Mode selects the direction of Sync.
+type Mode int
+
+const (
+	// ModeSync merges both sides; see Sync.
+	ModeSync Mode = iota
+	// ModeImport makes the account match the file. Lines win every
+	// conflict; todos that are not in the file are left alone.
+	ModeImport
+	// ModeExport makes the file match the account: all open and completed
+	// todos, with the account winning every conflict. Lines without a task
+	// are removed.
+	ModeExport
+)
+
+// Prefer selects the winning side when a line and its todo both changed
+// since the last sync.
+type Prefer int
+
+const (
+	// PreferNone reports conflicts and leaves both sides alone.
+	PreferNone Prefer = iota
+	// PreferFile copies the line to the todo.
+	PreferFile
+	// PreferHabitica copies the todo to the line.
+	PreferHabitica
+)
+
+// Options controls Sync.
+type Options struct {
+	Mode Mode
+	// Prefer resolves conflicts in ModeSync.
+	Prefer Prefer
+	// File identifies the file in the aliases of the todos Sync links to
+	// it, see FileID. Only todos of this file are ever deleted.
+	File string
+	// Prune deletes, in ModeSync, the open todos of File whose line was
+	// removed from the file. Without it they are reported as "orphan" and
+	// left alone. Nothing is pruned while the file has no lines at all.
+	Prune bool
+	// DryRun reports the changes without touching the account. The
+	// returned items show the file as it would be written.
+	DryRun bool
+}
+
+// FileID returns a short ID for the file at path, derived from its absolute path.
+func FileID(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(path)))
+	return hex.EncodeToString(sum[:3])
+}
+
+// Change is a change made by Sync.
+type Change struct {
+	// Side is "habitica", "file" or, for conflicts, "both".
+	Side   string
+	Action string // create, update, complete, reopen, delete, orphan, add, remove, conflict
+	Text   string
+	// Fields lists the differing fields of an update.
+	Fields []string
+}
+
+// String formats the change for humans, e.g. `habitica: update "Call mom" (text, due)`.
+func (c Change) String() string {
+	s := fmt.Sprintf("%s: %s %q", c.Side, c.Action, c.Text)
+	if len(c.Fields) > 0 {
+		s += " (" + strings.Join(c.Fields, ", ") + ")"
+	}
+	return s
+}
+
+// Sync reconciles the todo.txt items with the todos of the account and
+// returns the items to write back to the file, together with the changes made.
+//
+// Lines are linked to tasks by the hab: key, which holds the task alias.
+// The habsync: key records the state of the line and the todo after the
+// last sync (see KeySync), which tells for every line which side changed.
+// In ModeSync:
+//   - a line without hab: creates a todo, and the line gets its alias;
+//   - if only the line changed, it is copied to the todo; if only the todo
+//     changed, it is copied to the line;
+//   - if both changed, or the line has no habsync: key, the line is a
+//     conflict and resolved by opts.Prefer;
+//   - an open line whose todo is gone was deleted in Habitica and is removed;
+//     completed lines are kept, since Habitica archives completed todos;
+//   - an open todo of opts.File without a line was removed from the file;
+//     it is deleted with opts.Prune and reported as orphan otherwise;
+//   - todos linked to other files are left alone;
+//   - other open todos without a line are appended, and todos without an
+//     alias get one.
+//
+// Priorities map to difficulties: (A) is hard, (B) medium, no priority easy
+// and (C) or lower trivial. Projects map to tags of the same name (an
+// underscore also matches a space), contexts to tags named "@context";
+// missing tags are created.
+func Sync(ctx context.Context, client *habitica.Client, items []*Item, opts Options) ([]*Item, []Change, error) {
+	s, err := newSyncer(ctx, client, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := s.run(ctx, items)
+	return out, s.changes, err
+}
+
+type syncer struct {
+	client  *habitica.Client
+	opts    Options
+	tasks   []*habitica.Task
+	tags    []*habitica.Tag
+	index   *habitica.TagIndex
+	changes []Change
+}
+
+func newSyncer(ctx context.Context, client *habitica.Client, opts Options) (*syncer, error) {
+	open, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{Type: "todos"})
+	if err != nil {
+		return nil, err
+	}
+	completed, err := client.Tasks.ListUserTasks(ctx, habitica.TasksFilter{Type: "completedTodos"})
+	if err != nil {
+		return nil, err
+	}
+	tags, err := client.Tags.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &syncer{
+		client: client,
+		opts:   opts,
+		tasks:  append(open, completed...),
+		tags:   tags,
+		index:  habitica.NewTagIndex(tags),
+	}, nil
+}
+
+func (s *syncer) record(side, action, text string, fields ...string) {
+	s.changes = append(s.changes, Change{Side: side, Action: action, Text: text, Fields: fields})
+}
+
+func (s *syncer) run(ctx context.Context, items []*Item) ([]*Item, error) {
+	byAlias := make(map[string]*habitica.Task, len(s.tasks))
+	for _, t := range s.tasks {
+		if t.Alias != "" {
+			byAlias[t.Alias] = t
+		}
+	}
+
+	linked := make(map[habitica.UUID]bool)
+	var out []*Item
+	for _, it := range items {
+		alias := it.Get(KeyAlias)
+		t := byAlias[alias]
+		if alias == "" {
+			t = nil
+		}
+
+		switch {
+		case t == nil && s.opts.Mode == ModeExport:
+			s.record("file", "remove", it.Text)
+			continue
+		case t == nil && alias != "" && s.opts.Mode == ModeSync && it.Done:
+			// Completed and archived in Habitica.
+			out = append(out, it)
+			continue
+		case t == nil && strings.HasPrefix(alias, AliasPrefix) && s.opts.Mode == ModeSync:
+			s.record("file", "remove", it.Text)
+			continue
+		case t == nil:
+			if err := s.create(ctx, it); err != nil {
+				return out, err
+			}
+			out = append(out, it)
+			continue
+		}
+
+		linked[t.ID] = true
+		fileChanged, taskChanged := true, true
+		if hash, updated, ok := syncState(it); ok {
+			fileChanged = hash != lineHash(it)
+			taskChanged = updated != updatedMillis(t)
+		}
+		if fields := s.diff(it, t); len(fields) > 0 {
+			switch {
+			case s.opts.Mode == ModeImport ||
+				(s.opts.Mode == ModeSync && fileChanged && (!taskChanged || s.opts.Prefer == PreferFile)):
+				var err error
+				if t, err = s.updateTask(ctx, it, t, fields); err != nil {
+					return out, err
+				}
+			case s.opts.Mode == ModeExport || !fileChanged || s.opts.Prefer == PreferHabitica:
+				// Also covers renamed tags, which change neither side.
+				s.updateItem(it, t, fields)
+			default:
+				s.record("both", "conflict", it.Text, fields...)
+				out = append(out, it)
+				continue
+			}
+		}
+		setSyncState(it, t)
+		out = append(out, it)
+	}
+
+	if s.opts.Mode == ModeImport {
+		return out, nil
+	}
+	for _, t := range s.tasks {
+		if linked[t.ID] {
+			continue
+		}
+		switch {
+		case s.opts.Mode == ModeSync && t.Completed:
+			// Completed todos are only exported.
+			continue
+		case s.opts.Mode == ModeSync && strings.HasPrefix(t.Alias, AliasPrefix):
+			if s.opts.File == "" || aliasFile(t.Alias) != s.opts.File {
+				// Linked to another file.
+				continue
+			}
+			if !s.opts.Prune || len(items) == 0 {
+				s.record("habitica", "orphan", t.Text)
+				continue
+			}
+			s.record("habitica", "delete", t.Text)
+			if !s.opts.DryRun {
+				if err := s.client.Tasks.DeleteTask(ctx, t.ID); err != nil {
+					return out, fmt.Errorf("delete todo %q: %w", t.Text, err)
+				}
+			}
+			continue
+		}
+		if t.Alias == "" {
+			alias := s.taskAlias(t.ID)
+			if !s.opts.DryRun {
+				updated, err := s.client.Tasks.UpdateTask(ctx, t.ID, &habitica.TaskUpdateRequest{Alias: &alias})
+				if err != nil {
+					return out, fmt.Errorf("set alias of todo %q: %w", t.Text, err)
+				}
+				t.UpdatedAt = updated.UpdatedAt
+			}
+			t.Alias = alias
+		}
+		it := &Item{CreationDate: localDate(t.CreatedAt)}
+		it.Set(KeyAlias, t.Alias)
+		s.updateItem(it, t, nil)
+		setSyncState(it, t)
+		s.record("file", "add", it.Text)
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// create creates the todo for a line and links the line to it.
+func (s *syncer) create(ctx context.Context, it *Item) error {
+	alias := it.Get(KeyAlias)
+	if alias == "" {
+		alias = s.newAlias()
+	}
+	tags, err := s.resolveTags(ctx, it)
+	if err != nil {
+		return err
+	}
+
+	req := &habitica.TaskCreateRequest{
+		Text:     it.Text,
+		Type:     habitica.TaskTypeTodo,
+		Alias:    alias,
+		Priority: priorityToDifficulty(it.Priority),
+		Tags:     tags,
+	}
+	if due := it.Get(KeyDue); due != "" {
+		d, err := habitica.ParseDate(due, time.Local)
+		if err != nil {
+			return err
+		}
+		req.Date = habitica.NewTimestamp(d)
+	}
+
+	s.record("habitica", "create", it.Text)
+	if it.Done {
+		s.record("habitica", "complete", it.Text)
+	}
+	it.Set(KeyAlias, alias)
+	if it.CreationDate == "" {
+		it.CreationDate = time.Now().Format("2006-01-02")
+	}
+	if s.opts.DryRun {
+		return nil
+	}
+
+	t, err := s.client.Tasks.CreateTask(ctx, req)
+	if err != nil {
+		it.Set(KeyAlias, "")
+		return fmt.Errorf("create todo %q: %w", it.Text, err)
+	}
+	if it.Done {
+		if _, err := s.client.Tasks.ScoreTask(ctx, t.ID, "up"); err != nil {
+			return fmt.Errorf("complete todo %q: %w", it.Text, err)
+		}
+		if t, err = s.client.Tasks.GetTask(ctx, t.ID); err != nil {
+			return err
+		}
+	}
+	setSyncState(it, t)
+	return nil
+}
+
+// diff lists the fields in which the line and the task differ.
+func (s *syncer) diff(it *Item, t *habitica.Task) []string {
+	var fields []string
+	if it.Text != t.Text {
+		fields = append(fields, "text")
+	}
+	if normalizePriority(it.Priority) != difficultyToPriority(t.Priority) {
+		fields = append(fields, "priority")
+	}
+	if !equalKeys(itemTagKeys(it), s.taskTagKeys(t)) {
+		fields = append(fields, "tags")
+	}
+	if it.Get(KeyDue) != localDate(t.Date) {
+		fields = append(fields, "due")
+	}
+	if it.Done != t.Completed {
+		fields = append(fields, "done")
+	}
+	return fields
+}
+
+// updateTask copies the differing fields of the line to the task and
+// returns the updated task.
+func (s *syncer) updateTask(ctx context.Context, it *Item, t *habitica.Task, fields []string) (*habitica.Task, error) {
+	req := &habitica.TaskUpdateRequest{}
+	update := false
+	for _, f := range fields {
+		switch f {
+		case "text":
+			req.Text = &it.Text
+		case "priority":
+			p := priorityToDifficulty(it.Priority)
+			req.Priority = &p
+		case "tags":
+			tags, err := s.resolveTags(ctx, it)
+			if err != nil {
+				return nil, err
+			}
+			req.Tags = &tags
+		case "due":
+			var ts habitica.Timestamp
+			if due := it.Get(KeyDue); due != "" {
+				d, err := habitica.ParseDate(due, time.Local)
+				if err != nil {
+					return nil, err
+				}
+				ts = habitica.NewTimestamp(d)
+			}
+			req.Date = &ts
+		case "done":
+			continue
+		}
+		update = true
+	}
+
+	if update {
+		s.record("habitica", "update", it.Text, without(fields, "done")...)
+		if !s.opts.DryRun {
+			if _, err := s.client.Tasks.UpdateTask(ctx, t.ID, req); err != nil {
+				return nil, fmt.Errorf("update todo %q: %w", it.Text, err)
+			}
+		}
+	}
+	if it.Done != t.Completed {
+		action, direction := "complete", "up"
+		if !it.Done {
+			action, direction = "reopen", "down"
+		}
+		s.record("habitica", action, it.Text)
+		if !s.opts.DryRun {
+			if _, err := s.client.Tasks.ScoreTask(ctx, t.ID, direction); err != nil {
+				return nil, fmt.Errorf("%s todo %q: %w", action, it.Text, err)
+			}
+		}
+	}
+	if s.opts.DryRun {
+		return t, nil
+	}
+	return s.client.Tasks.GetTask(ctx, t.ID)
+}
+
+// updateItem copies the task to the line. With fields set, only those
+// fields are copied and the change is recorded.
+func (s *syncer) updateItem(it *Item, t *habitica.Task, fields []string) {
+	all := fields == nil
+	has := func(f string) bool {
+		for _, field := range fields {
+			if field == f {
+				return true
+			}
+		}
+		return all
+	}
+
+	if has("text") {
+		it.Text = t.Text
+	}
+	if has("priority") {
+		it.Priority = difficultyToPriority(t.Priority)
+	}
+	if has("tags") {
+		it.Projects, it.Contexts = nil, nil
+		for _, id := range t.Tags {
+			name := strings.ReplaceAll(s.index.Name(id), " ", "_")
+			switch {
+			case name == "":
+			case strings.HasPrefix(name, "@") && len(name) > 1:
+				it.Contexts = append(it.Contexts, name[1:])
+			default:
+				it.Projects = append(it.Projects, name)
+			}
+		}
+	}
+	if has("due") {
+		it.Set(KeyDue, localDate(t.Date))
+	}
+	if has("done") {
+		it.Done = t.Completed
+		it.CompletionDate = ""
+		if t.Completed {
+			it.CompletionDate = localDate(t.DateCompleted)
+		}
+	}
+	if !all {
+		s.record("file", "update", it.Text, fields...)
+	}
+}
+
+// resolveTags returns the tag IDs of the projects and contexts of a line,
+// creating missing tags.
+func (s *syncer) resolveTags(ctx context.Context, it *Item) ([]habitica.UUID, error) {
+	names := append([]string(nil), it.Projects...)
+	for _, c := range it.Contexts {
+		names = append(names, "@"+c)
+	}
+
+	ids := []habitica.UUID{}
+	for _, name := range names {
+		tag := s.index.Lookup(name)
+		if tag == nil {
+			tag = s.index.Lookup(strings.ReplaceAll(name, "_", " "))
+		}
+		if tag == nil {
+			s.record("habitica", "create tag", name)
+			tag = &habitica.Tag{ID: habitica.UUID(name), Name: name}
+			if !s.opts.DryRun {
+				var err error
+				if tag, err = s.client.Tags.CreateTag(ctx, name); err != nil {
+					return nil, fmt.Errorf("create tag %q: %w", name, err)
+				}
+			}
+			s.tags = append(s.tags, tag)
+			s.index = habitica.NewTagIndex(s.tags)
+		}
+		ids = append(ids, tag.ID)
+	}
+	return ids, nil
+}
+
+// lineHash returns a short hash of the synced fields of a line.
+func lineHash(it *Item) string {
+	tags := itemTagKeys(it)
+	sort.Strings(tags)
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%d\n%s\n%s\n%t\n", it.Text, normalizePriority(it.Priority), strings.Join(tags, " "), it.Get(KeyDue), it.Done)
+	return hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// updatedMillis returns the UpdatedAt of a task in Unix milliseconds, or 0.
+func updatedMillis(t *habitica.Task) int64 {
+	updated, err := t.UpdatedAt.Time()
+	if err != nil || updated.IsZero() {
+		return 0
+	}
+	return updated.UnixMilli()
+}
+
+// syncState returns the hash and update time stored in the KeySync value of a line.
+func syncState(it *Item) (hash string, updated int64, ok bool) {
+	hash, millis, ok := strings.Cut(it.Get(KeySync), ".")
+	if !ok {
+		return "", 0, false
+	}
+	updated, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return hash, updated, true
+}
+
+// setSyncState records that the line and the task are in sync.
+func setSyncState(it *Item, t *habitica.Task) {
+	it.Set(KeySync, lineHash(it)+"."+strconv.FormatInt(updatedMillis(t), 10))
+}
+
+// itemTagKeys returns the comparable tag keys of a line.
+func itemTagKeys(it *Item) []string {
+	var keys []string
+	for _, p := range it.Projects {
+		keys = append(keys, tagKey(p))
+	}
+	for _, c := range it.Contexts {
+		keys = append(keys, tagKey("@"+c))
+	}
+	return keys
+}
+
+func (s *syncer) taskTagKeys(t *habitica.Task) []string {
+	var keys []string
+	for _, id := range t.Tags {
+		if name := s.index.Name(id); name != "" {
+			keys = append(keys, tagKey(name))
+		}
+	}
+	return keys
+}
+
+func tagKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", "_"))
+}
+
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func without(fields []string, drop string) []string {
+	var out []string
+	for _, f := range fields {
+		if f != drop {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// priorityToDifficulty maps a todo.txt priority to a Habitica difficulty.
+func priorityToDifficulty(p byte) float64 {
+	switch normalizePriority(p) {
+	case 'A':
+		return 2
+	case 'B':
+		return 1.5
+	case 'C':
+		return 0.1
+	}
+	return 1
+}
+
+// difficultyToPriority is the inverse of priorityToDifficulty.
+func difficultyToPriority(d float64) byte {
+	switch {
+	case d >= 2:
+		return 'A'
+	case d >= 1.5:
+		return 'B'
+	case d >= 1:
+		return 0
+	}
+	return 'C'
+}
+
+// normalizePriority folds (D) to (Z) into (C), which all mean trivial.
+func normalizePriority(p byte) byte {
+	if p > 'C' {
+		return 'C'
+	}
+	return p
+}
+
+// alias returns the alias for a task of the file with the given suffix.
+func (s *syncer) alias(suffix string) string {
+	if s.opts.File == "" {
+		return AliasPrefix + suffix
+	}
+	return AliasPrefix + s.opts.File + "-" + suffix
+}
+
+// taskAlias derives the alias of an existing task from its ID.
+func (s *syncer) taskAlias(id habitica.UUID) string {
+	hexID := strings.ReplaceAll(string(id), "-", "")
+	if len(hexID) > 8 {
+		hexID = hexID[:8]
+	}
+	return s.alias(hexID)
+}
+
+// newAlias returns a random alias for a new task.
+func (s *syncer) newAlias() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return s.alias(hex.EncodeToString(b))
+}
+
+// aliasFile returns the file ID in an alias of this package, or "" for
+// aliases without one.
+func aliasFile(alias string) string {
+	rest, ok := strings.CutPrefix(alias, AliasPrefix)
+	if !ok {
+		return ""
+	}
+	file, _, ok := strings.Cut(rest, "-")
+	if !ok {
+		return ""
+	}
+	return file
+}
+
+// localDate renders a Habitica timestamp as a local YYYY-MM-DD date.
+func localDate(ts habitica.Timestamp) string {
+	t, err := ts.Time()
+	if err != nil || t.IsZero() {
+		return ""
+	}
+	return t.In(time.Local).Format("2006-01-02")
+}
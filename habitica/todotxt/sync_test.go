@@ -0,0 +1,215 @@
+package todotxt_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/mock"
+	"github.com/danielrichardt/gohabitica/habitica/todotxt"
+	"github.com/stretchr/testify/require"
+)
+
+func readItems(t *testing.T, s string) []*todotxt.Item {
+	t.Helper()
+	items, err := todotxt.Read(strings.NewReader(s))
+	require.NoError(t, err)
+	return items
+}
+
+// lines formats the items without their sync state, which holds timestamps.
+func lines(items []*todotxt.Item) []string {
+	out := make([]string, len(items))
+	for i, it := range items {
+		line := *it
+		line.Values = nil
+		for _, kv := range it.Values {
+			if kv.Key != todotxt.KeySync {
+				line.Values = append(line.Values, kv)
+			}
+		}
+		out[i] = line.String()
+	}
+	return out
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	account := mock.NewAccount([]*habitica.Task{
+		{ID: "task-a", Type: habitica.TaskTypeTodo, Alias: "todotxt-f1-aaaa", Text: "Call mom", Priority: 1},
+		{ID: "task-b", Type: habitica.TaskTypeTodo, Alias: "todotxt-f1-bbbb", Text: "Water plants", Priority: 1},
+		{ID: "task-c", Type: habitica.TaskTypeTodo, Alias: "todotxt-f1-cccc", Text: "Removed line", Priority: 1},
+		{ID: "task-d", Type: habitica.TaskTypeTodo, Alias: "todotxt-f1-dddd", Text: "Both sides", Priority: 1},
+		{ID: "task-e", Type: habitica.TaskTypeTodo, Alias: "todotxt-f1-eeee", Text: "Deleted in app", Priority: 1},
+	}, []*habitica.Tag{{ID: "tag-work", Name: "work"}, {ID: "tag-errands", Name: "Errands Run"}})
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	// Export records the sync state of every line.
+	items, _, err := todotxt.Sync(ctx, srv.Client, nil, todotxt.Options{Mode: todotxt.ModeExport, File: "f1"})
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+	for _, it := range items {
+		require.NotEmpty(t, it.Get(todotxt.KeySync), it.Text)
+	}
+
+	// Edit the file.
+	items[0].Text, items[0].Done, items[0].Projects = "Call dad", true, []string{"Errands_Run"}
+	items[3].Text = "Both sides (file)"
+	items = append(append(items[:2], items[3:]...), readItems(t, `(B) Brand new @phone due:2025-03-05
+x Archived hab:todotxt-f1-ffff
+Hand linked hab:todotxt-f1-gggg
+`)...)
+
+	// Edit the account.
+	time.Sleep(5 * time.Millisecond)
+	text, priority, tags := "Water all plants", 2.0, []habitica.UUID{"tag-work"}
+	_, err = srv.Client.Tasks.UpdateTask(ctx, "task-b", &habitica.TaskUpdateRequest{Text: &text, Priority: &priority, Tags: &tags})
+	require.NoError(t, err)
+	text = "Both sides (app)"
+	_, err = srv.Client.Tasks.UpdateTask(ctx, "task-d", &habitica.TaskUpdateRequest{Text: &text})
+	require.NoError(t, err)
+	require.NoError(t, srv.Client.Tasks.DeleteTask(ctx, "task-e"))
+	_, err = srv.Client.Tasks.CreateTask(ctx, &habitica.TaskCreateRequest{Type: habitica.TaskTypeTodo, Text: "Linked by hand", Alias: "todotxt-f1-gggg"})
+	require.NoError(t, err)
+	app, err := srv.Client.Tasks.CreateTask(ctx, &habitica.TaskCreateRequest{Type: habitica.TaskTypeTodo, Text: "From the app", Priority: 0.1})
+	require.NoError(t, err)
+
+	opts := todotxt.Options{File: "f1", Prune: true}
+	out, changes, err := todotxt.Sync(ctx, srv.Client, items, opts)
+	require.NoError(t, err)
+
+	got := lines(out)
+	require.Len(t, got, 7)
+	require.Equal(t, "x Call dad +Errands_Run hab:todotxt-f1-aaaa", got[0])
+	require.Equal(t, "(A) Water all plants +work hab:todotxt-f1-bbbb", got[1])
+	require.Equal(t, "Both sides (file) hab:todotxt-f1-dddd", got[2])
+	require.True(t, strings.HasPrefix(got[3], "(B) "), got[3])
+	require.Contains(t, got[3], "Brand new @phone due:2025-03-05 hab:todotxt-f1-")
+	require.Equal(t, "x Archived hab:todotxt-f1-ffff", got[4])
+	require.Equal(t, "Hand linked hab:todotxt-f1-gggg", got[5])
+	require.True(t, strings.HasPrefix(got[6], "(C) "), got[6])
+	require.Contains(t, got[6], "From the app hab:todotxt-f1-")
+	require.Equal(t, app.ID, account.Task(out[6].Get(todotxt.KeyAlias)).ID)
+
+	a := account.Task("task-a")
+	require.Equal(t, "Call dad", a.Text)
+	require.True(t, a.Completed)
+	require.Equal(t, []habitica.UUID{"tag-errands"}, a.Tags)
+	require.Equal(t, "Water all plants", account.Task("task-b").Text)
+	require.Nil(t, account.Task("task-c"))
+	require.Equal(t, "Both sides (app)", account.Task("task-d").Text)
+	require.Equal(t, "Linked by hand", account.Task("todotxt-f1-gggg").Text)
+
+	created := account.Task(out[3].Get(todotxt.KeyAlias))
+	require.NotNil(t, created)
+	require.Equal(t, 1.5, created.Priority)
+	require.Len(t, created.Tags, 1)
+
+	var summary []string
+	for _, c := range changes {
+		summary = append(summary, c.String())
+	}
+	require.Equal(t, []string{
+		`habitica: update "Call dad" (text, tags)`,
+		`habitica: complete "Call dad"`,
+		`file: update "Water all plants" (text, priority, tags)`,
+		`both: conflict "Both sides (file)" (text)`,
+		`file: remove "Deleted in app"`,
+		`habitica: create tag "@phone"`,
+		`habitica: create "Brand new"`,
+		`both: conflict "Hand linked" (text)`,
+		`habitica: delete "Removed line"`,
+		`file: add "From the app"`,
+	}, summary)
+
+	// Conflicts stay until a side is preferred.
+	_, changes, err = todotxt.Sync(ctx, srv.Client, out, opts)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+
+	opts.Prefer = todotxt.PreferHabitica
+	out, changes, err = todotxt.Sync(ctx, srv.Client, out, opts)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	got = lines(out)
+	require.Equal(t, "Both sides (app) hab:todotxt-f1-dddd", got[2])
+	require.Equal(t, "Linked by hand hab:todotxt-f1-gggg", got[5])
+
+	// Syncing the result again changes nothing.
+	_, changes, err = todotxt.Sync(ctx, srv.Client, out, todotxt.Options{File: "f1", Prune: true})
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestSync_ImportExport(t *testing.T) {
+	ctx := context.Background()
+	account := mock.NewAccount([]*habitica.Task{
+		{ID: "task-a", Type: habitica.TaskTypeTodo, Alias: "todotxt-aaaa", Text: "In the app", Priority: 1},
+		{ID: "task-b", Type: habitica.TaskTypeTodo, Alias: "manifest-alias", Text: "Other", Priority: 1, Completed: true},
+	}, nil)
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	// Import leaves todos that are not in the file alone.
+	items := readItems(t, "Only in file\nChanged in file hab:todotxt-aaaa\n")
+	out, _, err := todotxt.Sync(ctx, srv.Client, items, todotxt.Options{Mode: todotxt.ModeImport, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Len(t, account.Tasks(), 2)
+
+	out, _, err = todotxt.Sync(ctx, srv.Client, items, todotxt.Options{Mode: todotxt.ModeImport})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Len(t, account.Tasks(), 3)
+	require.Equal(t, "Changed in file", account.Task("todotxt-aaaa").Text)
+
+	// Export writes every todo of the account, including completed ones.
+	out, _, err = todotxt.Sync(ctx, srv.Client, readItems(t, "Stray line\n"), todotxt.Options{Mode: todotxt.ModeExport})
+	require.NoError(t, err)
+	got := lines(out)
+	require.Len(t, got, 3)
+	require.Contains(t, got, "Changed in file hab:todotxt-aaaa")
+	require.Contains(t, got, "x Other hab:manifest-alias")
+}
+
+func TestSync_Prune(t *testing.T) {
+	ctx := context.Background()
+	account := mock.NewAccount([]*habitica.Task{
+		{ID: "task-a", Type: habitica.TaskTypeTodo, Alias: "todotxt-f1-aaaa", Text: "Removed from this file", Priority: 1},
+		{ID: "task-b", Type: habitica.TaskTypeTodo, Alias: "todotxt-f2-bbbb", Text: "In another file", Priority: 1},
+		{ID: "task-c", Type: habitica.TaskTypeTodo, Alias: "todotxt-cccc", Text: "Without file", Priority: 1},
+	}, nil)
+	srv, err := mock.NewServer(account)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	items := readItems(t, "Kept\n")
+
+	// Without pruning the todo of this file is only reported.
+	out, changes, err := todotxt.Sync(ctx, srv.Client, items, todotxt.Options{File: "f1"})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Contains(t, changes, todotxt.Change{Side: "habitica", Action: "orphan", Text: "Removed from this file"})
+	require.Len(t, account.Tasks(), 4)
+
+	// Pruning deletes it; todos of other files are neither deleted nor appended.
+	out, changes, err = todotxt.Sync(ctx, srv.Client, out, todotxt.Options{File: "f1", Prune: true})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Equal(t, []todotxt.Change{{Side: "habitica", Action: "delete", Text: "Removed from this file"}}, changes)
+	require.Nil(t, account.Task("todotxt-f1-aaaa"))
+	require.NotNil(t, account.Task("todotxt-f2-bbbb"))
+	require.NotNil(t, account.Task("todotxt-cccc"))
+	require.True(t, strings.HasPrefix(out[0].Get(todotxt.KeyAlias), "todotxt-f1-"))
+
+	// An empty file prunes nothing.
+	_, changes, err = todotxt.Sync(ctx, srv.Client, nil, todotxt.Options{File: "f2", Prune: true})
+	require.NoError(t, err)
+	require.Contains(t, changes, todotxt.Change{Side: "habitica", Action: "orphan", Text: "In another file"})
+	require.NotNil(t, account.Task("todotxt-f2-bbbb"))
+}
@@ -0,0 +1,244 @@
+// Package todotxt reads and writes todo.txt files and keeps them in sync
+// with the todos of a Habitica account.
+//
+// A line such as
+//
+//	x 2025-03-02 2025-03-01 Call mom +Family @phone due:2025-03-05 pri:A hab:todotxt-1a2b3c4d
+//
+// maps to a completed Habitica todo "Call mom" with the tags "Family" and
+// "@phone", a due date and the difficulty of priority (A). The hab: key holds
+// the alias of the linked task and the habsync: key the state of the last
+// sync; both are added when a line is first synced. See Sync for how lines
+// and tasks are matched and which side wins.
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Item is a single todo.txt line.
+type Item struct {
+	Done bool
+	// Priority is 'A' to 'Z', or 0 without a priority. Completed lines keep
+	// it as a pri: key, as recommended by the todo.txt format.
+	Priority       byte
+	CompletionDate string // YYYY-MM-DD
+	CreationDate   string // YYYY-MM-DD
+	// Text is the description without projects, contexts and key:value pairs.
+	Text     string
+	Projects []string
+	Contexts []string
+	// Values holds the key:value pairs in file order, e.g. due and hab.
+	Values []KeyValue
+}
+
+// KeyValue is a key:value pair of a line.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// Well-known keys.
+const (
+	KeyDue      = "due"
+	KeyPriority = "pri"
+	// KeyAlias links a line to the Habitica task with this alias.
+	KeyAlias = "hab"
+	// KeySync records the state of a linked line after the last sync: a
+	// hash of the line and the update time of the task in Unix
+	// milliseconds, e.g. habsync:1a2b3c4d.1740826800123.
+	KeySync = "habsync"
+)
+
+var (
+	datePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	priorityPattern = regexp.MustCompile(`^\([A-Z]\)$`)
+	keyValuePattern = regexp.MustCompile(`^([A-Za-z][^:\s]*):([^:\s/][^\s]*)$`)
+)
+
+// ParseLine parses a single todo.txt line. Leading and trailing whitespace is ignored.
+func ParseLine(line string) (*Item, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty line")
+	}
+
+	it := &Item{}
+	if fields[0] == "x" {
+		it.Done = true
+		fields = fields[1:]
+		if len(fields) > 0 && datePattern.MatchString(fields[0]) {
+			it.CompletionDate = fields[0]
+			fields = fields[1:]
+		}
+	}
+	if len(fields) > 0 && priorityPattern.MatchString(fields[0]) {
+		it.Priority = fields[0][1]
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && datePattern.MatchString(fields[0]) {
+		it.CreationDate = fields[0]
+		fields = fields[1:]
+	}
+
+	var words []string
+	for _, f := range fields {
+		switch {
+		case len(f) > 1 && f[0] == '+':
+			it.Projects = append(it.Projects, f[1:])
+		case len(f) > 1 && f[0] == '@':
+			it.Contexts = append(it.Contexts, f[1:])
+		case keyValuePattern.MatchString(f):
+			m := keyValuePattern.FindStringSubmatch(f)
+			if m[1] == KeyPriority && len(m[2]) == 1 && m[2][0] >= 'A' && m[2][0] <= 'Z' {
+				it.Priority = m[2][0]
+				continue
+			}
+			it.Values = append(it.Values, KeyValue{Key: m[1], Value: m[2]})
+		default:
+			words = append(words, f)
+		}
+	}
+	it.Text = strings.Join(words, " ")
+	if it.Text == "" {
+		return nil, fmt.Errorf("line %q has no description", strings.TrimSpace(line))
+	}
+	return it, nil
+}
+
+// String formats the item as a todo.txt line.
+func (it *Item) String() string {
+	var parts []string
+	if it.Done {
+		parts = append(parts, "x")
+		if it.CompletionDate != "" {
+			parts = append(parts, it.CompletionDate)
+		}
+	} else if it.Priority != 0 {
+		parts = append(parts, "("+string(it.Priority)+")")
+	}
+	if it.CreationDate != "" {
+		parts = append(parts, it.CreationDate)
+	}
+	parts = append(parts, it.Text)
+	for _, p := range it.Projects {
+		parts = append(parts, "+"+p)
+	}
+	for _, c := range it.Contexts {
+		parts = append(parts, "@"+c)
+	}
+	for _, kv := range it.Values {
+		parts = append(parts, kv.Key+":"+kv.Value)
+	}
+	if it.Done && it.Priority != 0 {
+		parts = append(parts, KeyPriority+":"+string(it.Priority))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Get returns the value of key, or "" if the line has no such key.
+func (it *Item) Get(key string) string {
+	for _, kv := range it.Values {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return ""
+}
+
+// Set sets the value of key, replacing an existing pair in place. An empty value removes the key.
+func (it *Item) Set(key, value string) {
+	for i, kv := range it.Values {
+		if kv.Key != key {
+			continue
+		}
+		if value == "" {
+			it.Values = append(it.Values[:i], it.Values[i+1:]...)
+		} else {
+			it.Values[i].Value = value
+		}
+		return
+	}
+	if value != "" {
+		it.Values = append(it.Values, KeyValue{Key: key, Value: value})
+	}
+}
+
+// Read parses a todo.txt file. Blank lines are skipped.
+func Read(r io.Reader) ([]*Item, error) {
+	var items []*Item
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		it, err := ParseLine(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		items = append(items, it)
+	}
+	return items, sc.Err()
+}
+
+// ReadFile parses the todo.txt file at path. A missing file yields no items.
+func ReadFile(path string) ([]*Item, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	items, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return items, nil
+}
+
+// Write writes the items, one per line.
+func Write(w io.Writer, items []*Item) error {
+	bw := bufio.NewWriter(w)
+	for _, it := range items {
+		if _, err := bw.WriteString(it.String() + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteFile replaces the file at path with the items. It writes a temporary
+// file first, so the file is never left half-written.
+func WriteFile(path string, items []*Item) error {
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".todotxt-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Write(tmp, items); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
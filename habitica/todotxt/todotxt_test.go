@@ -0,0 +1,74 @@
+package todotxt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	it, err := ParseLine("(A) 2025-03-01 Call mom at 10:30 +Family @phone due:2025-03-05 hab:todotxt-1a2b")
+	require.NoError(t, err)
+	require.Equal(t, &Item{
+		Priority:     'A',
+		CreationDate: "2025-03-01",
+		Text:         "Call mom at 10:30",
+		Projects:     []string{"Family"},
+		Contexts:     []string{"phone"},
+		Values:       []KeyValue{{KeyDue, "2025-03-05"}, {KeyAlias, "todotxt-1a2b"}},
+	}, it)
+	require.Equal(t, "2025-03-05", it.Get(KeyDue))
+
+	done, err := ParseLine("x 2025-03-02 2025-03-01 Read https://example.com pri:B")
+	require.NoError(t, err)
+	require.True(t, done.Done)
+	require.Equal(t, byte('B'), done.Priority)
+	require.Equal(t, "2025-03-02", done.CompletionDate)
+	require.Equal(t, "2025-03-01", done.CreationDate)
+	require.Equal(t, "Read https://example.com", done.Text)
+
+	_, err = ParseLine("+project @context")
+	require.ErrorContains(t, err, "no description")
+}
+
+func TestItem_String(t *testing.T) {
+	for _, line := range []string{
+		"(A) 2025-03-01 Call mom +Family @phone due:2025-03-05",
+		"x 2025-03-02 2025-03-01 Call mom +Family pri:A",
+		"Plain task",
+		"x Done without dates",
+	} {
+		it, err := ParseLine(line)
+		require.NoError(t, err)
+		require.Equal(t, line, it.String())
+	}
+
+	it, err := ParseLine("Task due:2025-01-01 hab:x")
+	require.NoError(t, err)
+	it.Set(KeyDue, "")
+	it.Set("rec", "1w")
+	require.Equal(t, "Task hab:x rec:1w", it.String())
+}
+
+func TestReadWrite(t *testing.T) {
+	in := "(B) First +work\n\n   \nx Second\n"
+	items, err := Read(strings.NewReader(in))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, items))
+	require.Equal(t, "(B) First +work\nx Second\n", buf.String())
+
+	_, err = Read(strings.NewReader("ok\n@only-context\n"))
+	require.ErrorContains(t, err, "line 2")
+}
+
+func TestPriorityMapping(t *testing.T) {
+	for p, d := range map[byte]float64{'A': 2, 'B': 1.5, 0: 1, 'C': 0.1, 'Z': 0.1} {
+		require.Equal(t, d, priorityToDifficulty(p))
+		require.Equal(t, normalizePriority(p), difficultyToPriority(d))
+	}
+}
@@ -1,11 +1,141 @@
 package habitica
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Common structures that can be reused across multiple domains.
 
 // UUID is an alias for IDs represented as UUID strings.
 type UUID string
 
-// Timestamp is the ISO8601 timestamp as typically returned by the Habitica API.
-// We keep it as a string to avoid making assumptions about the exact format.
+// Timestamp is a point in time as returned by the Habitica API.
+// Most fields are ISO 8601 strings, a few (e.g. the habit history) are epoch
+// milliseconds. The original representation is kept so that values round-trip
+// unchanged, except that null and "" are both written as null and strings of
+// digits as numbers; use Time to convert it into a time.Time.
 type Timestamp string
 
+// timestampLayouts lists the string formats Habitica is known to emit.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"Mon Jan 02 2006 15:04:05 GMT-0700",
+	time.RFC1123,
+	time.RFC1123Z,
+}
+
+// NewTimestamp formats t the way Habitica does (UTC, millisecond precision).
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp(t.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+}
+
+// IsZero reports whether the timestamp is unset.
+func (ts Timestamp) IsZero() bool {
+	return strings.TrimSpace(string(ts)) == ""
+}
+
+// Time parses the timestamp. Numeric values are interpreted as epoch
+// milliseconds (or seconds for values too small to be milliseconds).
+// An unset timestamp yields the zero time without error.
+func (ts Timestamp) Time() (time.Time, error) {
+	s := strings.TrimSpace(string(ts))
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if ts.isNumeric() {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", s, err)
+		}
+		if f > 1e11 || f < -1e11 {
+			return time.UnixMilli(int64(f)).UTC(), nil
+		}
+		return time.Unix(int64(f), 0).UTC(), nil
+	}
+
+	// JavaScript's Date.toString appends the zone name in parentheses.
+	if i := strings.Index(s, " ("); i > 0 {
+		s = s[:i]
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q: unknown format", s)
+}
+
+// isNumeric reports whether the timestamp is an epoch value: an optional
+// sign followed by decimal digits with at most one '.'. Unlike
+// strconv.ParseFloat it rejects NaN, Inf, exponents and hex floats, which
+// are strings as far as Habitica is concerned.
+func (ts Timestamp) isNumeric() bool {
+	s := strings.TrimSpace(string(ts))
+	s = strings.TrimPrefix(s, "-")
+	digits, dot := 0, false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// UnmarshalJSON accepts JSON strings, numbers (epoch millis) and null.
+// Numbers in exponent notation are stored in decimal notation.
+func (ts *Timestamp) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		*ts = ""
+		return nil
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*ts = Timestamp(s)
+		return nil
+	default:
+		var n json.Number
+		if err := json.Unmarshal(b, &n); err != nil {
+			return fmt.Errorf("invalid timestamp %s", b)
+		}
+		*ts = Timestamp(n.String())
+		if !ts.isNumeric() {
+			f, err := n.Float64()
+			if err != nil {
+				return fmt.Errorf("invalid timestamp %s: %w", b, err)
+			}
+			*ts = Timestamp(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+		return nil
+	}
+}
+
+// MarshalJSON writes epoch values back as numbers, an unset timestamp as
+// null and everything else as string. Habitica reads a string of digits as
+// epoch milliseconds as well, so such strings are written as numbers.
+func (ts Timestamp) MarshalJSON() ([]byte, error) {
+	if ts.IsZero() {
+		return []byte("null"), nil
+	}
+	if ts.isNumeric() {
+		return []byte(strings.TrimSpace(string(ts))), nil
+	}
+	return json.Marshal(string(ts))
+}
@@ -0,0 +1,92 @@
+package habitica
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestamp_DecodeAndRoundTrip(t *testing.T) {
+	in := `{"createdAt":"2024-03-01T08:30:00.123Z","date":1709281800123,"updatedAt":null}`
+
+	var v struct {
+		CreatedAt Timestamp `json:"createdAt"`
+		Date      Timestamp `json:"date"`
+		UpdatedAt Timestamp `json:"updatedAt"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(in), &v))
+	require.Equal(t, Timestamp("2024-03-01T08:30:00.123Z"), v.CreatedAt)
+	require.Equal(t, Timestamp("1709281800123"), v.Date)
+	require.True(t, v.UpdatedAt.IsZero())
+
+	created, err := v.CreatedAt.Time()
+	require.NoError(t, err)
+	date, err := v.Date.Time()
+	require.NoError(t, err)
+	require.True(t, created.Equal(date))
+
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"createdAt":"2024-03-01T08:30:00.123Z","date":1709281800123,"updatedAt":null}`, string(out))
+}
+
+func TestTimestamp_RoundTripValues(t *testing.T) {
+	for in, want := range map[string]string{
+		`"NaN"`:           `"NaN"`,
+		`"Inf"`:           `"Inf"`,
+		`"-Infinity"`:     `"-Infinity"`,
+		`"0x1p-2"`:        `"0x1p-2"`,
+		`"1e3"`:           `"1e3"`,
+		`1709281800123`:   `1709281800123`,
+		`-1.5`:            `-1.5`,
+		`1.7e12`:          `1700000000000`,
+		`"1709281800123"`: `1709281800123`,
+		`null`:            `null`,
+		`""`:              `null`,
+	} {
+		var ts Timestamp
+		require.NoError(t, json.Unmarshal([]byte(in), &ts), in)
+		out, err := json.Marshal(ts)
+		require.NoError(t, err, in)
+		require.Equal(t, want, string(out), in)
+	}
+
+	_, err := Timestamp("NaN").Time()
+	require.ErrorContains(t, err, "unknown format")
+}
+
+func TestTimestamp_Time(t *testing.T) {
+	want := time.Date(2024, time.March, 1, 8, 30, 0, 0, time.UTC)
+
+	for _, ts := range []Timestamp{
+		"2024-03-01T08:30:00Z",
+		"2024-03-01T08:30:00.000Z",
+		"2024-03-01T09:30:00+01:00",
+		"2024-03-01T08:30:00",
+		"Fri Mar 01 2024 09:30:00 GMT+0100 (Central European Standard Time)",
+		"1709281800000",
+		"1709281800",
+	} {
+		got, err := ts.Time()
+		require.NoError(t, err, ts)
+		require.True(t, want.Equal(got), "%s: got %s", ts, got)
+	}
+
+	d, err := Timestamp("2024-03-01").Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), d)
+
+	zero, err := Timestamp("").Time()
+	require.NoError(t, err)
+	require.True(t, zero.IsZero())
+
+	_, err = Timestamp("yesterday").Time()
+	require.Error(t, err)
+}
+
+func TestNewTimestamp(t *testing.T) {
+	ts := NewTimestamp(time.Date(2024, time.March, 1, 9, 30, 0, 5e6, time.FixedZone("", 3600)))
+	require.Equal(t, Timestamp("2024-03-01T08:30:00.005Z"), ts)
+}
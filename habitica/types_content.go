@@ -1,13 +1,269 @@
 package habitica
 
+import "fmt"
+
 // Content represents the static content endpoint (/content).
-// The structure is intentionally simplified; in most clients, it is
-// sufficient to read only specific sub-sections as needed.
+// Only the sections relevant for typical clients are modeled; use the
+// lookup helpers (Gear, Egg, Quest, ...) to access single entries by key.
 type Content struct {
-	Items    map[string]any `json:"items"`
-	Pets     map[string]any `json:"pets"`
-	Mounts   map[string]any `json:"mounts"`
-	Quests   map[string]any `json:"quests"`
-	Backgrounds map[string]any `json:"backgrounds"`
+	Equipment       ContentGear                       `json:"gear"`
+	Eggs            map[string]*Egg                   `json:"eggs"`
+	HatchingPotions map[string]*HatchingPotion        `json:"hatchingPotions"`
+	Food            map[string]*Food                  `json:"food"`
+	Quests          map[string]*Quest                 `json:"quests"`
+	Spells          map[string]map[string]*Spell      `json:"spells"` // class -> spell key -> spell
+	Backgrounds     map[string]map[string]*Background `json:"backgrounds"`
+	BackgroundsFlat map[string]*Background            `json:"backgroundsFlat"`
+
+	// Pets and mounts are keyed by "<Egg>-<HatchingPotion>", e.g. "Wolf-Base".
+	Pets          map[string]bool   `json:"pets"`
+	Mounts        map[string]bool   `json:"mounts"`
+	QuestPets     map[string]bool   `json:"questPets"`
+	PremiumPets   map[string]bool   `json:"premiumPets"`
+	SpecialPets   map[string]string `json:"specialPets"`
+	SpecialMounts map[string]string `json:"specialMounts"`
+}
+
+// ContentGear holds all equipment. Flat is keyed by the gear key, e.g. "weapon_warrior_1".
+type ContentGear struct {
+	Flat map[string]*GearItem `json:"flat"`
+}
+
+// ContentEvent describes the availability window of limited content.
+type ContentEvent struct {
+	Start  Timestamp `json:"start"`
+	End    Timestamp `json:"end"`
+	Season string    `json:"season,omitempty"`
+}
+
+// Key returns the name Habitica gives the seasonal event, the season
+// followed by the year it ends in, e.g. "winter2024" for the event from
+// December 2023 to January 2024. It is empty if the season is unknown.
+func (e *ContentEvent) Key() string {
+	if e == nil || e.Season == "" {
+		return ""
+	}
+	end, err := e.End.Time()
+	if err != nil || end.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s%d", e.Season, end.Year())
+}
+
+// GearItem is a piece of equipment.
+type GearItem struct {
+	Key          string        `json:"key"`
+	Text         string        `json:"text"`
+	Notes        string        `json:"notes"`
+	Type         string        `json:"type"`  // weapon, armor, head, shield, back, body, headAccessory, eyewear
+	Klass        string        `json:"klass"` // warrior, rogue, wizard, healer, base, special, armoire, mystery
+	SpecialClass string        `json:"specialClass,omitempty"`
+	Set          string        `json:"set,omitempty"`
+	Index        string        `json:"index"`
+	Value        float64       `json:"value"` // gold
+	Str          float64       `json:"str"`
+	Int          float64       `json:"int"`
+	Per          float64       `json:"per"`
+	Con          float64       `json:"con"`
+	TwoHanded    bool          `json:"twoHanded,omitempty"`
+	Mystery      string        `json:"mystery,omitempty"`
+	Season       string        `json:"season,omitempty"`
+	Event        *ContentEvent `json:"event,omitempty"`
+}
+
+// Class returns the class that can buy the item: the special class for
+// seasonal gear, otherwise the gear's klass.
+func (g *GearItem) Class() string {
+	if g.SpecialClass != "" {
+		return g.SpecialClass
+	}
+	return g.Klass
+}
+
+// Egg is a pet egg.
+type Egg struct {
+	Key       string  `json:"key"`
+	Text      string  `json:"text"`
+	MountText string  `json:"mountText"`
+	Adjective string  `json:"adjective"`
+	Notes     string  `json:"notes"`
+	Value     float64 `json:"value"` // gems
+}
+
+// HatchingPotion colors a pet when hatching an egg.
+type HatchingPotion struct {
+	Key     string        `json:"key"`
+	Text    string        `json:"text"`
+	Notes   string        `json:"notes"`
+	Value   float64       `json:"value"` // gems
+	Premium bool          `json:"premium,omitempty"`
+	Limited bool          `json:"limited,omitempty"`
+	Wacky   bool          `json:"wacky,omitempty"`
+	Event   *ContentEvent `json:"event,omitempty"`
+}
+
+// Food is fed to pets to raise them into mounts.
+type Food struct {
+	Key     string        `json:"key"`
+	Text    string        `json:"text"`
+	TextA   string        `json:"textA"`
+	TextThe string        `json:"textThe"`
+	Notes   string        `json:"notes"`
+	Target  string        `json:"target,omitempty"` // preferred hatching potion
+	Value   float64       `json:"value"`            // gems
+	CanDrop bool          `json:"canDrop"`
+	Event   *ContentEvent `json:"event,omitempty"`
+}
+
+// Quest is a boss or collection quest.
+type Quest struct {
+	Key             string                      `json:"key"`
+	Text            string                      `json:"text"`
+	Notes           string                      `json:"notes"`
+	Completion      string                      `json:"completion"`
+	Category        string                      `json:"category"` // pet, unlockable, gold, hatchingPotion, world, ...
+	Value           float64                     `json:"value"`    // gems
+	GoldValue       float64                     `json:"goldValue,omitempty"`
+	Lvl             int                         `json:"lvl,omitempty"` // minimum level
+	Group           string                      `json:"group,omitempty"`
+	Boss            *QuestBoss                  `json:"boss,omitempty"`
+	Collect         map[string]QuestCollectGoal `json:"collect,omitempty"`
+	Drop            QuestDrop                   `json:"drop"`
+	UnlockCondition *QuestUnlockCondition       `json:"unlockCondition,omitempty"`
+	Event           *ContentEvent               `json:"event,omitempty"`
+}
+
+// QuestBoss describes the boss of a boss quest.
+type QuestBoss struct {
+	Name string     `json:"name"`
+	HP   float64    `json:"hp"`
+	Str  float64    `json:"str"`
+	Def  float64    `json:"def"`
+	Rage *QuestRage `json:"rage,omitempty"`
+}
+
+// QuestRage describes the rage mechanic of world bosses.
+type QuestRage struct {
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Value       float64 `json:"value"`
+}
+
+// QuestCollectGoal is an item that has to be collected in a collection quest.
+type QuestCollectGoal struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// QuestDrop lists the rewards of a quest.
+type QuestDrop struct {
+	GP     float64         `json:"gp"`
+	Exp    float64         `json:"exp"`
+	Unlock string          `json:"unlock,omitempty"`
+	Items  []QuestDropItem `json:"items,omitempty"`
 }
 
+// QuestDropItem is a single item reward of a quest.
+type QuestDropItem struct {
+	Type string `json:"type"` // eggs, hatchingPotions, food, gear, quests, mounts, pets
+	Key  string `json:"key"`
+	Text string `json:"text"`
+}
+
+// QuestUnlockCondition describes how a quest scroll is obtained.
+type QuestUnlockCondition struct {
+	Condition string `json:"condition"`
+	Text      string `json:"text"`
+}
+
+// Spell is a class skill or a special item cast like a skill.
+type Spell struct {
+	Key    string  `json:"key"`
+	Text   string  `json:"text"`
+	Notes  string  `json:"notes"`
+	Mana   float64 `json:"mana"`
+	Lvl    int     `json:"lvl"`
+	Target string  `json:"target"`          // self, user, party, task, tasks
+	Value  float64 `json:"value,omitempty"` // gold, special items only
+}
+
+// Background is a purchasable avatar background.
+type Background struct {
+	Key      string  `json:"key"`
+	Text     string  `json:"text"`
+	Notes    string  `json:"notes"`
+	Set      string  `json:"set"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+// Gear returns the equipment with the given key or nil.
+func (c *Content) Gear(key string) *GearItem {
+	return c.Equipment.Flat[key]
+}
+
+// Egg returns the egg with the given key or nil.
+func (c *Content) Egg(key string) *Egg {
+	return c.Eggs[key]
+}
+
+// HatchingPotion returns the hatching potion with the given key or nil.
+func (c *Content) HatchingPotion(key string) *HatchingPotion {
+	return c.HatchingPotions[key]
+}
+
+// FoodItem returns the food with the given key or nil.
+func (c *Content) FoodItem(key string) *Food {
+	return c.Food[key]
+}
+
+// Quest returns the quest with the given key or nil.
+func (c *Content) Quest(key string) *Quest {
+	return c.Quests[key]
+}
+
+// Spell returns the spell with the given key of any class or nil.
+func (c *Content) Spell(key string) *Spell {
+	for _, spells := range c.Spells {
+		if s, ok := spells[key]; ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// ClassSpells returns the spells of a class (warrior, rogue, wizard, healer or special).
+func (c *Content) ClassSpells(class string) map[string]*Spell {
+	return c.Spells[class]
+}
+
+// Background returns the background with the given key or nil.
+func (c *Content) Background(key string) *Background {
+	if bg, ok := c.BackgroundsFlat[key]; ok {
+		return bg
+	}
+	for _, set := range c.Backgrounds {
+		if bg, ok := set[key]; ok {
+			return bg
+		}
+	}
+	return nil
+}
+
+// HasPet reports whether a pet with the given key (e.g. "Wolf-Base") exists.
+func (c *Content) HasPet(key string) bool {
+	if c.Pets[key] || c.QuestPets[key] || c.PremiumPets[key] {
+		return true
+	}
+	_, ok := c.SpecialPets[key]
+	return ok
+}
+
+// HasMount reports whether a mount with the given key (e.g. "Wolf-Base") exists.
+func (c *Content) HasMount(key string) bool {
+	if c.Mounts[key] {
+		return true
+	}
+	_, ok := c.SpecialMounts[key]
+	return ok
+}
@@ -1,8 +1,52 @@
 package habitica
 
+import "strings"
+
 // Tag represents a tag that can be used to group tasks.
 type Tag struct {
-	ID   UUID   `json:"id"`
-	Name string `json:"name"`
+	ID        UUID   `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Challenge bool   `json:"challenge,omitempty"`
+}
+
+// TagIndex indexes tags by ID and by case-insensitive name.
+type TagIndex struct {
+	byID   map[UUID]*Tag
+	byName map[string]*Tag
+}
+
+// NewTagIndex builds an index of the given tags.
+func NewTagIndex(tags []*Tag) *TagIndex {
+	idx := &TagIndex{
+		byID:   make(map[UUID]*Tag, len(tags)),
+		byName: make(map[string]*Tag, len(tags)),
+	}
+	for _, t := range tags {
+		idx.add(t)
+	}
+	return idx
 }
 
+func (idx *TagIndex) add(t *Tag) {
+	idx.byID[t.ID] = t
+	key := strings.ToLower(t.Name)
+	if _, ok := idx.byName[key]; !ok {
+		idx.byName[key] = t
+	}
+}
+
+// Lookup returns the tag with the given ID or name, or nil.
+func (idx *TagIndex) Lookup(idOrName string) *Tag {
+	if t, ok := idx.byID[UUID(idOrName)]; ok {
+		return t
+	}
+	return idx.byName[strings.ToLower(strings.TrimSpace(idOrName))]
+}
+
+// Name returns the name of the tag with the given ID, or the ID itself if it is unknown.
+func (idx *TagIndex) Name(id UUID) string {
+	if t, ok := idx.byID[id]; ok {
+		return t.Name
+	}
+	return string(id)
+}
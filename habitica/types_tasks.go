@@ -1,5 +1,13 @@
 package habitica
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // TaskType describes the type of a task.
 type TaskType string
 
@@ -10,21 +18,67 @@ const (
 	TaskTypeReward TaskType = "reward"
 )
 
+// TaskFrequency describes how often a daily (or the counter of a habit) repeats.
+type TaskFrequency string
+
+const (
+	FrequencyDaily   TaskFrequency = "daily"
+	FrequencyWeekly  TaskFrequency = "weekly"
+	FrequencyMonthly TaskFrequency = "monthly"
+	FrequencyYearly  TaskFrequency = "yearly"
+)
+
 // Task represents a Habitica task.
+//
+// Which fields are populated depends on the task type: habits use Up, Down,
+// CounterUp, CounterDown and History; dailies use the scheduling fields
+// (Frequency, Repeat, EveryX, StartDate, DaysOfMonth, WeeksOfMonth), Streak,
+// IsDue, NextDue and History; todos use Date; rewards use Value as their price.
 type Task struct {
-	ID          UUID            `json:"_id"`
-	UserID      UUID            `json:"userId"`
-	Text        string          `json:"text"`
-	Notes       string          `json:"notes"`
-	Type        TaskType        `json:"type"`
-	Value       float64         `json:"value"`
-	Priority    float64         `json:"priority"`
-	CreatedAt   Timestamp       `json:"createdAt"`
-	UpdatedAt   Timestamp       `json:"updatedAt"`
-	Tags        []UUID          `json:"tags"`
-	Completed   bool            `json:"completed"`
-	Checklist   []ChecklistItem `json:"checklist"`
-	Attribute   string          `json:"attribute"` // str, int, con, per
+	ID        UUID            `json:"_id"`
+	UserID    UUID            `json:"userId"`
+	Alias     string          `json:"alias,omitempty"`
+	Text      string          `json:"text"`
+	Notes     string          `json:"notes"`
+	Type      TaskType        `json:"type"`
+	Value     float64         `json:"value"`
+	Priority  float64         `json:"priority"`
+	CreatedAt Timestamp       `json:"createdAt"`
+	UpdatedAt Timestamp       `json:"updatedAt"`
+	Tags      []UUID          `json:"tags"`
+	Completed bool            `json:"completed"`
+	Checklist []ChecklistItem `json:"checklist"`
+	Attribute string          `json:"attribute"` // str, int, con, per
+	Reminders []TaskReminder  `json:"reminders,omitempty"`
+
+	// Date is the due date of a todo.
+	Date          Timestamp `json:"date,omitempty"`
+	DateCompleted Timestamp `json:"dateCompleted,omitempty"`
+
+	// Habits.
+	Up          bool `json:"up"`
+	Down        bool `json:"down"`
+	CounterUp   int  `json:"counterUp"`
+	CounterDown int  `json:"counterDown"`
+
+	// Dailies.
+	Frequency    TaskFrequency `json:"frequency,omitempty"`
+	Repeat       *TaskRepeat   `json:"repeat,omitempty"`
+	EveryX       int           `json:"everyX"`
+	StartDate    Timestamp     `json:"startDate,omitempty"`
+	DaysOfMonth  []int         `json:"daysOfMonth,omitempty"`
+	WeeksOfMonth []int         `json:"weeksOfMonth,omitempty"`
+	Streak       int           `json:"streak"`
+	IsDue        bool          `json:"isDue"`
+	NextDue      []Timestamp   `json:"nextDue,omitempty"`
+	YesterDaily  bool          `json:"yesterDaily"`
+
+	// History of habits and dailies, oldest entry first.
+	History []TaskHistoryEntry `json:"history,omitempty"`
+
+	// Group and Challenge link the task to a group plan or challenge.
+	Group     *TaskGroup     `json:"group,omitempty"`
+	Challenge *TaskChallenge `json:"challenge,omitempty"`
 }
 
 // ChecklistItem is an entry of a todo or daily checklist.
@@ -34,24 +88,369 @@ type ChecklistItem struct {
 	Completed bool   `json:"completed,omitempty"`
 }
 
+// TaskRepeat lists the weekdays a weekly daily is due on.
+type TaskRepeat struct {
+	Monday    bool `json:"m"`
+	Tuesday   bool `json:"t"`
+	Wednesday bool `json:"w"`
+	Thursday  bool `json:"th"`
+	Friday    bool `json:"f"`
+	Saturday  bool `json:"s"`
+	Sunday    bool `json:"su"`
+}
+
+// RepeatOn returns a TaskRepeat enabling exactly the given weekdays.
+func RepeatOn(days ...time.Weekday) *TaskRepeat {
+	r := &TaskRepeat{}
+	for _, d := range days {
+		switch d {
+		case time.Monday:
+			r.Monday = true
+		case time.Tuesday:
+			r.Tuesday = true
+		case time.Wednesday:
+			r.Wednesday = true
+		case time.Thursday:
+			r.Thursday = true
+		case time.Friday:
+			r.Friday = true
+		case time.Saturday:
+			r.Saturday = true
+		case time.Sunday:
+			r.Sunday = true
+		}
+	}
+	return r
+}
+
+// On reports whether the given weekday is enabled.
+func (r *TaskRepeat) On(d time.Weekday) bool {
+	switch d {
+	case time.Monday:
+		return r.Monday
+	case time.Tuesday:
+		return r.Tuesday
+	case time.Wednesday:
+		return r.Wednesday
+	case time.Thursday:
+		return r.Thursday
+	case time.Friday:
+		return r.Friday
+	case time.Saturday:
+		return r.Saturday
+	case time.Sunday:
+		return r.Sunday
+	}
+	return false
+}
+
+// Days returns the enabled weekdays, Monday first. A nil TaskRepeat is due
+// every day, like a daily created without one.
+func (r *TaskRepeat) Days() []time.Weekday {
+	if r == nil {
+		return append([]time.Weekday(nil), weekdayOrder...)
+	}
+	var days []time.Weekday
+	for _, d := range weekdayOrder {
+		if r.On(d) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// String renders the enabled weekdays as ParseWeekdays accepts them, e.g.
+// "mon,wed,fri". Every day, including a nil TaskRepeat, is "all" and no day
+// is "none".
+func (r *TaskRepeat) String() string {
+	days := r.Days()
+	switch len(days) {
+	case 0:
+		return "none"
+	case len(weekdayOrder):
+		return "all"
+	}
+	names := make([]string, len(days))
+	for i, d := range days {
+		names[i] = strings.ToLower(d.String()[:3])
+	}
+	return strings.Join(names, ",")
+}
+
+// weekdayOrder is the order of the weekdays in Habitica, Monday first.
+var weekdayOrder = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// weekdayShortcuts are the names ParseWeekdays accepts for several days.
+var weekdayShortcuts = map[string][]time.Weekday{
+	"weekdays": weekdayOrder[:5],
+	"weekend":  weekdayOrder[5:],
+	"all":      weekdayOrder,
+	"none":     nil,
+}
+
+// ParseWeekdays returns a TaskRepeat enabling the named weekdays. Names are
+// case-insensitive and may be abbreviated to three letters or more ("mon",
+// "tues", "thursday"); "weekdays", "weekend", "all" and "none" name several
+// days or none. Empty names are skipped.
+func ParseWeekdays(names ...string) (*TaskRepeat, error) {
+	var days []time.Weekday
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			continue
+		}
+		if d, ok := weekdayShortcuts[key]; ok {
+			days = append(days, d...)
+			continue
+		}
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if len(key) >= 3 && strings.HasPrefix(strings.ToLower(d.String()), key) {
+				days = append(days, d)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid weekday %q; expected mon, tue, wed, thu, fri, sat, sun, weekdays, weekend, all or none", name)
+		}
+	}
+	return RepeatOn(days...), nil
+}
+
+// ParseDifficulty converts a difficulty into a task priority. It accepts the
+// names "trivial", "easy", "medium" and "hard" (case-insensitive) or a
+// number in (0, 2].
+func ParseDifficulty(s string) (float64, error) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	switch key {
+	case "trivial":
+		return 0.1, nil
+	case "easy":
+		return 1, nil
+	case "medium":
+		return 1.5, nil
+	case "hard":
+		return 2, nil
+	}
+	v, err := strconv.ParseFloat(key, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid difficulty %q; expected trivial, easy, medium, hard or a number", s)
+	}
+	if v <= 0 || v > 2 {
+		return 0, fmt.Errorf("difficulty %q is out of range; expected 0 < value <= 2", s)
+	}
+	return v, nil
+}
+
+// ParseDate parses a YYYY-MM-DD date as midnight in loc.
+func ParseDate(s string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(s), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q; expected YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+// TaskHistoryEntry records the value of a habit or daily at a point in time.
+// Habit entries carry ScoredUp/ScoredDown, daily entries IsDue/Completed.
+type TaskHistoryEntry struct {
+	Date       Timestamp `json:"date"`
+	Value      float64   `json:"value"`
+	ScoredUp   int       `json:"scoredUp,omitempty"`
+	ScoredDown int       `json:"scoredDown,omitempty"`
+	IsDue      *bool     `json:"isDue,omitempty"`
+	Completed  *bool     `json:"completed,omitempty"`
+}
+
+// TaskReminder is a reminder of a daily or todo. For dailies only the time
+// of day of Time is relevant.
+type TaskReminder struct {
+	ID        UUID      `json:"id,omitempty"`
+	StartDate Timestamp `json:"startDate,omitempty"`
+	Time      Timestamp `json:"time"`
+}
+
+// TaskGroup links a task to a group plan.
+type TaskGroup struct {
+	ID               UUID          `json:"id,omitempty"`
+	TaskID           UUID          `json:"taskId,omitempty"`
+	AssignedUsers    []UUID        `json:"assignedUsers,omitempty"`
+	ManagerNotes     string        `json:"managerNotes,omitempty"`
+	SharedCompletion string        `json:"sharedCompletion,omitempty"`
+	Approval         *TaskApproval `json:"approval,omitempty"`
+}
+
+// TaskApproval is the approval state of a group task.
+type TaskApproval struct {
+	Required      bool      `json:"required"`
+	Requested     bool      `json:"requested"`
+	Approved      bool      `json:"approved"`
+	RequestedDate Timestamp `json:"requestedDate,omitempty"`
+	DateApproved  Timestamp `json:"dateApproved,omitempty"`
+	ApprovingUser UUID      `json:"approvingUser,omitempty"`
+}
+
+// TaskChallenge links a task to a challenge. Broken is set when the
+// challenge or the task was deleted ("CHALLENGE_DELETED", "TASK_DELETED", ...).
+type TaskChallenge struct {
+	ID        UUID   `json:"id,omitempty"`
+	TaskID    UUID   `json:"taskId,omitempty"`
+	ShortName string `json:"shortName,omitempty"`
+	Broken    string `json:"broken,omitempty"`
+	Winner    string `json:"winner,omitempty"`
+}
+
 // TaskCreateRequest describes the fields used to create a new task.
+// Up and Down are pointers because Habitica enables both for new habits.
 type TaskCreateRequest struct {
 	Text      string          `json:"text"`
 	Notes     string          `json:"notes,omitempty"`
 	Type      TaskType        `json:"type"`
+	Alias     string          `json:"alias,omitempty"`
 	Priority  float64         `json:"priority,omitempty"`
+	Value     float64         `json:"value,omitempty"` // price of a reward
 	Tags      []UUID          `json:"tags,omitempty"`
 	Checklist []ChecklistItem `json:"checklist,omitempty"`
 	Attribute string          `json:"attribute,omitempty"`
+	Reminders []TaskReminder  `json:"reminders,omitempty"`
+	Date      Timestamp       `json:"date,omitempty"`
+
+	Up   *bool `json:"up,omitempty"`
+	Down *bool `json:"down,omitempty"`
+
+	Frequency    TaskFrequency `json:"frequency,omitempty"`
+	Repeat       *TaskRepeat   `json:"repeat,omitempty"`
+	EveryX       int           `json:"everyX,omitempty"`
+	StartDate    Timestamp     `json:"startDate,omitempty"`
+	DaysOfMonth  []int         `json:"daysOfMonth,omitempty"`
+	WeeksOfMonth []int         `json:"weeksOfMonth,omitempty"`
 }
 
 // TaskUpdateRequest describes the fields used to update an existing task.
 type TaskUpdateRequest struct {
 	Text      *string          `json:"text,omitempty"`
 	Notes     *string          `json:"notes,omitempty"`
+	Alias     *string          `json:"alias,omitempty"`
 	Priority  *float64         `json:"priority,omitempty"`
+	Value     *float64         `json:"value,omitempty"`
 	Tags      *[]UUID          `json:"tags,omitempty"`
 	Checklist *[]ChecklistItem `json:"checklist,omitempty"`
 	Attribute *string          `json:"attribute,omitempty"`
+	Reminders *[]TaskReminder  `json:"reminders,omitempty"`
+	Date      *Timestamp       `json:"date,omitempty"`
+
+	Up          *bool `json:"up,omitempty"`
+	Down        *bool `json:"down,omitempty"`
+	CounterUp   *int  `json:"counterUp,omitempty"`
+	CounterDown *int  `json:"counterDown,omitempty"`
+
+	Frequency    *TaskFrequency `json:"frequency,omitempty"`
+	Repeat       *TaskRepeat    `json:"repeat,omitempty"`
+	EveryX       *int           `json:"everyX,omitempty"`
+	StartDate    *Timestamp     `json:"startDate,omitempty"`
+	DaysOfMonth  *[]int         `json:"daysOfMonth,omitempty"`
+	WeeksOfMonth *[]int         `json:"weeksOfMonth,omitempty"`
+	Streak       *int           `json:"streak,omitempty"`
+}
+
+// ScoreResult is the response of scoring a task. The stat fields hold the
+// user's stats after scoring; use Diff to obtain the gains.
+type ScoreResult struct {
+	// Delta is the change of the task's value.
+	Delta  float64  `json:"delta"`
+	HP     float64  `json:"hp"`
+	MP     float64  `json:"mp"`
+	Exp    float64  `json:"exp"`
+	GP     float64  `json:"gp"`
+	Lvl    int      `json:"lvl"`
+	Class  string   `json:"class"`
+	Points int      `json:"points"`
+	Tmp    ScoreTmp `json:"_tmp"`
+}
+
+// ScoreTmp holds the side effects of scoring reported in "_tmp".
+type ScoreTmp struct {
+	// Crit is the critical hit multiplier, e.g. 1.5; zero without a crit.
+	Crit        float64             `json:"crit,omitempty"`
+	StreakBonus float64             `json:"streakBonus,omitempty"`
+	Drop        *ScoreDrop          `json:"drop,omitempty"`
+	Quest       *ScoreQuestProgress `json:"quest,omitempty"`
+	LeveledUp   *ScoreLevelUp       `json:"leveledUp,omitempty"`
+}
+
+// ScoreDrop is an item found while scoring.
+type ScoreDrop struct {
+	Key    string  `json:"key"`
+	Type   string  `json:"type"` // Egg, HatchingPotion, Food
+	Dialog string  `json:"dialog,omitempty"`
+	Value  float64 `json:"value,omitempty"`
+}
+
+// Name returns a short display name such as "Wolf Egg" or "Golden Hatching Potion".
+func (d *ScoreDrop) Name() string {
+	switch d.Type {
+	case "Egg":
+		return d.Key + " Egg"
+	case "HatchingPotion":
+		return d.Key + " Hatching Potion"
+	}
+	return d.Key
+}
+
+// ScoreQuestProgress is the progress made on the active quest.
+type ScoreQuestProgress struct {
+	// ProgressDelta is the damage dealt to the boss.
+	ProgressDelta float64 `json:"progressDelta"`
+	// Collection is the number of quest items found.
+	Collection int `json:"collection"`
+}
+
+// ScoreLevelUp reports a level-up caused by scoring.
+type ScoreLevelUp struct {
+	InitialLvl int `json:"initialLvl"`
+	NewLvl     int `json:"newLvl"`
+}
+
+// UnmarshalJSON accepts a single object as well as a list of level-ups,
+// in which case they are merged into one.
+func (l *ScoreLevelUp) UnmarshalJSON(b []byte) error {
+	type plain ScoreLevelUp
+	if len(b) > 0 && b[0] == '[' {
+		var all []plain
+		if err := json.Unmarshal(b, &all); err != nil {
+			return err
+		}
+		if len(all) > 0 {
+			*l = ScoreLevelUp{InitialLvl: all[0].InitialLvl, NewLvl: all[len(all)-1].NewLvl}
+		}
+		return nil
+	}
+	return json.Unmarshal(b, (*plain)(l))
+}
+
+// StatsDelta is the change of the user's stats caused by scoring.
+type StatsDelta struct {
+	HP     float64
+	MP     float64
+	Exp    float64
+	GP     float64
+	Levels int
 }
 
+// Diff computes the gains relative to the stats before scoring. Experience
+// resets on a level-up; the experience gained is then derived from
+// before.ToNextLevel, which is exact for a single level-up.
+func (r *ScoreResult) Diff(before *UserStats) StatsDelta {
+	d := StatsDelta{
+		HP:     r.HP - before.HP,
+		MP:     r.MP - before.MP,
+		Exp:    r.Exp - before.Exp,
+		GP:     r.GP - before.GP,
+		Levels: r.Lvl - before.Lvl,
+	}
+	if d.Levels > 0 && before.ToNextLevel > 0 {
+		d.Exp = before.ToNextLevel - before.Exp + r.Exp
+	}
+	return d
+}
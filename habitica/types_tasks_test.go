@@ -0,0 +1,199 @@
+package habitica
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func loadTasksFixture(t *testing.T) []*Task {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/tasks.json")
+	require.NoError(t, err)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(raw)
+	}
+	client, srv := newTestClient(t, handler)
+	t.Cleanup(srv.Close)
+
+	tasks, err := client.Tasks.ListUserTasks(context.Background(), TasksFilter{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 4)
+	return tasks
+}
+
+func TestTask_HabitFields(t *testing.T) {
+	habit := loadTasksFixture(t)[0]
+
+	require.Equal(t, TaskTypeHabit, habit.Type)
+	require.Equal(t, "floss", habit.Alias)
+	require.True(t, habit.Up)
+	require.False(t, habit.Down)
+	require.Equal(t, 3, habit.CounterUp)
+	require.Equal(t, FrequencyWeekly, habit.Frequency)
+	require.Len(t, habit.History, 2)
+	require.Equal(t, 2, habit.History[1].ScoredUp)
+	require.Nil(t, habit.History[1].IsDue)
+
+	date, err := habit.History[0].Date.Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), date)
+
+	// An empty challenge object means the task is not part of a challenge.
+	require.NotNil(t, habit.Challenge)
+	require.Empty(t, habit.Challenge.ID)
+	require.Equal(t, "singleCompletion", habit.Group.SharedCompletion)
+}
+
+func TestTask_DailyFields(t *testing.T) {
+	daily := loadTasksFixture(t)[1]
+
+	require.Equal(t, TaskTypeDaily, daily.Type)
+	require.Equal(t, FrequencyWeekly, daily.Frequency)
+	require.Equal(t, 1, daily.EveryX)
+	require.Equal(t, &TaskRepeat{Monday: true, Wednesday: true, Friday: true}, daily.Repeat)
+	require.True(t, daily.Repeat.On(time.Wednesday))
+	require.False(t, daily.Repeat.On(time.Sunday))
+	require.Equal(t, Timestamp("2024-01-15T00:00:00.000Z"), daily.StartDate)
+	require.Empty(t, daily.DaysOfMonth)
+	require.Equal(t, 4, daily.Streak)
+	require.True(t, daily.IsDue)
+	require.True(t, daily.YesterDaily)
+
+	require.Len(t, daily.NextDue, 2)
+	next, err := daily.NextDue[0].Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.March, 3, 23, 0, 0, 0, time.UTC), next.UTC())
+
+	require.Len(t, daily.History, 1)
+	require.NotNil(t, daily.History[0].IsDue)
+	require.True(t, *daily.History[0].IsDue)
+	require.False(t, *daily.History[0].Completed)
+
+	require.Len(t, daily.Reminders, 1)
+	require.Equal(t, Timestamp("2024-01-15T06:30:00.000Z"), daily.Reminders[0].Time)
+
+	require.Equal(t, "fit", daily.Challenge.ShortName)
+	require.Equal(t, UUID("5c1a2b3c-4d5e-4f60-8a9b-0c1d2e3f4a5b"), daily.Challenge.ID)
+}
+
+func TestTask_TodoAndRewardFields(t *testing.T) {
+	tasks := loadTasksFixture(t)
+	todo, reward := tasks[2], tasks[3]
+
+	due, err := todo.Date.Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.April, 30, 22, 0, 0, 0, time.UTC), due)
+	require.Equal(t, []UUID{"b0413351-405f-416f-8787-947ec1c85199"}, todo.Group.AssignedUsers)
+	require.True(t, todo.Group.Approval.Required)
+	require.True(t, todo.Group.Approval.Requested)
+	require.Equal(t, "before May", todo.Group.ManagerNotes)
+
+	require.Equal(t, TaskTypeReward, reward.Type)
+	require.Equal(t, 25.0, reward.Value)
+}
+
+func TestTaskCreateRequest_Daily(t *testing.T) {
+	no := false
+	raw, err := json.Marshal(&TaskCreateRequest{
+		Text:      "Read",
+		Type:      TaskTypeDaily,
+		Frequency: FrequencyWeekly,
+		EveryX:    1,
+		Repeat:    RepeatOn(time.Monday, time.Sunday),
+		StartDate: "2024-03-01T00:00:00.000Z",
+		Down:      &no,
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"text": "Read",
+		"type": "daily",
+		"frequency": "weekly",
+		"everyX": 1,
+		"repeat": {"m": true, "t": false, "w": false, "th": false, "f": false, "s": false, "su": true},
+		"startDate": "2024-03-01T00:00:00.000Z",
+		"down": false
+	}`, string(raw))
+}
+
+func TestTaskUpdateRequest_OnlySetFields(t *testing.T) {
+	streak := 0
+	days := []int{1, 15}
+	freq := FrequencyMonthly
+	raw, err := json.Marshal(&TaskUpdateRequest{
+		Frequency:   &freq,
+		DaysOfMonth: &days,
+		Streak:      &streak,
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"frequency": "monthly", "daysOfMonth": [1, 15], "streak": 0}`, string(raw))
+}
+
+func TestParseWeekdays(t *testing.T) {
+	r, err := ParseWeekdays("Mon", "tues", "thursday", "", "weekend")
+	require.NoError(t, err)
+	require.Equal(t, RepeatOn(time.Monday, time.Tuesday, time.Thursday, time.Saturday, time.Sunday), r)
+
+	r, err = ParseWeekdays("weekdays")
+	require.NoError(t, err)
+	require.Equal(t, RepeatOn(time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday), r)
+
+	r, err = ParseWeekdays()
+	require.NoError(t, err)
+	require.Equal(t, RepeatOn(), r)
+
+	for _, name := range []string{"mo", "monkey", "funday"} {
+		_, err = ParseWeekdays(name)
+		require.ErrorContains(t, err, "invalid weekday", name)
+	}
+}
+
+func TestTaskRepeat_String(t *testing.T) {
+	var unset *TaskRepeat
+	require.Len(t, unset.Days(), 7)
+	require.Equal(t, time.Monday, unset.Days()[0])
+
+	for r, want := range map[*TaskRepeat]string{
+		unset:                                  "all",
+		RepeatOn():                             "none",
+		RepeatOn(time.Sunday, time.Monday):     "mon,sun",
+		RepeatOn(time.Friday, time.Wednesday):  "wed,fri",
+		RepeatOn(weekdayOrder...):              "all",
+		RepeatOn(time.Saturday, time.Thursday): "thu,sat",
+	} {
+		require.Equal(t, want, r.String())
+		parsed, err := ParseWeekdays(strings.Split(want, ",")...)
+		require.NoError(t, err, want)
+		require.Equal(t, r.Days(), parsed.Days(), want)
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	for in, want := range map[string]float64{"trivial": 0.1, " Easy ": 1, "MEDIUM": 1.5, "hard": 2, "0.5": 0.5} {
+		p, err := ParseDifficulty(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, p, in)
+	}
+	_, err := ParseDifficulty("epic")
+	require.ErrorContains(t, err, "invalid difficulty")
+	_, err = ParseDifficulty("3")
+	require.ErrorContains(t, err, "out of range")
+}
+
+func TestParseDate(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	d, err := ParseDate(" 2024-03-01 ", loc)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, loc), d)
+
+	_, err = ParseDate("01.03.2024", loc)
+	require.ErrorContains(t, err, "expected YYYY-MM-DD")
+}
@@ -33,6 +33,10 @@ type UserStats struct {
 	GP              float64  `json:"gp"`
 	Lvl             int      `json:"lvl"`
 	Points          int      `json:"points"`
+	Str             int      `json:"str"`
+	Con             int      `json:"con"`
+	Int             int      `json:"int"`
+	Per             int      `json:"per"`
 	MaxHP           float64  `json:"maxHealth"`
 	MaxMP           float64  `json:"maxMP"`
 	Buffs           map[string]any `json:"buffs"`
@@ -1,13 +1,165 @@
 package habitica
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WebhookType selects the events a webhook is sent for.
+type WebhookType string
+
+const (
+	WebhookTaskActivity      WebhookType = "taskActivity"
+	WebhookGroupChatReceived WebhookType = "groupChatReceived"
+	WebhookUserActivity      WebhookType = "userActivity"
+	WebhookQuestActivity     WebhookType = "questActivity"
+	WebhookGlobalActivity    WebhookType = "globalActivity"
+)
+
 // Webhook represents a registered Habitica webhook.
 type Webhook struct {
-	ID        UUID     `json:"id"`
-	URL       string   `json:"url"`
-	Enabled   bool     `json:"enabled"`
-	Label     string   `json:"label"`
-	Type      string   `json:"type"`
-	Options   map[string]any `json:"options"`
-	CreatedAt Timestamp `json:"createdAt"`
+	ID      UUID        `json:"id"`
+	URL     string      `json:"url"`
+	Enabled bool        `json:"enabled"`
+	Label   string      `json:"label"`
+	Type    WebhookType `json:"type"`
+	// Options holds the options of the webhook type, e.g.
+	// *TaskActivityOptions; it is nil for globalActivity webhooks.
+	Options WebhookOptions `json:"options,omitempty"`
+	// Failures counts the failed deliveries since the last success;
+	// Habitica disables webhooks that keep failing.
+	Failures      int       `json:"failures"`
+	LastFailureAt Timestamp `json:"lastFailureAt,omitempty"`
+	CreatedAt     Timestamp `json:"createdAt"`
+	UpdatedAt     Timestamp `json:"updatedAt"`
+}
+
+// UnmarshalJSON decodes the options according to the webhook type.
+func (w *Webhook) UnmarshalJSON(b []byte) error {
+	type plain Webhook
+	var raw struct {
+		plain
+		Options json.RawMessage `json:"options"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*w = Webhook(raw.plain)
+	opts, err := decodeWebhookOptions(w.Type, raw.Options)
+	if err != nil {
+		return err
+	}
+	w.Options = opts
+	return nil
+}
+
+// WebhookOptions are the type-specific options of a webhook. The
+// implementations are TaskActivityOptions, GroupChatReceivedOptions,
+// UserActivityOptions, QuestActivityOptions and RawWebhookOptions.
+type WebhookOptions interface {
+	// WebhookType returns the webhook type the options belong to.
+	WebhookType() WebhookType
+}
+
+// TaskActivityOptions selects the task events of a taskActivity webhook.
+// Habitica defaults to Scored only.
+type TaskActivityOptions struct {
+	Created         bool `json:"created"`
+	Updated         bool `json:"updated"`
+	Deleted         bool `json:"deleted"`
+	Scored          bool `json:"scored"`
+	ChecklistScored bool `json:"checklistScored"`
+}
+
+func (*TaskActivityOptions) WebhookType() WebhookType { return WebhookTaskActivity }
+
+// GroupChatReceivedOptions selects the group of a groupChatReceived webhook.
+type GroupChatReceivedOptions struct {
+	GroupID UUID `json:"groupId"`
+}
+
+func (*GroupChatReceivedOptions) WebhookType() WebhookType { return WebhookGroupChatReceived }
+
+// UserActivityOptions selects the events of a userActivity webhook.
+type UserActivityOptions struct {
+	PetHatched  bool `json:"petHatched"`
+	MountRaised bool `json:"mountRaised"`
+	LeveledUp   bool `json:"leveledUp"`
 }
 
+func (*UserActivityOptions) WebhookType() WebhookType { return WebhookUserActivity }
+
+// QuestActivityOptions selects the events of a questActivity webhook.
+type QuestActivityOptions struct {
+	QuestStarted  bool `json:"questStarted"`
+	QuestFinished bool `json:"questFinished"`
+	QuestInvited  bool `json:"questInvited"`
+}
+
+func (*QuestActivityOptions) WebhookType() WebhookType { return WebhookQuestActivity }
+
+// RawWebhookOptions keeps the options of webhook types this package does
+// not know unchanged.
+type RawWebhookOptions struct {
+	Type WebhookType
+	Raw  json.RawMessage
+}
+
+func (o *RawWebhookOptions) WebhookType() WebhookType { return o.Type }
+
+// MarshalJSON writes the options as received.
+func (o *RawWebhookOptions) MarshalJSON() ([]byte, error) {
+	if len(o.Raw) == 0 {
+		return []byte("{}"), nil
+	}
+	return o.Raw, nil
+}
+
+// decodeWebhookOptions decodes the options of a webhook of the given type.
+// Missing options and globalActivity webhooks yield nil.
+func decodeWebhookOptions(typ WebhookType, raw json.RawMessage) (WebhookOptions, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var opts WebhookOptions
+	switch typ {
+	case WebhookTaskActivity, "":
+		opts = &TaskActivityOptions{}
+	case WebhookGroupChatReceived:
+		opts = &GroupChatReceivedOptions{}
+	case WebhookUserActivity:
+		opts = &UserActivityOptions{}
+	case WebhookQuestActivity:
+		opts = &QuestActivityOptions{}
+	case WebhookGlobalActivity:
+		return nil, nil
+	default:
+		return &RawWebhookOptions{Type: typ, Raw: append(json.RawMessage(nil), raw...)}, nil
+	}
+	if err := json.Unmarshal(raw, opts); err != nil {
+		return nil, fmt.Errorf("invalid %s webhook options: %w", typ, err)
+	}
+	return opts, nil
+}
+
+// WebhookCreateRequest is the payload of CreateWebhook. Type defaults to the
+// type of Options, or taskActivity; Enabled defaults to true.
+type WebhookCreateRequest struct {
+	// ID is optional; Habitica generates one when empty.
+	ID      UUID           `json:"id,omitempty"`
+	URL     string         `json:"url"`
+	Label   string         `json:"label,omitempty"`
+	Enabled *bool          `json:"enabled,omitempty"`
+	Type    WebhookType    `json:"type,omitempty"`
+	Options WebhookOptions `json:"options,omitempty"`
+}
+
+// WebhookUpdateRequest is the payload of UpdateWebhook; nil fields are left
+// unchanged. Options replace the current options and must match the type.
+type WebhookUpdateRequest struct {
+	URL     *string        `json:"url,omitempty"`
+	Label   *string        `json:"label,omitempty"`
+	Enabled *bool          `json:"enabled,omitempty"`
+	Type    WebhookType    `json:"type,omitempty"`
+	Options WebhookOptions `json:"options,omitempty"`
+}
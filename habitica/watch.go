@@ -0,0 +1,260 @@
+package habitica
+
+import (
+	"context"
+	"time"
+)
+
+// WatchEvent is a change detected by a Watcher: *TaskCompleted,
+// *ChecklistTicked, *HabitScored, *LevelUp, *HPLost, *GoldGained or, for
+// failed polls, *PollFailed.
+type WatchEvent interface {
+	watchEvent()
+}
+
+// TaskCompleted reports a todo or daily that was checked off.
+type TaskCompleted struct {
+	Task *Task
+}
+
+// ChecklistTicked reports a checklist item that was checked.
+type ChecklistTicked struct {
+	Task *Task
+	Item ChecklistItem
+}
+
+// HabitScored reports a habit whose counters went up. Up and Down are the
+// number of clicks on the + and - buttons since the last poll.
+type HabitScored struct {
+	Task *Task
+	Up   int
+	Down int
+}
+
+// LevelUp reports a gained level.
+type LevelUp struct {
+	From int
+	To   int
+}
+
+// HPLost reports a loss of health, e.g. from missed dailies or negative habits.
+type HPLost struct {
+	From float64
+	To   float64
+}
+
+// Amount returns the health lost.
+func (e *HPLost) Amount() float64 { return e.From - e.To }
+
+// GoldGained reports an increase of gold.
+type GoldGained struct {
+	From float64
+	To   float64
+}
+
+// Amount returns the gold gained.
+func (e *GoldGained) Amount() float64 { return e.To - e.From }
+
+// PollFailed reports a poll that failed; the next one is attempted after Retry.
+type PollFailed struct {
+	Err   error
+	Retry time.Duration
+}
+
+func (*TaskCompleted) watchEvent()   {}
+func (*ChecklistTicked) watchEvent() {}
+func (*HabitScored) watchEvent()     {}
+func (*LevelUp) watchEvent()         {}
+func (*HPLost) watchEvent()          {}
+func (*GoldGained) watchEvent()      {}
+func (*PollFailed) watchEvent()      {}
+
+// WatchOptions configures a Watcher.
+type WatchOptions struct {
+	// Interval is the time between polls; default one minute. Every poll
+	// makes three requests.
+	Interval time.Duration
+	// MaxBackoff caps the delay after failed polls, which doubles from
+	// Interval with every failure; default 15 minutes.
+	MaxBackoff time.Duration
+	// Buffer is the capacity of the channel returned by Watch; default 16.
+	Buffer int
+}
+
+// Watcher detects changes by polling, for setups that cannot receive
+// webhooks. Every poll snapshots the user (GetCurrent) and the tasks
+// (ListUserTasks, including recently completed todos) and compares the
+// snapshot with the previous one.
+//
+// Changes between two polls are merged: a task that is completed and
+// uncompleted again in between is not reported.
+type Watcher struct {
+	client *Client
+	opts   WatchOptions
+	prev   *watchSnapshot
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// watchSnapshot is the state compared by a Watcher.
+type watchSnapshot struct {
+	stats UserStats
+	tasks []*Task
+	byID  map[UUID]*Task
+}
+
+// NewWatcher creates a Watcher; the first poll only records the baseline.
+func NewWatcher(client *Client, opts WatchOptions) *Watcher {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 15 * time.Minute
+	}
+	if opts.MaxBackoff < opts.Interval {
+		opts.MaxBackoff = opts.Interval
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 16
+	}
+	return &Watcher{client: client, opts: opts, sleep: sleepContext}
+}
+
+// Poll takes a snapshot and returns the changes since the previous
+// successful poll. The first poll returns no events. Poll must not be
+// called concurrently or while Watch is running.
+func (w *Watcher) Poll(ctx context.Context) ([]WatchEvent, error) {
+	cur, err := w.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prev := w.prev
+	w.prev = cur
+	if prev == nil {
+		return nil, nil
+	}
+	return diffSnapshots(prev, cur), nil
+}
+
+// Watch polls until ctx is done and sends the changes to the returned
+// channel, which is closed afterwards. Failed polls are reported as
+// *PollFailed and retried with exponential backoff.
+func (w *Watcher) Watch(ctx context.Context) <-chan WatchEvent {
+	ch := make(chan WatchEvent, w.opts.Buffer)
+	go func() {
+		defer close(ch)
+
+		send := func(e WatchEvent) bool {
+			select {
+			case ch <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		failures := 0
+		for {
+			delay := w.opts.Interval
+			events, err := w.Poll(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				delay = w.backoff(failures)
+				failures++
+				events = []WatchEvent{&PollFailed{Err: err, Retry: delay}}
+			} else {
+				failures = 0
+			}
+			for _, e := range events {
+				if !send(e) {
+					return
+				}
+			}
+			if w.sleep(ctx, delay) != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// backoff returns the delay after the given number of earlier consecutive failures.
+func (w *Watcher) backoff(failures int) time.Duration {
+	d := w.opts.Interval
+	for i := 0; i <= failures && d < w.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > w.opts.MaxBackoff {
+		d = w.opts.MaxBackoff
+	}
+	return d
+}
+
+func (w *Watcher) snapshot(ctx context.Context) (*watchSnapshot, error) {
+	user, err := w.client.User.GetCurrent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Completed todos are not part of the active tasks.
+	active, err := w.client.Tasks.ListUserTasks(ctx, TasksFilter{})
+	if err != nil {
+		return nil, err
+	}
+	completed, err := w.client.Tasks.ListUserTasks(ctx, TasksFilter{Type: "completedTodos"})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &watchSnapshot{stats: user.Stats, tasks: append(active, completed...), byID: make(map[UUID]*Task)}
+	for _, t := range s.tasks {
+		s.byID[t.ID] = t
+	}
+	return s, nil
+}
+
+// diffSnapshots returns the changes from prev to cur: task events in the
+// order of the task lists, followed by the stat changes.
+func diffSnapshots(prev, cur *watchSnapshot) []WatchEvent {
+	var events []WatchEvent
+
+	for _, t := range cur.tasks {
+		old := prev.byID[t.ID]
+		if old == nil {
+			continue
+		}
+		if t.Type == TaskTypeHabit {
+			up, down := t.CounterUp-old.CounterUp, t.CounterDown-old.CounterDown
+			// Counters reset at the start of a new period; a drop is no click.
+			if up > 0 || down > 0 {
+				events = append(events, &HabitScored{Task: t, Up: max(up, 0), Down: max(down, 0)})
+			}
+			continue
+		}
+
+		was := make(map[UUID]bool, len(old.Checklist))
+		for _, item := range old.Checklist {
+			was[item.ID] = item.Completed
+		}
+		for _, item := range t.Checklist {
+			if done, ok := was[item.ID]; ok && !done && item.Completed {
+				events = append(events, &ChecklistTicked{Task: t, Item: item})
+			}
+		}
+		if !old.Completed && t.Completed {
+			events = append(events, &TaskCompleted{Task: t})
+		}
+	}
+
+	if cur.stats.Lvl > prev.stats.Lvl {
+		events = append(events, &LevelUp{From: prev.stats.Lvl, To: cur.stats.Lvl})
+	}
+	if cur.stats.HP < prev.stats.HP {
+		events = append(events, &HPLost{From: prev.stats.HP, To: cur.stats.HP})
+	}
+	if cur.stats.GP > prev.stats.GP {
+		events = append(events, &GoldGained{From: prev.stats.GP, To: cur.stats.GP})
+	}
+	return events
+}
@@ -0,0 +1,130 @@
+package habitica
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// watchState is the account served to a Watcher in tests.
+type watchState struct {
+	mu        sync.Mutex
+	stats     UserStats
+	active    []*Task
+	completed []*Task
+	failures  int
+}
+
+func (s *watchState) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var data any
+	switch {
+	case r.URL.Path == "/user":
+		data = User{ID: "user-id", Stats: s.stats}
+	case r.URL.Path == "/tasks/user" && r.URL.Query().Get("type") == "completedTodos":
+		data = s.completed
+	case r.URL.Path == "/tasks/user":
+		data = s.active
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(APIResponse[any]{Success: true, Data: data})
+}
+
+func (s *watchState) update(fn func(s *watchState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s)
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	state := &watchState{
+		stats: UserStats{Lvl: 11, HP: 50, GP: 100},
+		active: []*Task{
+			{ID: "habit", Type: TaskTypeHabit, Text: "Water", CounterUp: 2},
+			{ID: "daily", Type: TaskTypeDaily, Text: "Standup", Checklist: []ChecklistItem{{ID: "i1", Text: "Notes"}}},
+			{ID: "todo", Type: TaskTypeTodo, Text: "Report", Checklist: []ChecklistItem{{ID: "i2", Text: "Outline"}, {ID: "i3", Text: "Draft"}}},
+		},
+	}
+	client, srv := newTestClient(t, state.serve)
+	defer srv.Close()
+	ctx := context.Background()
+
+	w := NewWatcher(client, WatchOptions{})
+	events, err := w.Poll(ctx)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	events, err = w.Poll(ctx)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	state.update(func(s *watchState) {
+		s.active[0] = &Task{ID: "habit", Type: TaskTypeHabit, Text: "Water", CounterUp: 4, CounterDown: 1}
+		s.active[1] = &Task{ID: "daily", Type: TaskTypeDaily, Text: "Standup", Completed: true, Checklist: []ChecklistItem{{ID: "i1", Text: "Notes", Completed: true}}}
+		// The completed todo moves to the completed list.
+		s.completed = []*Task{{ID: "todo", Type: TaskTypeTodo, Text: "Report", Completed: true, Checklist: []ChecklistItem{{ID: "i2", Text: "Outline"}, {ID: "i3", Text: "Draft", Completed: true}}}}
+		s.active = s.active[:2]
+		s.stats = UserStats{Lvl: 12, HP: 42.5, GP: 112.25}
+	})
+
+	events, err = w.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 8)
+
+	require.Equal(t, &HabitScored{Task: state.active[0], Up: 2, Down: 1}, events[0])
+	require.Equal(t, "Notes", events[1].(*ChecklistTicked).Item.Text)
+	require.Equal(t, "Standup", events[2].(*TaskCompleted).Task.Text)
+	require.Equal(t, "Draft", events[3].(*ChecklistTicked).Item.Text)
+	require.Equal(t, "Report", events[4].(*TaskCompleted).Task.Text)
+	require.Equal(t, &LevelUp{From: 11, To: 12}, events[5])
+	require.InDelta(t, 7.5, events[6].(*HPLost).Amount(), 1e-9)
+	require.InDelta(t, 12.25, events[7].(*GoldGained).Amount(), 1e-9)
+}
+
+func TestWatcher_Watch(t *testing.T) {
+	state := &watchState{stats: UserStats{Lvl: 1, GP: 10}, failures: 3}
+	client, srv := newTestClient(t, state.serve)
+	defer srv.Close()
+
+	w := NewWatcher(client, WatchOptions{Interval: 10 * time.Millisecond, MaxBackoff: 30 * time.Millisecond})
+	var delays []time.Duration
+	w.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		if len(delays) == 4 {
+			state.update(func(s *watchState) { s.stats.GP = 15 })
+		}
+		return ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var failed []*PollFailed
+	for e := range w.Watch(ctx) {
+		if f, ok := e.(*PollFailed); ok {
+			failed = append(failed, f)
+			continue
+		}
+		require.Equal(t, &GoldGained{From: 10, To: 15}, e)
+		cancel()
+	}
+
+	require.Len(t, failed, 3)
+	require.Error(t, failed[0].Err)
+	require.Equal(t, []time.Duration{20 * time.Millisecond, 30 * time.Millisecond, 30 * time.Millisecond}, []time.Duration{failed[0].Retry, failed[1].Retry, failed[2].Retry})
+	// Successful polls wait for the interval again.
+	require.Equal(t, 10*time.Millisecond, delays[3])
+}
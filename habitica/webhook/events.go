@@ -0,0 +1,312 @@
+// Package webhook receives Habitica webhooks.
+//
+// Parse decodes a webhook payload into one of the typed events below, and
+// Handler is an http.Handler that parses the requests sent by Habitica and
+// dispatches the events to registered functions:
+//
+//	h := webhook.NewHandler(webhook.WithToken(secret))
+//	webhook.On(h, func(ctx context.Context, e *webhook.TaskScored) error {
+//		log.Printf("%s scored %s", e.Task.Text, e.Direction)
+//		return nil
+//	})
+//	http.Handle("/habitica", h)
+//
+// Register the webhook with a URL such as https://example.com/habitica?token=<secret>.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+)
+
+// Kind identifies an event as "<webhookType>.<type>", or just the webhook
+// type for webhooks without subtypes.
+type Kind string
+
+const (
+	KindTaskScored        Kind = "taskActivity.scored"
+	KindTaskCreated       Kind = "taskActivity.created"
+	KindTaskUpdated       Kind = "taskActivity.updated"
+	KindTaskDeleted       Kind = "taskActivity.deleted"
+	KindChecklistScored   Kind = "taskActivity.checklistScored"
+	KindGroupChatReceived Kind = "groupChatReceived"
+	KindPetHatched        Kind = "userActivity.petHatched"
+	KindMountRaised       Kind = "userActivity.mountRaised"
+	KindLeveledUp         Kind = "userActivity.leveledUp"
+	KindQuestActivity     Kind = "questActivity"
+)
+
+// Event is a parsed webhook payload. The concrete types are the pointer
+// types below; unrecognized payloads are returned as *Unknown.
+type Event interface {
+	Kind() Kind
+	// UserID is the ID of the user the webhook belongs to.
+	UserID() habitica.UUID
+}
+
+// Raw returns the payload e was parsed from, or nil if e was not created by
+// Parse. It includes fields the typed events leave out, such as webhookType.
+func Raw(e Event) json.RawMessage {
+	switch e := e.(type) {
+	case *Unknown:
+		return e.Raw
+	case interface{ rawPayload() json.RawMessage }:
+		return e.rawPayload()
+	}
+	return nil
+}
+
+// body keeps the payload a typed event was parsed from.
+type body struct {
+	payload json.RawMessage
+}
+
+func (b *body) rawPayload() json.RawMessage { return b.payload }
+
+func (b *body) setRawPayload(p json.RawMessage) { b.payload = p }
+
+// UserRef identifies the owner of a webhook.
+type UserRef struct {
+	ID habitica.UUID `json:"_id"`
+}
+
+// GroupRef identifies the group of a chat or quest event.
+type GroupRef struct {
+	ID   habitica.UUID `json:"id"`
+	Name string        `json:"name"`
+}
+
+// TaskScored is sent when a task is scored.
+type TaskScored struct {
+	Task      *habitica.Task `json:"task"`
+	Direction string         `json:"direction"` // up or down
+	// Delta is the change of the task's value.
+	Delta float64     `json:"delta"`
+	User  ScoringUser `json:"user"`
+
+	body
+}
+
+// ScoringUser is the user of a TaskScored event with the stats after scoring.
+type ScoringUser struct {
+	UserRef
+	Stats habitica.UserStats `json:"stats"`
+	Tmp   habitica.ScoreTmp  `json:"_tmp"`
+}
+
+func (*TaskScored) Kind() Kind { return KindTaskScored }
+
+func (e *TaskScored) UserID() habitica.UUID { return e.User.ID }
+
+// TaskCreated is sent when a task is created.
+type TaskCreated struct {
+	Task *habitica.Task `json:"task"`
+	User UserRef        `json:"user"`
+
+	body
+}
+
+func (*TaskCreated) Kind() Kind { return KindTaskCreated }
+
+func (e *TaskCreated) UserID() habitica.UUID { return e.User.ID }
+
+// TaskUpdated is sent when a task is edited.
+type TaskUpdated struct {
+	Task *habitica.Task `json:"task"`
+	User UserRef        `json:"user"`
+
+	body
+}
+
+func (*TaskUpdated) Kind() Kind { return KindTaskUpdated }
+
+func (e *TaskUpdated) UserID() habitica.UUID { return e.User.ID }
+
+// TaskDeleted is sent when a task is deleted.
+type TaskDeleted struct {
+	Task *habitica.Task `json:"task"`
+	User UserRef        `json:"user"`
+
+	body
+}
+
+func (*TaskDeleted) Kind() Kind { return KindTaskDeleted }
+
+func (e *TaskDeleted) UserID() habitica.UUID { return e.User.ID }
+
+// ChecklistScored is sent when a checklist item is checked or unchecked.
+type ChecklistScored struct {
+	Task *habitica.Task         `json:"task"`
+	Item habitica.ChecklistItem `json:"item"`
+	User UserRef                `json:"user"`
+
+	body
+}
+
+func (*ChecklistScored) Kind() Kind { return KindChecklistScored }
+
+func (e *ChecklistScored) UserID() habitica.UUID { return e.User.ID }
+
+// GroupChatReceived is sent for new messages in the chat of a group.
+type GroupChatReceived struct {
+	Group GroupRef    `json:"group"`
+	Chat  ChatMessage `json:"chat"`
+	User  UserRef     `json:"user"`
+
+	body
+}
+
+// ChatMessage is a message in a group chat. System messages have no UUID.
+type ChatMessage struct {
+	ID        string             `json:"id"`
+	Text      string             `json:"text"`
+	Timestamp habitica.Timestamp `json:"timestamp"`
+	// UUID is the ID of the author, or "system".
+	UUID     string `json:"uuid"`
+	User     string `json:"user"`
+	Username string `json:"username"`
+}
+
+func (*GroupChatReceived) Kind() Kind { return KindGroupChatReceived }
+
+func (e *GroupChatReceived) UserID() habitica.UUID { return e.User.ID }
+
+// PetHatched is sent when the user hatches a pet.
+type PetHatched struct {
+	Pet     string  `json:"pet"` // e.g. Wolf-Base
+	Message string  `json:"message"`
+	User    UserRef `json:"user"`
+
+	body
+}
+
+func (*PetHatched) Kind() Kind { return KindPetHatched }
+
+func (e *PetHatched) UserID() habitica.UUID { return e.User.ID }
+
+// MountRaised is sent when the user raises a pet to a mount.
+type MountRaised struct {
+	Mount   string  `json:"mount"`
+	Message string  `json:"message"`
+	User    UserRef `json:"user"`
+
+	body
+}
+
+func (*MountRaised) Kind() Kind { return KindMountRaised }
+
+func (e *MountRaised) UserID() habitica.UUID { return e.User.ID }
+
+// LeveledUp is sent when the user gains one or more levels at once.
+type LeveledUp struct {
+	InitialLvl int     `json:"initialLvl"`
+	FinalLvl   int     `json:"finalLvl"`
+	User       UserRef `json:"user"`
+
+	body
+}
+
+func (*LeveledUp) Kind() Kind { return KindLeveledUp }
+
+func (e *LeveledUp) UserID() habitica.UUID { return e.User.ID }
+
+// QuestActivity is sent when a quest of the user's party starts, finishes or
+// the user is invited to one.
+type QuestActivity struct {
+	// Type is questStarted, questFinished or questInvited.
+	Type  string   `json:"type"`
+	Group GroupRef `json:"group"`
+	Quest struct {
+		Key string `json:"key"`
+	} `json:"quest"`
+	User UserRef `json:"user"`
+
+	body
+}
+
+func (*QuestActivity) Kind() Kind { return KindQuestActivity }
+
+func (e *QuestActivity) UserID() habitica.UUID { return e.User.ID }
+
+// Unknown is a payload of a webhook type or subtype this package does not know.
+type Unknown struct {
+	WebhookType string
+	Type        string
+	User        UserRef
+	// Raw is the complete payload.
+	Raw json.RawMessage
+}
+
+// Kind returns the kind derived from the webhook type and subtype.
+func (e *Unknown) Kind() Kind { return kindOf(e.WebhookType, e.Type) }
+
+func (e *Unknown) UserID() habitica.UUID { return e.User.ID }
+
+func kindOf(webhookType, typ string) Kind {
+	if typ == "" || webhookType == string(KindGroupChatReceived) || webhookType == string(KindQuestActivity) {
+		return Kind(webhookType)
+	}
+	return Kind(webhookType + "." + typ)
+}
+
+// newEvent returns an empty event for a kind, or nil for unknown kinds.
+func newEvent(kind Kind) Event {
+	switch kind {
+	case KindTaskScored:
+		return &TaskScored{}
+	case KindTaskCreated:
+		return &TaskCreated{}
+	case KindTaskUpdated:
+		return &TaskUpdated{}
+	case KindTaskDeleted:
+		return &TaskDeleted{}
+	case KindChecklistScored:
+		return &ChecklistScored{}
+	case KindGroupChatReceived:
+		return &GroupChatReceived{}
+	case KindPetHatched:
+		return &PetHatched{}
+	case KindMountRaised:
+		return &MountRaised{}
+	case KindLeveledUp:
+		return &LeveledUp{}
+	case KindQuestActivity:
+		return &QuestActivity{}
+	}
+	return nil
+}
+
+// Parse decodes a webhook payload. The kind of event is taken from the
+// "webhookType" and "type" fields of the payload; Raw returns the payload.
+func Parse(payload []byte) (Event, error) {
+	var head struct {
+		WebhookType string  `json:"webhookType"`
+		Type        string  `json:"type"`
+		User        UserRef `json:"user"`
+	}
+	if err := json.Unmarshal(payload, &head); err != nil {
+		return nil, fmt.Errorf("decode webhook payload: %w", err)
+	}
+	if head.WebhookType == "" {
+		return nil, fmt.Errorf("decode webhook payload: missing webhookType")
+	}
+
+	kind := kindOf(head.WebhookType, head.Type)
+	raw := append(json.RawMessage(nil), payload...)
+	e := newEvent(kind)
+	if e == nil {
+		return &Unknown{
+			WebhookType: head.WebhookType,
+			Type:        head.Type,
+			User:        head.User,
+			Raw:         raw,
+		}, nil
+	}
+	if err := json.Unmarshal(payload, e); err != nil {
+		return nil, fmt.Errorf("decode %s webhook: %w", kind, err)
+	}
+	e.(interface{ setRawPayload(json.RawMessage) }).setRawPayload(raw)
+	return e, nil
+}
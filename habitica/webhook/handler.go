@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// DefaultMaxBodySize limits the size of webhook requests.
+const DefaultMaxBodySize = 1 << 20
+
+// Func handles an event. Returning an error answers the request with
+// status 500. Habitica does not retry failed deliveries and disables
+// webhooks that keep failing.
+type Func func(ctx context.Context, e Event) error
+
+// Handler is an http.Handler receiving Habitica webhooks. Events are
+// dispatched synchronously, in registration order, to the functions
+// registered for their kind and then to those registered with OnAny.
+//
+// A Handler is safe for concurrent use; functions may be registered while
+// it is serving.
+type Handler struct {
+	token       string
+	maxBodySize int64
+	errorLog    *log.Logger
+
+	mu       sync.RWMutex
+	handlers map[Kind][]Func
+	all      []Func
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithToken requires requests to carry the shared secret in the "token"
+// query parameter, e.g. https://example.com/habitica?token=<secret>.
+// Requests with a missing or different token are answered with 403.
+func WithToken(token string) Option {
+	return func(h *Handler) {
+		h.token = token
+	}
+}
+
+// WithMaxBodySize overrides DefaultMaxBodySize.
+func WithMaxBodySize(n int64) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxBodySize = n
+		}
+	}
+}
+
+// WithErrorLog logs rejected requests and handler errors to l.
+func WithErrorLog(l *log.Logger) Option {
+	return func(h *Handler) {
+		h.errorLog = l
+	}
+}
+
+// NewHandler creates a Handler without registered functions.
+func NewHandler(opts ...Option) *Handler {
+	h := &Handler{
+		maxBodySize: DefaultMaxBodySize,
+		handlers:    make(map[Kind][]Func),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Handle registers fn for events of the given kind.
+func (h *Handler) Handle(kind Kind, fn Func) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[kind] = append(h.handlers[kind], fn)
+}
+
+// OnAny registers fn for all events, including *Unknown ones.
+func (h *Handler) OnAny(fn Func) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.all = append(h.all, fn)
+}
+
+// On registers a typed function for the events of type E, which must not be
+// *Unknown, e.g.
+//
+//	webhook.On(h, func(ctx context.Context, e *webhook.LeveledUp) error { ... })
+func On[E Event](h *Handler, fn func(ctx context.Context, e E) error) {
+	var zero E
+	if _, ok := any(zero).(*Unknown); ok {
+		panic("webhook: use OnAny for unknown events")
+	}
+	h.Handle(zero.Kind(), func(ctx context.Context, e Event) error {
+		typed, ok := e.(E)
+		if !ok {
+			return fmt.Errorf("webhook: unexpected event %T for %s", e, zero.Kind())
+		}
+		return fn(ctx, typed)
+	})
+}
+
+// Dispatch calls the functions registered for the event. All functions are
+// called; their errors are joined.
+func (h *Handler) Dispatch(ctx context.Context, e Event) error {
+	h.mu.RLock()
+	fns := append(append([]Func(nil), h.handlers[e.Kind()]...), h.all...)
+	h.mu.RUnlock()
+
+	var errs []error
+	for _, fn := range fns {
+		if err := fn(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ServeHTTP validates the token, parses the payload and dispatches the event.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(h.token)) != 1 {
+		h.logf("webhook: rejected request from %s: invalid token", r.RemoteAddr)
+		http.Error(w, "invalid token", http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		h.logf("webhook: read request: %v", err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, "cannot read body", http.StatusBadRequest)
+		}
+		return
+	}
+	e, err := Parse(body)
+	if err != nil {
+		h.logf("webhook: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Dispatch(r.Context(), e); err != nil {
+		h.logf("webhook: handle %s: %v", e.Kind(), err)
+		http.Error(w, "handler failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) logf(format string, args ...any) {
+	if h.errorLog != nil {
+		h.errorLog.Printf(format, args...)
+	}
+}
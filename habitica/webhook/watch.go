@@ -0,0 +1,38 @@
+package webhook
+
+import "github.com/danielrichardt/gohabitica/habitica"
+
+// FromWatch converts a change detected by a habitica.Watcher into the events
+// Habitica sends webhooks for, so handlers also work for users who cannot
+// receive webhooks. user is the ID of the watched user.
+//
+// Completed tasks and habit clicks become *TaskScored (one per click),
+// ticked checklist items *ChecklistScored and level ups *LeveledUp. The
+// events have no payload (Raw returns nil), and *TaskScored events carry
+// neither Delta nor the user's stats. Changes without a webhook, such as
+// *habitica.HPLost, yield no events.
+func FromWatch(user habitica.UUID, change habitica.WatchEvent) []Event {
+	ref := UserRef{ID: user}
+	scored := func(t *habitica.Task, direction string) Event {
+		return &TaskScored{Task: t, Direction: direction, User: ScoringUser{UserRef: ref}}
+	}
+
+	switch c := change.(type) {
+	case *habitica.TaskCompleted:
+		return []Event{scored(c.Task, "up")}
+	case *habitica.HabitScored:
+		var events []Event
+		for i := 0; i < c.Up; i++ {
+			events = append(events, scored(c.Task, "up"))
+		}
+		for i := 0; i < c.Down; i++ {
+			events = append(events, scored(c.Task, "down"))
+		}
+		return events
+	case *habitica.ChecklistTicked:
+		return []Event{&ChecklistScored{Task: c.Task, Item: c.Item, User: ref}}
+	case *habitica.LevelUp:
+		return []Event{&LeveledUp{InitialLvl: c.From, FinalLvl: c.To, User: ref}}
+	}
+	return nil
+}
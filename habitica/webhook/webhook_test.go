@@ -0,0 +1,227 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/danielrichardt/gohabitica/habitica"
+	"github.com/danielrichardt/gohabitica/habitica/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+const userID = habitica.UUID("b0413351-405f-416f-8787-947ec1c85199")
+
+func payload(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name + ".json")
+	require.NoError(t, err)
+	return b
+}
+
+func TestParse(t *testing.T) {
+	for name, check := range map[string]func(t *testing.T, e webhook.Event){
+		"task_scored": func(t *testing.T, e webhook.Event) {
+			scored := e.(*webhook.TaskScored)
+			require.Equal(t, "Standup", scored.Task.Text)
+			require.Equal(t, habitica.TaskTypeDaily, scored.Task.Type)
+			require.Equal(t, "up", scored.Direction)
+			require.InDelta(t, 0.9747, scored.Delta, 1e-9)
+			require.Equal(t, 12, scored.User.Stats.Lvl)
+			require.Equal(t, 12, scored.User.Tmp.LeveledUp.NewLvl)
+		},
+		"task_created": func(t *testing.T, e webhook.Event) {
+			require.Equal(t, "Write report", e.(*webhook.TaskCreated).Task.Text)
+		},
+		"task_updated": func(t *testing.T, e webhook.Event) {
+			updated := e.(*webhook.TaskUpdated)
+			require.Equal(t, "Write quarterly report", updated.Task.Text)
+			require.Equal(t, 2.0, updated.Task.Priority)
+			require.Len(t, updated.Task.Checklist, 1)
+		},
+		"task_deleted": func(t *testing.T, e webhook.Event) {
+			deleted := e.(*webhook.TaskDeleted)
+			require.Equal(t, habitica.UUID("9d3e6c1a-55b2-4f0e-8a7c-0c1d2e3f4a5b"), deleted.Task.ID)
+			require.Equal(t, habitica.TaskTypeTodo, deleted.Task.Type)
+		},
+		"checklist_scored": func(t *testing.T, e webhook.Event) {
+			scored := e.(*webhook.ChecklistScored)
+			require.Equal(t, "Outline", scored.Item.Text)
+			require.True(t, scored.Item.Completed)
+			require.Len(t, scored.Task.Checklist, 2)
+		},
+		"group_chat_received": func(t *testing.T, e webhook.Event) {
+			chat := e.(*webhook.GroupChatReceived)
+			require.Equal(t, "The Procrastinators", chat.Group.Name)
+			require.Equal(t, "Boss is down to 20 HP!", chat.Chat.Text)
+			require.Equal(t, "alex", chat.Chat.Username)
+		},
+		"pet_hatched": func(t *testing.T, e webhook.Event) {
+			require.Equal(t, "Wolf-Base", e.(*webhook.PetHatched).Pet)
+		},
+		"mount_raised": func(t *testing.T, e webhook.Event) {
+			require.Equal(t, "Wolf-Base", e.(*webhook.MountRaised).Mount)
+		},
+		"leveled_up": func(t *testing.T, e webhook.Event) {
+			up := e.(*webhook.LeveledUp)
+			require.Equal(t, 11, up.InitialLvl)
+			require.Equal(t, 12, up.FinalLvl)
+		},
+		"quest_started": func(t *testing.T, e webhook.Event) {
+			quest := e.(*webhook.QuestActivity)
+			require.Equal(t, "questStarted", quest.Type)
+			require.Equal(t, "vice1", quest.Quest.Key)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			e, err := webhook.Parse(payload(t, name))
+			require.NoError(t, err)
+			require.Equal(t, userID, e.UserID())
+			require.JSONEq(t, string(payload(t, name)), string(webhook.Raw(e)))
+			check(t, e)
+		})
+	}
+}
+
+// TestParse_Kinds makes sure there is a payload in testdata for every kind.
+func TestParse_Kinds(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.json")
+	require.NoError(t, err)
+
+	seen := make(map[webhook.Kind]bool)
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		require.NoError(t, err)
+		e, err := webhook.Parse(b)
+		require.NoError(t, err, file)
+		require.NotEqual(t, "*webhook.Unknown", fmt.Sprintf("%T", e), file)
+		seen[e.Kind()] = true
+	}
+	for _, kind := range []webhook.Kind{
+		webhook.KindTaskScored, webhook.KindTaskCreated, webhook.KindTaskUpdated, webhook.KindTaskDeleted,
+		webhook.KindChecklistScored, webhook.KindGroupChatReceived, webhook.KindPetHatched,
+		webhook.KindMountRaised, webhook.KindLeveledUp, webhook.KindQuestActivity,
+	} {
+		require.True(t, seen[kind], "no payload for %s", kind)
+	}
+}
+
+func TestParse_Unknown(t *testing.T) {
+	e, err := webhook.Parse([]byte(`{"webhookType":"taskActivity","type":"archived","user":{"_id":"u1"}}`))
+	require.NoError(t, err)
+	unknown := e.(*webhook.Unknown)
+	require.Equal(t, webhook.Kind("taskActivity.archived"), unknown.Kind())
+	require.Equal(t, habitica.UUID("u1"), unknown.UserID())
+	require.Equal(t, unknown.Raw, webhook.Raw(e))
+	require.Nil(t, webhook.Raw(&webhook.TaskScored{}))
+
+	_, err = webhook.Parse([]byte(`{"type":"scored"}`))
+	require.ErrorContains(t, err, "missing webhookType")
+	_, err = webhook.Parse([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestFromWatch(t *testing.T) {
+	habit := &habitica.Task{ID: "habit", Type: habitica.TaskTypeHabit, Text: "Water"}
+	todo := &habitica.Task{ID: "todo", Type: habitica.TaskTypeTodo, Text: "Report", Completed: true}
+	item := habitica.ChecklistItem{ID: "i1", Text: "Outline", Completed: true}
+
+	var got []string
+	for _, change := range []habitica.WatchEvent{
+		&habitica.HabitScored{Task: habit, Up: 2, Down: 1},
+		&habitica.ChecklistTicked{Task: todo, Item: item},
+		&habitica.TaskCompleted{Task: todo},
+		&habitica.LevelUp{From: 11, To: 13},
+		&habitica.HPLost{From: 50, To: 42},
+		&habitica.PollFailed{Err: errors.New("offline")},
+	} {
+		for _, e := range webhook.FromWatch(userID, change) {
+			require.Equal(t, userID, e.UserID())
+			switch e := e.(type) {
+			case *webhook.TaskScored:
+				got = append(got, fmt.Sprintf("%s %s %s", e.Kind(), e.Task.Text, e.Direction))
+			case *webhook.ChecklistScored:
+				got = append(got, fmt.Sprintf("%s %s", e.Kind(), e.Item.Text))
+			case *webhook.LeveledUp:
+				got = append(got, fmt.Sprintf("%s %d-%d", e.Kind(), e.InitialLvl, e.FinalLvl))
+			}
+		}
+	}
+	require.Equal(t, []string{
+		"taskActivity.scored Water up",
+		"taskActivity.scored Water up",
+		"taskActivity.scored Water down",
+		"taskActivity.checklistScored Outline",
+		"taskActivity.scored Report up",
+		"userActivity.leveledUp 11-13",
+	}, got)
+}
+
+func TestHandler(t *testing.T) {
+	h := webhook.NewHandler(webhook.WithToken("s3cret"))
+
+	var got []string
+	webhook.On(h, func(ctx context.Context, e *webhook.TaskScored) error {
+		got = append(got, "scored "+e.Task.Text)
+		return nil
+	})
+	webhook.On(h, func(ctx context.Context, e *webhook.LeveledUp) error {
+		return errors.New("boom")
+	})
+	h.OnAny(func(ctx context.Context, e webhook.Event) error {
+		got = append(got, "any "+string(e.Kind()))
+		return nil
+	})
+
+	post := func(target, name string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(string(payload(t, name))))
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("/hook?token=s3cret", "task_scored")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, []string{"scored Standup", "any taskActivity.scored"}, got)
+
+	rec = post("/hook?token=s3cret", "pet_hatched")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "any userActivity.petHatched", got[len(got)-1])
+
+	// Errors of handlers fail the request, but all handlers still run.
+	rec = post("/hook?token=s3cret", "leveled_up")
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Equal(t, "any userActivity.leveledUp", got[len(got)-1])
+
+	n := len(got)
+	require.Equal(t, http.StatusForbidden, post("/hook", "task_scored").Code)
+	require.Equal(t, http.StatusForbidden, post("/hook?token=wrong", "task_scored").Code)
+	require.Len(t, got, n)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hook?token=s3cret", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hook?token=s3cret", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestHandler_MaxBodySize(t *testing.T) {
+	h := webhook.NewHandler(webhook.WithMaxBodySize(64))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(payload(t, "task_created")))))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// Other read errors are the client's fault, but not about the size.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", iotest.ErrReader(errors.New("connection reset"))))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,92 @@
+package habitica
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrWriteQueueFull is returned when a mutating request cannot be queued
+// because the write queue of the client is at capacity.
+var ErrWriteQueueFull = errors.New("habitica: write queue is full")
+
+// WithWriteSerialization makes the client execute mutating requests (POST,
+// PUT, PATCH, DELETE) one at a time in the order they were issued. Habitica
+// warns that concurrent writes to the same user may overwrite each other;
+// reads are not affected and keep running concurrently.
+//
+// queueSize bounds the number of writes waiting behind the one in flight;
+// further writes fail with ErrWriteQueueFull. A value <= 0 means unbounded.
+func WithWriteSerialization(queueSize int) Option {
+	return func(c *Client) {
+		c.writes = &writeQueue{max: queueSize}
+	}
+}
+
+// isWrite reports whether a request mutates state on the server.
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// writeQueue is a FIFO lock with a bounded number of waiters whose
+// acquisition can be abandoned through a context.
+type writeQueue struct {
+	max int
+
+	mu      sync.Mutex
+	busy    bool
+	waiters []chan struct{}
+}
+
+// acquire blocks until the caller owns the queue, the queue is full or ctx is done.
+func (q *writeQueue) acquire(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.busy {
+		q.busy = true
+		q.mu.Unlock()
+		return nil
+	}
+	if q.max > 0 && len(q.waiters) >= q.max {
+		q.mu.Unlock()
+		return ErrWriteQueueFull
+	}
+	ch := make(chan struct{})
+	q.waiters = append(q.waiters, ch)
+	q.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		for i, w := range q.waiters {
+			if w == ch {
+				q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+				q.mu.Unlock()
+				return ctx.Err()
+			}
+		}
+		q.mu.Unlock()
+		// Ownership was handed over concurrently with the cancellation; pass it on.
+		q.release()
+		return ctx.Err()
+	}
+}
+
+// release hands the queue to the next waiter or marks it idle.
+func (q *writeQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.waiters) == 0 {
+		q.busy = false
+		return
+	}
+	next := q.waiters[0]
+	q.waiters = q.waiters[1:]
+	close(next)
+}
@@ -0,0 +1,197 @@
+package habitica
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// These tests are most useful when run with the race detector (go test -race).
+
+func okResponse(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-RateLimit-Limit", "1000")
+	w.Header().Set("X-RateLimit-Remaining", "999")
+	_ = json.NewEncoder(w).Encode(APIResponse[struct{}]{
+		Success:       true,
+		Notifications: []UserNotification{{ID: "n-1"}},
+	})
+}
+
+func TestClient_WriteSerialization_NoOverlappingWrites(t *testing.T) {
+	var inFlight, maxInFlight int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		okResponse(w)
+	}
+
+	client, srv := newTestClient(t, handler, WithWriteSerialization(0))
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, client.doRequest(context.Background(), http.MethodPost, "/tasks/user", nil, nil, nil))
+		}()
+	}
+	wg.Wait()
+
+	require.EqualValues(t, 1, atomic.LoadInt32(&maxInFlight))
+}
+
+func TestClient_WriteSerialization_ReadsStayConcurrent(t *testing.T) {
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		okResponse(w)
+	}
+
+	client, srv := newTestClient(t, handler, WithWriteSerialization(0))
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, client.doRequest(context.Background(), http.MethodGet, "/user", nil, nil, nil))
+		}()
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-arrived:
+		case <-time.After(2 * time.Second):
+			t.Fatal("reads were not executed concurrently")
+		}
+	}
+	close(release)
+	wg.Wait()
+}
+
+func TestClient_WriteSerialization_QueueFull(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		okResponse(w)
+	}
+
+	client, srv := newTestClient(t, handler, WithWriteSerialization(1))
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, client.doRequest(context.Background(), http.MethodPut, "/tasks/a", nil, nil, nil))
+	}()
+	<-started
+
+	go func() {
+		defer wg.Done()
+		require.NoError(t, client.doRequest(context.Background(), http.MethodPut, "/tasks/b", nil, nil, nil))
+	}()
+	require.Eventually(t, func() bool {
+		client.writes.mu.Lock()
+		defer client.writes.mu.Unlock()
+		return len(client.writes.waiters) == 1
+	}, 2*time.Second, time.Millisecond)
+
+	err := client.doRequest(context.Background(), http.MethodPut, "/tasks/c", nil, nil, nil)
+	require.ErrorIs(t, err, ErrWriteQueueFull)
+
+	close(release)
+	wg.Wait()
+}
+
+func TestWriteQueue_FIFOAndCancellation(t *testing.T) {
+	q := &writeQueue{}
+	require.NoError(t, q.acquire(context.Background()))
+
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	waitForWaiters := func(n int) {
+		require.Eventually(t, func() bool {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return len(q.waiters) == n
+		}, 2*time.Second, time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		waiterCtx := context.Background()
+		if i == 2 {
+			waiterCtx = ctx
+		}
+		go func(i int, ctx context.Context) {
+			defer wg.Done()
+			if err := q.acquire(ctx); err != nil {
+				require.ErrorIs(t, err, context.Canceled)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			q.release()
+		}(i, waiterCtx)
+		waitForWaiters(i)
+	}
+
+	cancel()
+	waitForWaiters(2)
+
+	q.release()
+	wg.Wait()
+
+	require.Equal(t, []int{1, 3}, order)
+	require.False(t, q.busy)
+}
+
+func TestClient_ConcurrentUse(t *testing.T) {
+	client, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) { okResponse(w) },
+		WithWriteSerialization(0),
+		WithNotificationHandler(func(ctx context.Context, ns []UserNotification) {}),
+	)
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			method := http.MethodGet
+			if i%2 == 0 {
+				method = http.MethodPost
+			}
+			var meta ResponseMeta
+			require.NoError(t, client.doRequest(WithResponseMeta(context.Background(), &meta), method, "/user", nil, nil, nil))
+			_ = client.RateLimit()
+		}(i)
+	}
+	wg.Wait()
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,6 +16,7 @@ const (
 
 	envUserID   = "HABITICA_USER_ID"
 	envAPIToken = "HABITICA_API_TOKEN"
+	envOffline  = "HABITICA_OFFLINE"
 )
 
 // Config holds the settings required to talk to the Habitica API.
@@ -22,6 +24,9 @@ type Config struct {
 	BaseURL  string
 	UserID   string
 	APIToken string
+	// Offline enables journaling of task mutations that cannot be sent
+	// because the API is unreachable (see habitica.WithOfflineJournal).
+	Offline bool
 }
 
 // Options control how configuration is loaded.
@@ -36,6 +41,8 @@ type Options struct {
 // Order:
 //   1. Environment variables HABITICA_USER_ID and HABITICA_API_TOKEN
 //   2. If not set: config file (default path or explicitly provided)
+//
+// Offline mode is enabled by HABITICA_OFFLINE=1 or "offline: true" in the config file.
 func Load(opts Options) (*Config, error) {
 	baseURL := DefaultBaseURL
 	if opts.BaseURLOverride != "" {
@@ -51,6 +58,7 @@ func Load(opts Options) (*Config, error) {
 			BaseURL:  baseURL,
 			UserID:   userID,
 			APIToken: apiToken,
+			Offline:  offlineFromEnv(),
 		}, nil
 	}
 
@@ -82,16 +90,39 @@ func Load(opts Options) (*Config, error) {
 		return nil, newMissingCredentialsError()
 	}
 
+	if offlineFromEnv() {
+		cfgFromFile.Offline = true
+	}
+
 	return cfgFromFile, nil
 }
 
+// Dir returns the gohabitica directory below the user's configuration directory.
+// Besides the default config file it holds local state such as the offline journal.
+func Dir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "gohabitica"), nil
+}
+
 // defaultConfigPath determines the default path of the configuration file.
 func defaultConfigPath() (string, error) {
-	configDir, err := os.UserConfigDir()
+	dir, err := Dir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(configDir, "gohabitica", "config.yaml"), nil
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// offlineFromEnv reports whether offline mode is enabled via HABITICA_OFFLINE.
+func offlineFromEnv() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(envOffline))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
 }
 
 // loadFromFile loads configuration from the given YAML file.
@@ -105,6 +136,7 @@ func loadFromFile(path string) (*Config, error) {
 		BaseURL  string `yaml:"base_url"`
 		UserID   string `yaml:"user_id"`
 		APIToken string `yaml:"api_token"`
+		Offline  bool   `yaml:"offline"`
 	}
 	if err := yaml.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("configuration file %s could not be read: %w", path, err)
@@ -114,6 +146,7 @@ func loadFromFile(path string) (*Config, error) {
 		BaseURL:  raw.BaseURL,
 		UserID:   raw.UserID,
 		APIToken: raw.APIToken,
+		Offline:  raw.Offline,
 	}, nil
 }
 